@@ -2,8 +2,12 @@ package main
 
 import (
 	"backend/internal/api"
+	"backend/internal/config"
 	"backend/internal/infra"
 	"backend/internal/model"
+	"backend/internal/service"
+	"context"
+	"log"
 
 	"net/http"
 
@@ -12,23 +16,45 @@ import (
 )
 
 func main() {
+	cfg := config.Load()
 	r := gin.Default()
+	// Au-delà, les fichiers uploadés sont écrits sur disque par net/http
+	r.MaxMultipartMemory = 8 << 20
 
 	// Configure CORS
-	config := cors.DefaultConfig()
-	config.AllowAllOrigins = true
-	config.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
-	config.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization"}
-	config.AllowCredentials = true
+	corsConfig := cors.DefaultConfig()
+	corsConfig.AllowAllOrigins = true
+	corsConfig.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+	corsConfig.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Owner"}
+	corsConfig.AllowCredentials = true
 
-	r.Use(cors.New(config))
+	r.Use(cors.New(corsConfig))
 
 	// Auto-migration des tables (dev)
 	db := infra.GetDB()
-	db.AutoMigrate(&model.BulkJob{}, &model.EmailResult{})
+	db.AutoMigrate(&model.BulkJob{}, &model.EmailResult{}, &model.WorkItem{}, &model.Worker{}, &model.ExtractResult{}, &model.ExtractContact{}, &model.RobotsSkip{}, &model.ExtractOrganization{}, &model.Upload{}, &model.Webhook{}, &model.WebhookDelivery{}, &model.Schedule{}, &model.StatusChange{}, &model.List{}, &model.ListMember{}, &model.Suppression{})
+
+	// Événements SSE des jobs traités par cmd/worker ou un autre nœud API (LISTEN/NOTIFY)
+	go service.RunJobEventListener(context.Background())
+
+	// Reprise des jobs interrompus puis démarrage des workers embarqués
+	// (QUEUE_WORKERS=0 pour un nœud API seul, le travail étant fait par cmd/worker)
+	if err := service.RecoverInterruptedWork(); err != nil {
+		log.Printf("queue recovery failed: %v", err)
+	}
+	if cfg.QueueWorkers > 0 {
+		service.RunWorkerNode(context.Background(), service.NewWorkerID(), cfg.WorkerKinds, cfg.QueueWorkers)
+	}
+
+	// Fichiers prévisualisés jamais utilisés par un job
+	go service.RunUploadJanitor(context.Background())
+	// Envoi des webhooks de fin de job (aussi fait par cmd/worker)
+	go service.RunWebhookDispatcher(context.Background())
+	// Re-vérifications planifiées (aussi lancées par cmd/worker)
+	go service.RunScheduledReverifications(context.Background())
 
 	api.RegisterRoutes(r)
 
-	r.Run(":3009")
+	r.Run(cfg.HTTPAddr)
 	go http.ListenAndServe("localhost:6060", nil)
 }
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"backend/internal/util"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// Récepteur de webhooks pour le développement: affiche les événements reçus et
+// vérifie leur signature. Exemple:
+//
+//	go run ./cmd/webhook-receiver -addr :4000 -secret whsec_...
+//
+// puis webhookUrl=http://localhost:4000/ sur /api/bulk-verify, ou POST /api/webhooks.
+// -fail N répond 500 aux N premières requêtes pour observer les nouveaux essais.
+func main() {
+	addr := flag.String("addr", ":4000", "listen address")
+	secret := flag.String("secret", os.Getenv("WEBHOOK_SECRET"), "signing secret (signature not checked when empty)")
+	fail := flag.Int("fail", 0, "answer 500 to the first N requests")
+	flag.Parse()
+
+	var received atomic.Int64
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		n := received.Add(1)
+		log.Printf("%s %s event=%s delivery=%s", r.Method, r.URL.Path, r.Header.Get("X-MailHound-Event"), r.Header.Get("X-MailHound-Delivery"))
+
+		if *secret != "" {
+			if err := util.VerifySignature(*secret, r.Header.Get(util.SignatureHeader), body, time.Now()); err != nil {
+				log.Printf("  signature: INVALID (%v)", err)
+				http.Error(w, "invalid signature", http.StatusUnauthorized)
+				return
+			}
+			log.Printf("  signature: ok")
+		}
+
+		var pretty bytes.Buffer
+		if json.Indent(&pretty, body, "  ", "  ") == nil {
+			log.Printf("  %s", pretty.String())
+		} else {
+			log.Printf("  %s", body)
+		}
+
+		if n <= int64(*fail) {
+			log.Printf("  answering 500 (%d/%d simulated failures)", n, *fail)
+			http.Error(w, "simulated failure", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	log.Printf("webhook receiver listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
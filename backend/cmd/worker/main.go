@@ -0,0 +1,39 @@
+package main
+
+import (
+	"backend/internal/config"
+	"backend/internal/service"
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// Processus worker: consomme la file partagée (vérification et extraction)
+// sans servir l'API. Plusieurs workers peuvent tourner sur des hôtes/IP différents.
+func main() {
+	cfg := config.Load()
+	if cfg.QueueWorkers <= 0 {
+		log.Fatal("QUEUE_WORKERS must be greater than 0 in worker mode")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Les événements des jobs sont relayés aux clients SSE du serveur API
+	service.EnableJobEventNotify()
+
+	workerID := service.NewWorkerID()
+	log.Printf("worker %s: starting %d goroutines for %v", workerID, cfg.QueueWorkers, cfg.WorkerKinds)
+	if err := service.ReapExpiredLeases(); err != nil {
+		log.Printf("worker %s: lease reaper failed: %v", workerID, err)
+	}
+	wait := service.RunWorkerNode(ctx, workerID, cfg.WorkerKinds, cfg.QueueWorkers)
+	go service.RunWebhookDispatcher(ctx)
+	go service.RunScheduledReverifications(ctx)
+
+	<-ctx.Done()
+	log.Printf("worker %s: shutting down, waiting for in-flight items", workerID)
+	wait()
+}
@@ -1,165 +1,191 @@
 package api
 
 import (
+	"backend/internal/model"
 	"backend/internal/service"
-	"encoding/csv"
+	"backend/internal/util"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
-	"runtime"
+	"strconv"
 	"strings"
-	"sync"
-	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 type BulkVerifyResponse struct {
-	JobID string `json:"jobId"`
+	JobID  string        `json:"jobId"`
+	Status string        `json:"status"`
+	Source util.ListInfo `json:"source"` // format, delimiter, encoding and sheet detected
+	// Column read, given by emailCol or guessed
+	EmailColumn string `json:"emailColumn"`
+	// Secret signing the job webhook (generated when webhookUrl is set without webhookSecret)
+	WebhookSecret string `json:"webhookSecret,omitempty"`
 	// Results []service.EmailValidationResult `json:"results"`
 
 }
 
 func BulkVerifyHandler(c *gin.Context) {
-	// Use number of CPUs to optimize workers for IO-bound tasks
-	numWorkers := runtime.NumCPU() * 4
-
-	// 1. Get file
-	fileHeader, err := c.FormFile("file")
+	// 1. Get file: new upload, or uploadId from /api/uploads/preview
+	source, err := openBulkSource(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		switch {
+		case isUploadTooLarge(err):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file too large"})
+		case errors.Is(err, errFileRequired):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "file or uploadId is required"})
+		case errors.Is(err, service.ErrUploadNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "upload not found"})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "cannot open file"})
+		}
 		return
 	}
-	file, err := fileHeader.Open()
+
+	// 2. Detect format (CSV/TSV, XLSX, JSON, text) and read header
+	headers, info, err := service.ReadSpooledHeader(source.spoolPath, source.sheet)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot open file"})
+		source.discard()
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot read file header: " + err.Error()})
 		return
 	}
-	defer file.Close()
 
-	// 2. Get email column name
-	emailCol := c.PostForm("emailCol")
-	if emailCol == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "emailCol is required"})
+	// 3. Email column: emailCol when given, otherwise guessed from content
+	colIndex, err := service.ResolveColumn(source.spoolPath, info.Sheet, headers, c.PostForm("emailCol"), model.WorkKindVerify)
+	if err != nil {
+		source.discard()
+		c.JSON(http.StatusBadRequest, gin.H{"error": "email column not found"})
 		return
 	}
 
-	// 3. Read CSV header
-	reader := csv.NewReader(file)
-	reader.TrimLeadingSpace = true
-	headers, err := reader.Read()
+	// 4. Optional webhook notified when the job ends, priority and owner
+	webhookURL, webhookSecret, err := jobWebhook(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot read CSV header"})
+		source.discard()
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	priority, owner, err := jobScheduling(c)
+	if err != nil {
+		source.discard()
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	colIndex := -1
-	for i, h := range headers {
-		if strings.EqualFold(strings.TrimSpace(h), strings.TrimSpace(emailCol)) {
-			colIndex = i
-			break
-		}
+	// 5. Create job record, emails are queued by batches while the file is read.
+	// Invalid syntax is never verified; duplicates are skipped unless skipDuplicates=false
+	opts := service.IngestOptions{
+		SkipDuplicates: c.DefaultPostForm("skipDuplicates", "true") != "false",
+		WebhookURL:     webhookURL,
+		WebhookSecret:  webhookSecret,
+		Priority:       priority,
+		Owner:          owner,
 	}
-	if colIndex == -1 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "email column not found"})
+	if err := source.claim(); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "upload already used by another job"})
+		return
+	}
+	job, err := service.CreateIngestingBulkJob(model.WorkKindVerify, source.fileName, source.spoolPath, info, headers, colIndex, opts)
+	if errors.Is(err, service.ErrNoRows) {
+		source.discard()
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no valid emails found"})
+		return
+	}
+	if err != nil {
+		source.discard()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create job"})
 		return
 	}
 
-	// 4. Extract emails
-	emails := []string{}
-	for {
-		record, err := reader.Read()
-		if err == io.EOF {
-			break
-		}
-		if err != nil || colIndex >= len(record) {
-			continue
-		}
-		email := strings.TrimSpace(record[colIndex])
-		if email != "" {
-			emails = append(emails, email)
-		}
+	// 6. Respond, queue workers process the job in background
+	c.JSON(http.StatusAccepted, BulkVerifyResponse{JobID: job.ID, Status: job.Status, Source: info, EmailColumn: headers[colIndex], WebhookSecret: webhookSecret})
+}
+
+// GET status and progress of a bulk job
+func GetBulkJobStatusHandler(c *gin.Context) {
+	jobId := c.Param("jobId")
+	job, err := service.GetBulkJobByID(jobId)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return
 	}
-	if len(emails) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "no valid emails found"})
+	progress := 0.0
+	if job.TotalEmails > 0 {
+		progress = float64(job.ProcessedEmails) * 100 / float64(job.TotalEmails)
+	}
+	// Position in the queue and estimated start, only while the job waits
+	queue, err := service.GetQueueEstimate(job)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	c.JSON(http.StatusOK, gin.H{
+		"jobId":           job.ID,
+		"kind":            job.Kind,
+		"extractMode":     job.ExtractMode,
+		"ignoreRobots":    job.IgnoreRobots,
+		"parentJobId":     job.ParentJobID,
+		"scheduleId":      job.ScheduleID,
+		"fileName":        job.FileName,
+		"displayName":     job.DisplayName,
+		"sourceFormat":    job.SourceFormat,
+		"sourceSheet":     job.SourceSheet,
+		"status":          job.Status,
+		"priority":        job.Priority,
+		"owner":           job.Owner,
+		"queue":           queue,
+		"totalEmails":     job.TotalEmails,
+		"processedEmails": job.ProcessedEmails,
+		"errorCount":      job.ErrorCount,
+		"errorMessage":    job.ErrorMessage,
+		"ingesting":       job.Ingesting,
+		"skipDuplicates":  job.SkipDuplicates,
+		"preflight":       service.GetPreflightReport(job),
+		"progress":        progress,
+		"uploadedAt":      job.UploadedAt,
+		"startedAt":       job.StartedAt,
+		"finishedAt":      job.FinishedAt,
+	})
+}
 
-	// 5. Create job record
-	job := service.CreateBulkJob(fileHeader.Filename, len(emails))
-	jobID := job.ID
-
-	// 6. Setup worker pool and channels
-	type Job struct {
-		Index int
-		Email string
-	}
-	emailCh := make(chan Job, len(emails))
-	resultCh := make(chan service.EmailValidationResult, len(emails))
-	var wg sync.WaitGroup
-
-	// 7. Start worker goroutines
-	for i := 0; i < numWorkers; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for job := range emailCh {
-				start := time.Now()
-				var res service.EmailValidationResult
-				var err error
-				for retry := 0; retry < 3; retry++ {
-					res, err = service.ValidateEmailSMTP(job.Email)
-					if err == nil {
-						break
-					}
-					time.Sleep(10 * time.Second)
-				}
-				res.Email = job.Email
-				res.JobID = jobID
-				res.CheckedAt = start
-				resultCh <- res
-			}
-		}()
-	}
-
-	// 8. Send jobs to channel
-	go func() {
-		for i, email := range emails {
-			emailCh <- Job{Index: i, Email: email}
-		}
-		close(emailCh)
-	}()
-
-	// 9. Close result channel when all workers are done
-	go func() {
-		wg.Wait()
-		close(resultCh)
-	}()
-
-	// 10. Collect results
-	finalResults := []service.EmailValidationResult{}
-	for res := range resultCh {
-		finalResults = append(finalResults, res)
-	}
-
-	// 11. Batch save to DB in chunks
-	const batchSize = 500
-	for i := 0; i < len(finalResults); i += batchSize {
-		end := i + batchSize
-		if end > len(finalResults) {
-			end = len(finalResults)
-		}
-		if err := service.BatchSaveResults(finalResults[i:end]); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save results"})
-			return
+// resultFilterFromQuery reads the result filter shared by the results page and downloads:
+// status=valid,unknown (type is kept as an alias), minScore, maxScore, domain, excludeDomain,
+// roleBased, disposable, catchAll, free, bounceType=hard,soft,none, reason=smtp_rejected,no_mx,
+// q, sort=score&order=desc
+func resultFilterFromQuery(c *gin.Context) (service.ResultFilter, error) {
+	filter := service.ResultFilter{
+		Statuses:       splitQuery(c.Query("status")),
+		Domains:        splitQuery(c.Query("domain")),
+		ExcludeDomains: splitQuery(c.Query("excludeDomain")),
+		BounceTypes:    splitQuery(c.Query("bounceType")),
+		ReasonCodes:    splitQuery(c.Query("reason")),
+		Search:         c.Query("q"),
+		Sort:           c.Query("sort"),
+		Desc:           strings.EqualFold(c.Query("order"), "desc"),
+	}
+	if typ := c.Query("type"); typ != "" && typ != "all" && len(filter.Statuses) == 0 {
+		filter.Statuses = []string{typ}
+	}
+	if n, err := strconv.Atoi(c.Query("minScore")); err == nil {
+		filter.MinScore = &n
+	}
+	if n, err := strconv.Atoi(c.Query("maxScore")); err == nil {
+		filter.MaxScore = &n
+	}
+	for param, dst := range map[string]**bool{
+		"roleBased":  &filter.RoleBased,
+		"disposable": &filter.Disposable,
+		"catchAll":   &filter.CatchAll,
+		"free":       &filter.Free,
+	} {
+		if b, err := strconv.ParseBool(c.Query(param)); err == nil {
+			*dst = &b
 		}
 	}
-
-	// 12. Respond
-	c.JSON(http.StatusOK, BulkVerifyResponse{JobID: jobID})
+	return filter, filter.Validate()
 }
 
-// GET paginated results of a job
+// GET paginated results of a job, filtered and sorted (see resultFilterFromQuery)
 func GetJobResultsHandler(c *gin.Context) {
 	jobId := c.Param("jobId")
 	page := 1
@@ -177,7 +203,12 @@ func GetJobResultsHandler(c *gin.Context) {
 		pageSize = 20
 	}
 
-	results, total, valid, invalid, acceptAll, err := service.GetJobResultsPaginated(jobId, page, pageSize)
+	filter, err := resultFilterFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	results, total, valid, invalid, acceptAll, err := service.GetJobResultsPaginated(jobId, filter, page, pageSize)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -188,15 +219,16 @@ func GetJobResultsHandler(c *gin.Context) {
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{
-		"jobId":     jobId,
-		"fileName":  job.FileName,
-		"results":   results,
-		"total":     total,
-		"valid":     valid,
-		"invalid":   invalid,
-		"acceptAll": acceptAll,
-		"page":      page,
-		"pageSize":  pageSize,
+		"jobId":       jobId,
+		"fileName":    job.FileName,
+		"displayName": job.DisplayName,
+		"results":     results,
+		"total":       total,
+		"valid":       valid,
+		"invalid":     invalid,
+		"acceptAll":   acceptAll,
+		"page":        page,
+		"pageSize":    pageSize,
 	})
 }
 
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"backend/internal/service"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+// GET /api/bulk-verify/:jobId/events
+// Stream Server-Sent Events for a bulk job: "progress" (counters, tallies, ETA),
+// "result" (each verification result, or each email found by an extraction),
+// "contact" (phones and social profiles), "organization" (structured data) and
+// "done". Every event carries an id "<epoch>-<seq>" so that EventSource reconnects
+// with Last-Event-ID and only gets what it missed; an id from another stream (API
+// restart, other node) gets a resync snapshot.
+func BulkJobEventsHandler(c *gin.Context) {
+	jobId := c.Param("jobId")
+	job, err := service.GetBulkJobByID(jobId)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return
+	}
+
+	// 1. Resume point: header sent by EventSource on reconnect, or query param
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("lastEventId")
+	}
+
+	// A job processed by another process (cmd/worker) has no stream here until
+	// its first relayed event: open it so that the subscription below follows it
+	if service.JobEventRelayActive() && service.IsJobActive(job.Status) {
+		service.OpenJobStream(jobId)
+	}
+	replay, ch, missed, cancel := service.SubscribeJobEvents(jobId, lastEventID)
+	defer cancel()
+
+	c.Header("Content-Type", sse.ContentType)
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	// 2. Snapshot from DB when events were lost or no live stream exists
+	if missed || (ch == nil && len(replay) == 0) {
+		progress, err := service.GetJobProgress(jobId)
+		if err == nil {
+			progress.Resync = missed
+			c.Render(-1, sse.Event{Event: service.JobEventProgress, Retry: 3000, Data: progress})
+			if ch == nil && !service.IsJobActive(progress.Status) {
+				c.Render(-1, sse.Event{Event: service.JobEventDone, Data: progress})
+			}
+		}
+		c.Writer.Flush()
+		// No relay listener: follow the progress of another process from DB
+		if err == nil && ch == nil && len(replay) == 0 && service.IsJobActive(progress.Status) {
+			streamJobProgressFromDB(c, jobId)
+			return
+		}
+	}
+
+	// 3. Replay buffered events
+	for _, ev := range replay {
+		renderJobEvent(c, ev)
+	}
+	c.Writer.Flush()
+	if ch == nil {
+		return
+	}
+
+	// 4. Live events with heartbeat
+	heartbeat := time.NewTicker(15 * time.Second)
+	defer heartbeat.Stop()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case ev, ok := <-ch:
+			if !ok {
+				return false
+			}
+			renderJobEvent(c, ev)
+			return ev.Type != service.JobEventDone
+		case <-heartbeat.C:
+			// The relayed "done" may have been lost while the listener reconnected
+			if progress, err := service.GetJobProgress(jobId); err == nil && !service.IsJobActive(progress.Status) {
+				c.Render(-1, sse.Event{Event: service.JobEventDone, Data: progress})
+				return false
+			}
+			io.WriteString(w, ": ping\n\n")
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
+
+func renderJobEvent(c *gin.Context, ev service.JobEvent) {
+	c.Render(-1, sse.Event{
+		Id:    ev.EventID(),
+		Event: ev.Type,
+		Data:  ev.Data,
+	})
+}
+
+// streamJobProgressFromDB polls the job counters when no live stream exists in
+// this process and the event relay is down; only "progress" and "done" events
+// are sent in this mode
+func streamJobProgressFromDB(c *gin.Context, jobId string) {
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-ticker.C:
+			progress, err := service.GetJobProgress(jobId)
+			if err != nil {
+				return false
+			}
+			if !service.IsJobActive(progress.Status) {
+				c.Render(-1, sse.Event{Event: service.JobEventDone, Data: progress})
+				return false
+			}
+			c.Render(-1, sse.Event{Event: service.JobEventProgress, Data: progress})
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
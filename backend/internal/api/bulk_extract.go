@@ -1,135 +1,211 @@
 package api
 
 import (
+	"backend/internal/model"
 	"backend/internal/service"
-	"encoding/csv"
-	"io"
+	"encoding/json"
+	"errors"
 	"net/http"
-	"runtime"
-	"strings"
-	"sync"
 
 	"github.com/gin-gonic/gin"
 )
 
 type BulkExtractResult struct {
+	Site       string `json:"site"`
+	Email      string `json:"email"`
+	Domain     string `json:"domain"`
+	Suppressed bool   `json:"suppressed,omitempty"`
+	Source     string `json:"source,omitempty"` // page where the email was found
+}
+
+// Phone or social profile found by a deep extraction
+type BulkExtractContact struct {
 	Site   string `json:"site"`
-	Email  string `json:"email"`
-	Domain string `json:"domain"`
+	Kind   string `json:"kind"`  // phone or social
+	Value  string `json:"value"` // E.164 for phones
+	Source string `json:"source,omitempty"`
+	// Phones only: country and mobile, landline, toll_free or unknown
+	Country string `json:"country,omitempty"`
+	Type    string `json:"type,omitempty"`
+}
+
+// Organization or person described by the structured data of a site (deep mode)
+type BulkExtractOrganization struct {
+	Site string `json:"site"`
+	service.SiteOrganization
+}
+
+// Page not visited because robots.txt disallows it
+type BulkExtractRobotsSkip struct {
+	Site string `json:"site"`
+	URL  string `json:"url"`
 }
 
 func BulkExtractHandler(c *gin.Context) {
-	fileHeader, err := c.FormFile("file")
+	// Nouveau fichier, ou uploadId retourné par /api/uploads/preview
+	source, err := openBulkSource(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Le fichier est requis / الملف مطلوب"})
+		switch {
+		case isUploadTooLarge(err):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Fichier trop volumineux / الملف كبير جداً"})
+		case errors.Is(err, errFileRequired):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Le fichier est requis / الملف مطلوب"})
+		case errors.Is(err, service.ErrUploadNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Fichier introuvable / الملف غير موجود"})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Impossible d'ouvrir le fichier / لا يمكن فتح الملف"})
+		}
 		return
 	}
-	file, err := fileHeader.Open()
+
+	// CSV/TSV, XLSX (champ "sheet" optionnel), JSON ou texte: détection automatique
+	headers, info, err := service.ReadSpooledHeader(source.spoolPath, source.sheet)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Impossible d'ouvrir le fichier / لا يمكن فتح الملف"})
+		source.discard()
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Impossible de lire l'en-tête du fichier / لا يمكن قراءة رأس الملف", "details": err.Error()})
 		return
 	}
-	defer file.Close()
 
-	reader := csv.NewReader(file)
-	reader.TrimLeadingSpace = true
-	headers, err := reader.Read()
+	// websiteCol optionnel: à défaut, la colonne qui contient des sites est devinée
+	colIndex, err := service.ResolveColumn(source.spoolPath, info.Sheet, headers, c.PostForm("websiteCol"), model.WorkKindExtract)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Impossible de lire l'en-tête du CSV / لا يمكن قراءة رأس ملف CSV"})
+		source.discard()
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Colonne du site web non trouvée / لم يتم العثور على عمود الموقع الإلكتروني"})
 		return
 	}
 
-	websiteCol := c.PostForm("websiteCol")
-	if websiteCol == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Le nom de la colonne du site web est requis / اسم عمود الموقع الإلكتروني مطلوب"})
+	// Webhook optionnel notifié à la fin du job
+	webhookURL, webhookSecret, err := jobWebhook(c)
+	if err != nil {
+		source.discard()
+		c.JSON(http.StatusBadRequest, gin.H{"error": "URL du webhook invalide / رابط الـ webhook غير صالح"})
 		return
 	}
-	colIndex := -1
-	for i, h := range headers {
-		if strings.EqualFold(strings.TrimSpace(h), strings.TrimSpace(websiteCol)) {
-			colIndex = i
-			break
-		}
+
+	// Mode fast (page d'accueil) ou deep (crawl du site: emails, téléphones, réseaux sociaux)
+	mode, err := service.ParseExtractMode(c.PostForm("mode"))
+	if err != nil {
+		source.discard()
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Mode invalide (fast ou deep) / وضع غير صالح (fast أو deep)"})
+		return
 	}
-	if colIndex == -1 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Colonne du site web non trouvée / لم يتم العثور على عمود الموقع الإلكتروني"})
+
+	// Priorité (low, normal, high, urgent) et propriétaire pour le partage des workers
+	priority, owner, err := jobScheduling(c)
+	if err != nil {
+		source.discard()
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Priorité invalide / أولوية غير صالحة"})
 		return
 	}
 
-	websites := []string{}
-	for {
-		record, err := reader.Read()
-		if err == io.EOF {
-			break
-		}
-		if err != nil || colIndex >= len(record) {
-			continue
-		}
-		website := strings.TrimSpace(record[colIndex])
-		if website != "" {
-			websites = append(websites, website)
-		}
+	// Les sites sont mis en file au fil de la lecture et traités par les workers (API ou cmd/worker)
+	if err := source.claim(); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Fichier déjà utilisé par un autre job / الملف مستخدم بالفعل في مهمة أخرى"})
+		return
 	}
-	if len(websites) == 0 {
+	job, err := service.CreateIngestingBulkJob(model.WorkKindExtract, source.fileName, source.spoolPath, info, headers, colIndex, service.IngestOptions{
+		WebhookURL:    webhookURL,
+		WebhookSecret: webhookSecret,
+		Priority:      priority,
+		Owner:         owner,
+		ExtractMode:   mode,
+		// Sites dont on est propriétaire: robots.txt ignoré
+		IgnoreRobots: c.PostForm("ignoreRobots") == "true",
+	})
+	if errors.Is(err, service.ErrNoRows) {
+		source.discard()
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Aucun site web valide trouvé / لم يتم العثور على مواقع إلكترونية صالحة"})
 		return
 	}
+	if err != nil {
+		source.discard()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Impossible de créer le job / لا يمكن إنشاء المهمة"})
+		return
+	}
+	if c.PostForm("async") == "true" {
+		c.JSON(http.StatusAccepted, gin.H{"jobId": job.ID, "status": job.Status, "mode": mode, "source": info, "websiteColumn": headers[colIndex], "webhookSecret": webhookSecret})
+		return
+	}
 
-	numWorkers := runtime.NumCPU() * 4
-	type Job struct {
-		Index   int
-		Website string
-	}
-
-	type EmailResult struct {
-		Site   string
-		Email  string
-		Domain string
-	}
-
-	jobCh := make(chan Job, len(websites))
-	resultCh := make(chan []EmailResult, len(websites))
-	var wg sync.WaitGroup
-
-	for i := 0; i < numWorkers; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for job := range jobCh {
-				emails, err := service.ExtractEmailsFromSiteFast(job.Website)
-				results := []EmailResult{}
-				if err == nil {
-					for _, em := range emails {
-						results = append(results, EmailResult{
-							Site:   job.Website,
-							Email:  em.Email,
-							Domain: em.Domain,
-						})
-					}
-				}
-				resultCh <- results
-			}
-		}()
-	}
-
-	go func() {
-		for i, website := range websites {
-			jobCh <- Job{Index: i, Website: website}
-		}
-		close(jobCh)
-	}()
-
-	go func() {
-		wg.Wait()
-		close(resultCh)
-	}()
-
-	allResults := []BulkExtractResult{}
-	for res := range resultCh {
-		for _, r := range res {
-			allResults = append(allResults, BulkExtractResult(r))
+	// Mode synchrone (compatibilité): on attend la fin du job
+	job, err = service.WaitForBulkJob(c.Request.Context(), job.ID)
+	if err != nil {
+		c.JSON(http.StatusGatewayTimeout, gin.H{"jobId": job.ID, "error": "Extraction toujours en cours / الاستخراج ما زال جارياً"})
+		return
+	}
+	resp := gin.H{"jobId": job.ID, "results": extractResultsResponse(job.ID)}
+	if job.ExtractMode == model.ExtractModeDeep {
+		resp["contacts"] = extractContactsResponse(job.ID)
+		resp["organizations"] = extractOrganizationsResponse(job.ID)
+	}
+	if skips := robotsSkipsResponse(job.ID); len(skips) > 0 {
+		resp["robotsSkipped"] = skips
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// GET results of a bulk extraction job
+func GetBulkExtractResultsHandler(c *gin.Context) {
+	jobId := c.Param("jobId")
+	job, err := service.GetBulkJobByID(jobId)
+	if err != nil || job.Kind != model.WorkKindExtract {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job introuvable / المهمة غير موجودة"})
+		return
+	}
+	resp := gin.H{
+		"jobId":   job.ID,
+		"status":  job.Status,
+		"mode":    job.ExtractMode,
+		"results": extractResultsResponse(job.ID),
+	}
+	// Phones and social profiles (deep mode)
+	if job.ExtractMode == model.ExtractModeDeep {
+		resp["contacts"] = extractContactsResponse(job.ID)
+		resp["organizations"] = extractOrganizationsResponse(job.ID)
+	}
+	// Pages not visited because robots.txt disallows them
+	if skips := robotsSkipsResponse(job.ID); len(skips) > 0 {
+		resp["robotsSkipped"] = skips
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func extractResultsResponse(jobId string) []BulkExtractResult {
+	rows := service.GetExtractResults(jobId)
+	results := make([]BulkExtractResult, len(rows))
+	for i, r := range rows {
+		results[i] = BulkExtractResult{Site: r.Site, Email: r.Email, Domain: r.Domain, Suppressed: r.Suppressed, Source: r.SourceURL}
+	}
+	return results
+}
+
+func extractContactsResponse(jobId string) []BulkExtractContact {
+	rows := service.GetExtractContacts(jobId)
+	contacts := make([]BulkExtractContact, len(rows))
+	for i, r := range rows {
+		contacts[i] = BulkExtractContact{Site: r.Site, Kind: r.Kind, Value: r.Value, Source: r.SourceURL, Country: r.Country, Type: r.PhoneType}
+	}
+	return contacts
+}
+
+func extractOrganizationsResponse(jobId string) []BulkExtractOrganization {
+	rows := service.GetExtractOrganizations(jobId)
+	orgs := make([]BulkExtractOrganization, 0, len(rows))
+	for _, r := range rows {
+		org := BulkExtractOrganization{Site: r.Site}
+		if err := json.Unmarshal([]byte(r.Data), &org.SiteOrganization); err != nil {
+			continue
 		}
+		orgs = append(orgs, org)
 	}
+	return orgs
+}
 
-	c.JSON(http.StatusOK, gin.H{"results": allResults})
+func robotsSkipsResponse(jobId string) []BulkExtractRobotsSkip {
+	rows := service.GetRobotsSkips(jobId)
+	skips := make([]BulkExtractRobotsSkip, len(rows))
+	for i, r := range rows {
+		skips[i] = BulkExtractRobotsSkip{Site: r.Site, URL: r.URL}
+	}
+	return skips
 }
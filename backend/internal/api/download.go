@@ -1,18 +1,169 @@
 package api
 
 import (
+	"archive/zip"
+	"backend/internal/model"
 	"backend/internal/service"
 	"backend/internal/util"
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
+// Download formats: content type and file extension
+var downloadFormats = map[string]struct{ contentType, ext string }{
+	"csv":    {"text/csv", "csv"},
+	"xlsx":   {"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", "xlsx"},
+	"json":   {"application/json", "json"},
+	"ndjson": {"application/x-ndjson", "ndjson"},
+	"zip":    {"application/zip", "zip"},
+}
+
+// GET /api/upload/job/:jobId/results/download?format=csv|xlsx|json|ndjson|zip
+// plus the result filter and sort of the results page (see resultFilterFromQuery).
+// Results are streamed from the database: xlsx has one sheet per status, zip one
+// CSV file per status. Once streaming has started an error can only truncate the file.
 func DownloadJobResultsHandler(c *gin.Context) {
 	jobId := c.Param("jobId")
-	typ := c.Query("type")
-	results := service.GetResultsForDownload(jobId, typ)
-	csvData := util.GenerateCSV(results)
-	c.Header("Content-Type", "text/csv")
-	c.Header("Content-Disposition", "attachment; filename=results-"+jobId+"-"+typ+".csv")
-	c.String(200, csvData)
+	format := c.DefaultQuery("format", "csv")
+	f, ok := downloadFormats[format]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv, xlsx, json, ndjson or zip"})
+		return
+	}
+	filter, err := resultFilterFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	job, err := service.GetBulkJobByID(jobId)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return
+	}
+	statuses, err := service.DownloadStatuses(jobId, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	// Jobs créés avec l'en-tête d'origine: fichier enrichi, sinon format historique
+	layout := util.NewResultLayout(job.Headers)
+
+	name := "all"
+	if len(filter.Statuses) > 0 {
+		name = strings.Join(filter.Statuses, "-")
+	}
+	c.Header("Content-Type", f.contentType)
+	c.Header("Content-Disposition", "attachment; filename=results-"+jobId+"-"+name+"."+f.ext)
+	c.Status(http.StatusOK)
+
+	out := bufio.NewWriterSize(c.Writer, 64<<10)
+	switch format {
+	case "xlsx":
+		err = writeResultsXLSX(out, jobId, filter, statuses, layout)
+	case "zip":
+		err = writeResultsZIP(out, jobId, filter, statuses, layout)
+	case "json":
+		err = writeResultsJSON(out, jobId, filter, layout)
+	case "ndjson":
+		err = writeResultsNDJSON(out, jobId, filter, layout)
+	default:
+		err = writeResultsCSV(csv.NewWriter(out), jobId, filter, layout)
+	}
+	if err == nil {
+		err = out.Flush()
+	}
+	if err != nil {
+		c.Error(err)
+	}
+}
+
+func writeResultsCSV(w *csv.Writer, jobId string, filter service.ResultFilter, layout util.ResultLayout) error {
+	w.Write(layout.Columns())
+	err := service.StreamJobResults(jobId, filter, func(r model.EmailResult) error {
+		return w.Write(layout.Row(r))
+	})
+	if err != nil {
+		return err
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// statusFilter restricts the filter to one status (a sheet or a file of the download)
+func statusFilter(filter service.ResultFilter, status string) service.ResultFilter {
+	filter.Statuses = []string{status}
+	return filter
+}
+
+func writeResultsXLSX(out io.Writer, jobId string, filter service.ResultFilter, statuses []string, layout util.ResultLayout) error {
+	x := util.NewXLSXWriter(out)
+	for _, status := range statuses {
+		if err := x.AddSheet(status); err != nil {
+			return err
+		}
+		if err := x.WriteRow(layout.Columns()); err != nil {
+			return err
+		}
+		err := service.StreamJobResults(jobId, statusFilter(filter, status), func(r model.EmailResult) error {
+			return x.WriteRow(layout.Row(r))
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return x.Close()
+}
+
+func writeResultsZIP(out io.Writer, jobId string, filter service.ResultFilter, statuses []string, layout util.ResultLayout) error {
+	zw := zip.NewWriter(out)
+	for _, status := range statuses {
+		f, err := zw.Create(status + ".csv")
+		if err != nil {
+			return err
+		}
+		if err := writeResultsCSV(csv.NewWriter(f), jobId, statusFilter(filter, status), layout); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// writeResultsJSON writes a JSON array, one record at a time
+func writeResultsJSON(out io.Writer, jobId string, filter service.ResultFilter, layout util.ResultLayout) error {
+	if _, err := io.WriteString(out, "["); err != nil {
+		return err
+	}
+	first := true
+	err := service.StreamJobResults(jobId, filter, func(r model.EmailResult) error {
+		b, err := json.Marshal(layout.Record(r))
+		if err != nil {
+			return err
+		}
+		if !first {
+			if _, err := io.WriteString(out, ","); err != nil {
+				return err
+			}
+		}
+		first = false
+		_, err = out.Write(b)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(out, "]\n")
+	return err
+}
+
+func writeResultsNDJSON(out io.Writer, jobId string, filter service.ResultFilter, layout util.ResultLayout) error {
+	enc := json.NewEncoder(out)
+	return service.StreamJobResults(jobId, filter, func(r model.EmailResult) error {
+		return enc.Encode(layout.Record(r))
+	})
 }
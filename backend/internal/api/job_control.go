@@ -0,0 +1,43 @@
+package api
+
+import (
+	"backend/internal/model"
+	"backend/internal/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// POST /api/bulk-verify/:jobId/pause
+func PauseBulkJobHandler(c *gin.Context) {
+	jobControl(c, service.PauseBulkJob)
+}
+
+// POST /api/bulk-verify/:jobId/resume
+func ResumeBulkJobHandler(c *gin.Context) {
+	jobControl(c, service.ResumeBulkJob)
+}
+
+// POST /api/bulk-verify/:jobId/cancel
+func CancelBulkJobHandler(c *gin.Context) {
+	jobControl(c, service.CancelBulkJob)
+}
+
+func jobControl(c *gin.Context, action func(string) (model.BulkJob, error)) {
+	jobId := c.Param("jobId")
+	if _, err := service.GetBulkJobByID(jobId); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return
+	}
+	job, err := action(jobId)
+	if errors.Is(err, service.ErrInvalidJobTransition) {
+		c.JSON(http.StatusConflict, gin.H{"error": "action not allowed for a job in status " + job.Status, "status": job.Status})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"jobId": job.ID, "status": job.Status})
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"backend/internal/service"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GET /api/jobs?page=&pageSize=&sort=uploadedAt&order=desc&status=done,failed&kind=verify&q=leads&tag=&owner=&from=2025-01-01&to=2025-02-01
+// List bulk jobs with their per-status result counts
+func ListJobsHandler(c *gin.Context) {
+	// 1. Pagination
+	query := service.JobListQuery{Page: 1, PageSize: 20, Sort: c.DefaultQuery("sort", "uploadedAt"), Desc: c.DefaultQuery("order", "desc") != "asc"}
+	if p, err := strconv.Atoi(c.Query("page")); err == nil && p > 0 {
+		query.Page = p
+	}
+	if ps, err := strconv.Atoi(c.Query("pageSize")); err == nil && ps > 0 {
+		query.PageSize = min(ps, 100)
+	}
+
+	// 2. Filters
+	if v := c.Query("status"); v != "" {
+		query.Statuses = strings.Split(v, ",")
+	}
+	query.Kind = c.Query("kind")
+	query.Search = c.Query("q")
+	query.Tag = c.Query("tag")
+	query.Owner = c.Query("owner")
+	from, err := parseDateParam(c.Query("from"), false)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from date, expected YYYY-MM-DD or RFC 3339"})
+		return
+	}
+	to, err := parseDateParam(c.Query("to"), true)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to date, expected YYYY-MM-DD or RFC 3339"})
+		return
+	}
+	query.From, query.To = from, to
+
+	// 3. Query
+	jobs, total, err := service.ListBulkJobs(query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"jobs":     jobs,
+		"total":    total,
+		"page":     query.Page,
+		"pageSize": query.PageSize,
+	})
+}
+
+// PATCH /api/jobs/:jobId
+// Body: {"name": "new name", "tags": ["leads", "2025"], "priority": "high"}, all optional
+func UpdateJobHandler(c *gin.Context) {
+	var update service.JobUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	if update.Name != nil && strings.TrimSpace(*update.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name cannot be empty"})
+		return
+	}
+	job, err := service.UpdateBulkJob(c.Param("jobId"), update)
+	if errors.Is(err, service.ErrInvalidPriority) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, service.ErrJobNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// DELETE /api/jobs/:jobId
+// Remove a finished job with its results and re-verification jobs
+func DeleteJobHandler(c *gin.Context) {
+	err := service.DeleteBulkJob(c.Param("jobId"))
+	switch {
+	case errors.Is(err, service.ErrJobNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+	case errors.Is(err, service.ErrJobActive):
+		c.JSON(http.StatusConflict, gin.H{"error": "job is still running, cancel it first"})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// parseDateParam parses an RFC 3339 time or a day; with endOfDay, a day
+// includes its whole 24 hours (to=2025-01-31 ends at midnight on Feb 1st)
+func parseDateParam(v string, endOfDay bool) (*time.Time, error) {
+	if v == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return &t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", v, time.Local)
+	if err != nil {
+		return nil, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
+
+// jobScheduling reads the priority (low, normal, high, urgent) and the owner
+// sharing workers fairly with other owners (X-Owner header or owner field)
+func jobScheduling(c *gin.Context) (priority int, owner string, err error) {
+	priority, err = service.ParsePriority(c.PostForm("priority"))
+	if err != nil {
+		return 0, "", err
+	}
+	owner = strings.TrimSpace(c.GetHeader("X-Owner"))
+	if owner == "" {
+		owner = strings.TrimSpace(c.PostForm("owner"))
+	}
+	return priority, owner, nil
+}
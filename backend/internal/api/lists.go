@@ -0,0 +1,401 @@
+package api
+
+import (
+	"backend/internal/model"
+	"backend/internal/service"
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// listError maps list errors to 400/404/409
+func listError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrListNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "list not found"})
+	case errors.Is(err, service.ErrJobNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+	case errors.Is(err, service.ErrJobActive):
+		c.JSON(http.StatusConflict, gin.H{"error": "a verification of this list is still running"})
+	case errors.Is(err, service.ErrNothingToReverify):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no members match the filter"})
+	case errors.Is(err, service.ErrListNameRequired),
+		errors.Is(err, service.ErrInvalidDedupe),
+		errors.Is(err, service.ErrInvalidPriority),
+		errors.Is(err, service.ErrRowLimit),
+		errors.Is(err, service.ErrInvalidWebhookURL):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// listOwner reads the owner of a new list (X-Owner header)
+func listOwner(c *gin.Context) string {
+	return strings.TrimSpace(c.GetHeader("X-Owner"))
+}
+
+type createListRequest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Emails      []string `json:"emails"`
+}
+
+// POST /api/lists
+// Body: {"name": "Customers", "description": "...", "emails": ["a@example.com"]}
+func CreateListHandler(c *gin.Context) {
+	var req createListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	list, report, err := service.CreateList(req.Name, req.Description, listOwner(c), req.Emails)
+	if err != nil {
+		listError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"list": list, "import": report})
+}
+
+// POST /api/lists/import
+// Multipart: file (or uploadId from /api/uploads/preview), emailCol (guessed
+// when omitted), sheet, name (file name by default), description.
+// Members are added synchronously; invalid addresses and duplicates are skipped.
+func ImportListHandler(c *gin.Context) {
+	// 1. Get file: new upload, or uploadId from /api/uploads/preview
+	source, err := openBulkSource(c)
+	if err != nil {
+		switch {
+		case isUploadTooLarge(err):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file too large"})
+		case errors.Is(err, errFileRequired):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "file or uploadId is required"})
+		case errors.Is(err, service.ErrUploadNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "upload not found"})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "cannot open file"})
+		}
+		return
+	}
+
+	// 2. Header and email column
+	headers, info, err := service.ReadSpooledHeader(source.spoolPath, source.sheet)
+	if err != nil {
+		source.discard()
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot read file header: " + err.Error()})
+		return
+	}
+	colIndex, err := service.ResolveColumn(source.spoolPath, info.Sheet, headers, c.PostForm("emailCol"), model.WorkKindVerify)
+	if err != nil {
+		source.discard()
+		c.JSON(http.StatusBadRequest, gin.H{"error": "email column not found"})
+		return
+	}
+	if err := source.claim(); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "upload already used"})
+		return
+	}
+	defer os.Remove(source.spoolPath)
+
+	// 3. Create list and add members
+	name := c.PostForm("name")
+	if strings.TrimSpace(name) == "" {
+		name = source.fileName
+	}
+	list, report, err := service.ImportList(name, c.PostForm("description"), listOwner(c), source.spoolPath, info.Sheet, colIndex)
+	if err != nil {
+		listError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"list": list, "import": report, "source": info, "emailColumn": headers[colIndex]})
+}
+
+type listFromJobRequest struct {
+	JobID    string   `json:"jobId"`
+	Name     string   `json:"name"`     // job file name by default
+	Statuses []string `json:"statuses"` // verification jobs only, all by default
+}
+
+// POST /api/lists/from-job
+// Creates a list from a verification job (status and flags kept) or from the
+// emails found by an extraction job
+func CreateListFromJobHandler(c *gin.Context) {
+	var req listFromJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	list, report, err := service.CreateListFromJob(req.JobID, req.Name, listOwner(c), req.Statuses)
+	if err != nil {
+		listError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"list": list, "import": report})
+}
+
+type mergeListsRequest struct {
+	Name    string   `json:"name"`
+	ListIDs []string `json:"listIds"`
+	Dedupe  string   `json:"dedupe"` // exact (default) or canonical
+}
+
+// POST /api/lists/merge
+// Body: {"name": "All customers", "listIds": ["...", "..."], "dedupe": "canonical"}
+func MergeListsHandler(c *gin.Context) {
+	var req mergeListsRequest
+	if err := c.ShouldBindJSON(&req); err != nil || len(req.ListIDs) < 2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name and at least two listIds are required"})
+		return
+	}
+	list, err := service.MergeLists(req.Name, listOwner(c), req.ListIDs, req.Dedupe)
+	if err != nil {
+		listError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"list": list})
+}
+
+// GET /api/lists?page=&pageSize=&q=&owner=
+func ListListsHandler(c *gin.Context) {
+	query := service.ListQuery{
+		Page:     1,
+		PageSize: 20,
+		Search:   c.Query("q"),
+		Owner:    c.Query("owner"),
+	}
+	if p, err := strconv.Atoi(c.Query("page")); err == nil && p > 0 {
+		query.Page = p
+	}
+	if ps, err := strconv.Atoi(c.Query("pageSize")); err == nil && ps > 0 {
+		query.PageSize = min(ps, 100)
+	}
+	lists, total, err := service.ListLists(query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"lists": lists, "total": total, "page": query.Page, "pageSize": query.PageSize})
+}
+
+// GET /api/lists/:listId
+func GetListHandler(c *gin.Context) {
+	list, err := service.GetList(c.Param("listId"))
+	if err != nil {
+		listError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// PATCH /api/lists/:listId
+// Body: {"name": "...", "description": "..."}
+func UpdateListHandler(c *gin.Context) {
+	var update service.ListUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	list, err := service.UpdateList(c.Param("listId"), update)
+	if err != nil {
+		listError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// DELETE /api/lists/:listId
+func DeleteListHandler(c *gin.Context) {
+	if err := service.DeleteList(c.Param("listId")); err != nil {
+		listError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// memberFilterFromQuery reads ?status=valid,unknown&minScore=&maxScore=&domain=&excludeDomain=
+// &roleBased=&disposable=&catchAll=&free=&q=
+func memberFilterFromQuery(c *gin.Context) service.MemberFilter {
+	filter := service.MemberFilter{
+		Statuses:       splitQuery(c.Query("status")),
+		Domains:        splitQuery(c.Query("domain")),
+		ExcludeDomains: splitQuery(c.Query("excludeDomain")),
+		Search:         c.Query("q"),
+	}
+	if n, err := strconv.Atoi(c.Query("minScore")); err == nil {
+		filter.MinScore = &n
+	}
+	if n, err := strconv.Atoi(c.Query("maxScore")); err == nil {
+		filter.MaxScore = &n
+	}
+	for param, dst := range map[string]**bool{
+		"roleBased":  &filter.RoleBased,
+		"disposable": &filter.Disposable,
+		"catchAll":   &filter.CatchAll,
+		"free":       &filter.Free,
+	} {
+		if b, err := strconv.ParseBool(c.Query(param)); err == nil {
+			*dst = &b
+		}
+	}
+	return filter
+}
+
+func splitQuery(v string) []string {
+	var out []string
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// GET /api/lists/:listId/members?page=&pageSize= plus member filters
+func ListMembersHandler(c *gin.Context) {
+	page, pageSize := 1, 50
+	if p, err := strconv.Atoi(c.Query("page")); err == nil && p > 0 {
+		page = p
+	}
+	if ps, err := strconv.Atoi(c.Query("pageSize")); err == nil && ps > 0 {
+		pageSize = min(ps, 500)
+	}
+	members, total, err := service.ListListMembers(c.Param("listId"), memberFilterFromQuery(c), page, pageSize)
+	if err != nil {
+		listError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"members": members, "total": total, "page": page, "pageSize": pageSize})
+}
+
+type listMembersRequest struct {
+	Emails []string `json:"emails"`
+}
+
+// POST /api/lists/:listId/members
+// Body: {"emails": ["a@example.com", "b@example.com"]}
+func AddListMembersHandler(c *gin.Context) {
+	var req listMembersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	report, err := service.AddListMembers(c.Param("listId"), req.Emails)
+	if err != nil {
+		listError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// DELETE /api/lists/:listId/members
+// Body: {"emails": ["a@example.com"]}
+func RemoveListMembersHandler(c *gin.Context) {
+	var req listMembersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	removed, err := service.RemoveListMembers(c.Param("listId"), req.Emails)
+	if err != nil {
+		listError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"removed": removed})
+}
+
+type subtractListRequest struct {
+	ListID string `json:"listId"` // members to remove (compared by mailbox)
+	Name   string `json:"name"`   // "<list> - <other>" by default
+}
+
+// POST /api/lists/:listId/subtract
+// Creates a list of the members that are not in listId (e.g. minus unsubscribes)
+func SubtractListHandler(c *gin.Context) {
+	var req subtractListRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.ListID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "listId is required"})
+		return
+	}
+	list, err := service.SubtractList(c.Param("listId"), req.ListID, req.Name)
+	if err != nil {
+		listError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"list": list})
+}
+
+type segmentListRequest struct {
+	Name   string               `json:"name"`
+	Filter service.MemberFilter `json:"filter"`
+}
+
+// POST /api/lists/:listId/segment
+// Body: {"name": "Valid corporate", "filter": {"statuses": ["valid"], "minScore": 80,
+// "free": false, "excludeDomains": ["example.org"]}}
+func SegmentListHandler(c *gin.Context) {
+	var req segmentListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	list, err := service.SegmentList(c.Param("listId"), req.Name, req.Filter)
+	if err != nil {
+		listError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"list": list})
+}
+
+type verifyListRequest struct {
+	Filter        service.MemberFilter `json:"filter"`   // all members by default
+	Priority      string               `json:"priority"` // low, normal, high, urgent
+	WebhookURL    string               `json:"webhookUrl"`
+	WebhookSecret string               `json:"webhookSecret"`
+}
+
+// POST /api/lists/:listId/verify
+// Queues a verification job of the list members; each result updates the
+// member. Follow it with the bulk-verify status, events and download endpoints.
+func VerifyListHandler(c *gin.Context) {
+	var req verifyListRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+	}
+	priority, err := service.ParsePriority(req.Priority)
+	if err != nil {
+		listError(c, err)
+		return
+	}
+	if req.WebhookURL != "" {
+		if err := service.ValidateWebhookURL(req.WebhookURL); err != nil {
+			listError(c, err)
+			return
+		}
+		if req.WebhookSecret == "" {
+			req.WebhookSecret = service.NewWebhookSecret()
+		}
+	}
+	job, err := service.VerifyList(c.Param("listId"), service.ListVerifyOptions{
+		Filter:        req.Filter,
+		Priority:      priority,
+		Owner:         listOwner(c),
+		WebhookURL:    req.WebhookURL,
+		WebhookSecret: req.WebhookSecret,
+	})
+	if err != nil {
+		listError(c, err)
+		return
+	}
+	resp := gin.H{"jobId": job.ID, "listId": c.Param("listId"), "status": job.Status, "totalEmails": job.TotalEmails}
+	if req.WebhookURL != "" {
+		resp["webhookSecret"] = req.WebhookSecret
+	}
+	c.JSON(http.StatusAccepted, resp)
+}
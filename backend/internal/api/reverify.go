@@ -0,0 +1,62 @@
+package api
+
+import (
+	"backend/internal/service"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// POST /api/bulk-verify/:jobId/reverify
+// Body (optional): {"statuses": ["unknown", "accept_all"], "reason": "timeout"}.
+// Statuses may also be given as ?status=unknown,accept_all and reason as ?reason=.
+// Creates a child job; its results replace the parent's for the same rows. A
+// child job is re-verified through its root job, which holds the latest results.
+func ReverifyBulkJobHandler(c *gin.Context) {
+	jobId := c.Param("jobId")
+	if _, err := service.GetBulkJobByID(jobId); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return
+	}
+
+	// 1. Filter
+	var filter service.ReverifyFilter
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&filter); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+	}
+	if v := c.Query("status"); v != "" {
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				filter.Statuses = append(filter.Statuses, s)
+			}
+		}
+	}
+	if v := c.Query("reason"); v != "" {
+		filter.Reason = v
+	}
+
+	// 2. Create child job
+	child, err := service.ReverifyBulkJob(jobId, filter)
+	switch {
+	case errors.Is(err, service.ErrInvalidJobTransition):
+		c.JSON(http.StatusConflict, gin.H{"error": "only verification jobs can be re-verified"})
+	case errors.Is(err, service.ErrJobActive):
+		c.JSON(http.StatusConflict, gin.H{"error": "job or a previous re-verification is still running"})
+	case errors.Is(err, service.ErrNothingToReverify):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no results match the filter"})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create job"})
+	default:
+		c.JSON(http.StatusAccepted, gin.H{
+			"jobId":       child.ID,
+			"parentJobId": *child.ParentJobID,
+			"status":      child.Status,
+			"totalEmails": child.TotalEmails,
+		})
+	}
+}
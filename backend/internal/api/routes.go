@@ -15,14 +15,74 @@ func RegisterRoutes(r *gin.Engine) {
 	// stats
 	r.GET("/api/stats", GetDashboardStatsHandler)
 
+	// Upload preview: headers, first rows and guessed columns; jobs reuse the file via uploadId
+	r.POST("/api/uploads/preview", limitUploadSize(), PreviewUploadHandler)
+	r.POST("/api/uploads/:uploadId/analyze", AnalyzeUploadHandler)
+
+	// Bulk jobs history (verify and extract)
+	r.GET("/api/jobs", ListJobsHandler)
+	r.PATCH("/api/jobs/:jobId", UpdateJobHandler)
+	r.DELETE("/api/jobs/:jobId", DeleteJobHandler)
+
+	// Webhooks notified when jobs end, with delivery log
+	r.POST("/api/webhooks", CreateWebhookHandler)
+	r.GET("/api/webhooks", ListWebhooksHandler)
+	r.DELETE("/api/webhooks/:webhookId", DeleteWebhookHandler)
+	r.GET("/api/webhooks/deliveries", ListWebhookDeliveriesHandler)
+	r.POST("/api/webhooks/deliveries/:deliveryId/redeliver", RedeliverWebhookHandler)
+
+	// Contact lists: import, merge, subtract, segment and verify
+	r.GET("/api/lists", ListListsHandler)
+	r.POST("/api/lists", CreateListHandler)
+	r.POST("/api/lists/import", limitUploadSize(), ImportListHandler)
+	r.POST("/api/lists/from-job", CreateListFromJobHandler)
+	r.POST("/api/lists/merge", MergeListsHandler)
+	r.GET("/api/lists/:listId", GetListHandler)
+	r.PATCH("/api/lists/:listId", UpdateListHandler)
+	r.DELETE("/api/lists/:listId", DeleteListHandler)
+	r.GET("/api/lists/:listId/members", ListMembersHandler)
+	r.POST("/api/lists/:listId/members", AddListMembersHandler)
+	r.DELETE("/api/lists/:listId/members", RemoveListMembersHandler)
+	r.POST("/api/lists/:listId/subtract", SubtractListHandler)
+	r.POST("/api/lists/:listId/segment", SegmentListHandler)
+	r.POST("/api/lists/:listId/verify", VerifyListHandler)
+
+	// Account-wide suppression list, consulted by bulk verification and extraction
+	r.GET("/api/suppressions", ListSuppressionsHandler)
+	r.POST("/api/suppressions", AddSuppressionsHandler)
+	r.POST("/api/suppressions/import", limitUploadSize(), ImportSuppressionsHandler)
+	r.GET("/api/suppressions/export", ExportSuppressionsHandler)
+	r.POST("/api/suppressions/check", CheckSuppressionsHandler)
+	r.DELETE("/api/suppressions/:suppressionId", DeleteSuppressionHandler)
+
+	// Scheduled re-verification of jobs and lists, with a status change report per run
+	r.POST("/api/schedules", CreateScheduleHandler)
+	r.GET("/api/schedules", ListSchedulesHandler)
+	r.GET("/api/schedules/:scheduleId", GetScheduleHandler)
+	r.PATCH("/api/schedules/:scheduleId", UpdateScheduleHandler)
+	r.DELETE("/api/schedules/:scheduleId", DeleteScheduleHandler)
+	r.POST("/api/schedules/:scheduleId/run", RunScheduleHandler)
+
 	// Email verification
 	r.POST("/api/verify", service.VerifyEmailHandler)
-	r.POST("/api/bulk-verify", BulkVerifyHandler)
+	r.POST("/api/bulk-verify", limitUploadSize(), BulkVerifyHandler)
+	r.GET("/api/bulk-verify/:jobId/status", GetBulkJobStatusHandler)
+	r.GET("/api/bulk-verify/:jobId/events", BulkJobEventsHandler)
+	r.POST("/api/bulk-verify/:jobId/pause", PauseBulkJobHandler)
+	r.POST("/api/bulk-verify/:jobId/resume", ResumeBulkJobHandler)
+	r.POST("/api/bulk-verify/:jobId/cancel", CancelBulkJobHandler)
+	r.POST("/api/bulk-verify/:jobId/reverify", ReverifyBulkJobHandler)
+	r.GET("/api/bulk-verify/:jobId/diff", GetJobDiffHandler)
 	r.GET("/api/upload/job/:jobId/results/download", DownloadJobResultsHandler)
 	r.GET("/api/upload/job/:jobId/results", GetJobResultsHandler)
 
 	// email extractor
 	r.POST("/api/extract", SingleExtractHandler)
-	r.POST("/api/bulk-extract", BulkExtractHandler)
+	r.POST("/api/bulk-extract", limitUploadSize(), BulkExtractHandler)
+	r.GET("/api/bulk-extract/:jobId/status", GetBulkJobStatusHandler)
+	r.GET("/api/bulk-extract/:jobId/results", GetBulkExtractResultsHandler)
+	r.POST("/api/bulk-extract/:jobId/pause", PauseBulkJobHandler)
+	r.POST("/api/bulk-extract/:jobId/resume", ResumeBulkJobHandler)
+	r.POST("/api/bulk-extract/:jobId/cancel", CancelBulkJobHandler)
 
 }
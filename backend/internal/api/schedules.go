@@ -0,0 +1,159 @@
+package api
+
+import (
+	"backend/internal/service"
+	"backend/internal/util"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// scheduleError maps schedule validation errors to 400/404/409
+func scheduleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrScheduleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "schedule not found"})
+	case errors.Is(err, service.ErrJobNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+	case errors.Is(err, service.ErrListNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "list not found"})
+	case errors.Is(err, service.ErrInvalidJobTransition):
+		c.JSON(http.StatusConflict, gin.H{"error": "only verification jobs (not re-verifications or list runs) can be scheduled"})
+	case errors.Is(err, service.ErrScheduleTarget),
+		errors.Is(err, util.ErrInvalidCron),
+		errors.Is(err, service.ErrInvalidTimezone),
+		errors.Is(err, service.ErrScheduleNeverRuns),
+		errors.Is(err, service.ErrInvalidStatusFilter),
+		errors.Is(err, service.ErrInvalidNotifyEmail),
+		errors.Is(err, service.ErrInvalidWebhookURL):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// POST /api/schedules
+// Body: {"jobId": "..." or "listId": "...", "cron": "0 3 * * 1", "timezone": "Europe/Paris",
+// "statuses": ["valid", "accept_all", "unknown"], "webhookUrl": "...", "notifyEmail": "..."}.
+// Each run re-verifies the job's (or list's) matching rows in a new job; the webhook
+// secret is only returned in this response.
+func CreateScheduleHandler(c *gin.Context) {
+	var in service.ScheduleInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	schedule, secret, err := service.CreateSchedule(in)
+	if err != nil {
+		scheduleError(c, err)
+		return
+	}
+	resp := gin.H{"schedule": schedule}
+	if secret != "" {
+		resp["webhookSecret"] = secret
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+// GET /api/schedules?jobId=&listId=
+func ListSchedulesHandler(c *gin.Context) {
+	schedules, err := service.ListSchedules(c.Query("jobId"), c.Query("listId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"schedules": schedules})
+}
+
+// GET /api/schedules/:scheduleId?runs=20
+// Schedule with its latest runs and the status changes each one found
+func GetScheduleHandler(c *gin.Context) {
+	runs := 20
+	if n, err := strconv.Atoi(c.Query("runs")); err == nil && n > 0 {
+		runs = min(n, 100)
+	}
+	schedule, history, err := service.GetSchedule(c.Param("scheduleId"), runs)
+	if err != nil {
+		scheduleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"schedule": schedule, "runs": history})
+}
+
+// PATCH /api/schedules/:scheduleId
+// Same fields as creation (except jobId and listId); omitted fields are unchanged.
+// "active": false pauses the schedule.
+func UpdateScheduleHandler(c *gin.Context) {
+	var in service.ScheduleInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	schedule, secret, err := service.UpdateSchedule(c.Param("scheduleId"), in)
+	if err != nil {
+		scheduleError(c, err)
+		return
+	}
+	resp := gin.H{"schedule": schedule}
+	if secret != "" {
+		resp["webhookSecret"] = secret
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// DELETE /api/schedules/:scheduleId
+func DeleteScheduleHandler(c *gin.Context) {
+	if err := service.DeleteSchedule(c.Param("scheduleId")); err != nil {
+		scheduleError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// POST /api/schedules/:scheduleId/run
+// Runs the schedule now, without moving its next run
+func RunScheduleHandler(c *gin.Context) {
+	job, err := service.RunScheduleNow(c.Param("scheduleId"))
+	switch {
+	case errors.Is(err, service.ErrJobActive):
+		c.JSON(http.StatusConflict, gin.H{"error": "job or a previous run is still running"})
+	case errors.Is(err, service.ErrNothingToReverify):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no results match the schedule statuses"})
+	case err != nil:
+		scheduleError(c, err)
+	default:
+		c.JSON(http.StatusAccepted, gin.H{"jobId": job.ID, "status": job.Status, "totalEmails": job.TotalEmails})
+	}
+}
+
+// GET /api/bulk-verify/:jobId/diff?from=valid&to=invalid&page=1&pageSize=100
+// Status changes found by a re-verification or list verification job, with a
+// summary per transition
+func GetJobDiffHandler(c *gin.Context) {
+	query := service.DiffQuery{
+		JobID:    c.Param("jobId"),
+		From:     c.Query("from"),
+		To:       c.Query("to"),
+		Page:     1,
+		PageSize: 100,
+	}
+	if p, err := strconv.Atoi(c.Query("page")); err == nil && p > 0 {
+		query.Page = p
+	}
+	if ps, err := strconv.Atoi(c.Query("pageSize")); err == nil && ps > 0 {
+		query.PageSize = min(ps, 1000)
+	}
+	report, err := service.GetJobDiff(query)
+	switch {
+	case errors.Is(err, service.ErrJobNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+	case errors.Is(err, service.ErrInvalidJobTransition):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "job is not a re-verification or a list verification"})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusOK, report)
+	}
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"backend/internal/model"
 	"backend/internal/service"
 	"net/http"
 
@@ -10,6 +11,10 @@ import (
 func SingleExtractHandler(c *gin.Context) {
 	type Request struct {
 		Website string `json:"website" binding:"required"`
+		// fast (home page emails) or deep (crawled site: emails, phones and social profiles)
+		Mode string `json:"mode"`
+		// Skip robots.txt (and its Crawl-delay) for a site we own
+		IgnoreRobots bool `json:"ignoreRobots"`
 	}
 
 	var req Request
@@ -20,7 +25,15 @@ func SingleExtractHandler(c *gin.Context) {
 		return
 	}
 
-	emails, err := service.ExtractEmailsFromSiteFast(req.Website)
+	mode, err := service.ParseExtractMode(req.Mode)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Mode invalide (fast ou deep) / وضع غير صالح (fast أو deep)",
+		})
+		return
+	}
+
+	contacts, err := service.ExtractSite(req.Website, mode, req.IgnoreRobots)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Erreur lors de l'extraction / خطأ أثناء الاستخراج",
@@ -29,7 +42,16 @@ func SingleExtractHandler(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"emails": emails,
-	})
+	resp := gin.H{"emails": contacts.Emails}
+	if mode == model.ExtractModeDeep {
+		resp["mode"] = mode
+		resp["phones"] = contacts.Phones
+		resp["socials"] = contacts.Socials
+		resp["organizations"] = contacts.Organizations
+	}
+	// Pages not visited because robots.txt disallows them
+	if len(contacts.RobotsSkipped) > 0 {
+		resp["robotsSkipped"] = contacts.RobotsSkipped
+	}
+	c.JSON(http.StatusOK, resp)
 }
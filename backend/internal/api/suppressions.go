@@ -0,0 +1,172 @@
+package api
+
+import (
+	"backend/internal/service"
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type suppressionRequest struct {
+	service.SuppressionInput
+	Entries []service.SuppressionInput `json:"entries"`
+}
+
+// POST /api/suppressions
+// Body: {"value": "john@example.com", "reason": "unsubscribed"} or {"entries": [...]}.
+// The kind (email, canonical, domain, pattern) is guessed from the value when omitted:
+// "example.com" or "@example.com" suppresses a domain and its subdomains, "*" and "?"
+// make a wildcard pattern ("info@*", "*.gov").
+func AddSuppressionsHandler(c *gin.Context) {
+	var req suppressionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	entries := req.Entries
+	if req.Value != "" {
+		entries = append(entries, req.SuppressionInput)
+	}
+	if len(entries) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "value or entries is required"})
+		return
+	}
+	report, err := service.AddSuppressions(entries)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if report.Added == 0 && report.Existing == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "no valid entry", "report": report})
+		return
+	}
+	c.JSON(http.StatusCreated, report)
+}
+
+// GET /api/suppressions?kind=domain&q=&page=1&pageSize=50
+func ListSuppressionsHandler(c *gin.Context) {
+	query := service.SuppressionQuery{
+		Kind:     c.Query("kind"),
+		Search:   c.Query("q"),
+		Page:     1,
+		PageSize: 50,
+	}
+	if p, err := strconv.Atoi(c.Query("page")); err == nil && p > 0 {
+		query.Page = p
+	}
+	if ps, err := strconv.Atoi(c.Query("pageSize")); err == nil && ps > 0 {
+		query.PageSize = min(ps, 1000)
+	}
+	entries, total, err := service.ListSuppressions(query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"suppressions": entries, "total": total, "page": query.Page, "pageSize": query.PageSize})
+}
+
+// DELETE /api/suppressions/:suppressionId
+func DeleteSuppressionHandler(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("suppressionId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "suppression not found"})
+		return
+	}
+	if err := service.DeleteSuppression(id); err != nil {
+		if errors.Is(err, service.ErrSuppressionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "suppression not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// POST /api/suppressions/import
+// Multipart: file (CSV/XLSX) or uploadId, optional sheet, column, reason and source.
+// The value is read from column, else from a "value", "email" or "domain" column,
+// else from the first column; kind, reason and source columns are used when present,
+// so a file from /api/suppressions/export imports back as is.
+func ImportSuppressionsHandler(c *gin.Context) {
+	source, err := openBulkSource(c)
+	if err != nil {
+		switch {
+		case isUploadTooLarge(err):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file too large"})
+		case errors.Is(err, errFileRequired):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "file or uploadId is required"})
+		case errors.Is(err, service.ErrUploadNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "upload not found"})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "cannot open file"})
+		}
+		return
+	}
+	if err := source.claim(); err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "upload already used"})
+		return
+	}
+	defer os.Remove(source.spoolPath)
+
+	origin := strings.TrimSpace(c.PostForm("source"))
+	if origin == "" {
+		origin = "import:" + source.fileName
+	}
+	report, err := service.ImportSuppressions(source.spoolPath, source.sheet, c.PostForm("column"), c.PostForm("reason"), origin)
+	switch {
+	case errors.Is(err, service.ErrColumnNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "column not found"})
+	case err != nil:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot import file: " + err.Error()})
+	default:
+		c.JSON(http.StatusCreated, report)
+	}
+}
+
+// GET /api/suppressions/export
+// CSV with kind, value, reason, source and createdAt columns
+func ExportSuppressionsHandler(c *gin.Context) {
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", "attachment; filename=suppressions-"+time.Now().Format("20060102")+".csv")
+	c.Status(http.StatusOK)
+	if err := service.ExportSuppressions(c.Writer); err != nil {
+		// Headers are already sent: the file is truncated
+		c.Error(err)
+	}
+}
+
+type checkSuppressionsRequest struct {
+	Emails []string `json:"emails"`
+}
+
+// POST /api/suppressions/check
+// Body: {"emails": ["..."]}; returns the matching entry of each suppressed email
+func CheckSuppressionsHandler(c *gin.Context) {
+	var req checkSuppressionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil || len(req.Emails) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "emails is required"})
+		return
+	}
+	if len(req.Emails) > 1000 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "at most 1000 emails per request"})
+		return
+	}
+	matches, err := service.CheckSuppressed(req.Emails)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	suppressed := map[string]*service.SuppressionMatch{}
+	for email, m := range matches {
+		if m != nil {
+			suppressed[email] = m
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"suppressed": suppressed})
+}
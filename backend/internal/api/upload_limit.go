@@ -0,0 +1,27 @@
+package api
+
+import (
+	"backend/internal/config"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// limitUploadSize refuse les corps de requête plus gros que MAX_UPLOAD_MB
+func limitUploadSize() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		max := config.Load().MaxUploadBytes
+		if c.Request.ContentLength > max {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file too large"})
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, max)
+		c.Next()
+	}
+}
+
+func isUploadTooLarge(err error) bool {
+	var maxErr *http.MaxBytesError
+	return errors.As(err, &maxErr)
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"backend/internal/model"
+	"backend/internal/service"
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// POST /api/uploads/preview
+// Parse the first rows of an uploaded list and guess the email and website
+// columns. The file is kept: bulk jobs can reference it with uploadId.
+func PreviewUploadHandler(c *gin.Context) {
+	// 1. Get file
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		if isUploadTooLarge(err) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+
+	// 2. Number of rows to return (default 10)
+	rows := 10
+	if v := c.PostForm("rows"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			rows = min(n, service.MaxPreviewRows)
+		}
+	}
+
+	// 3. Spool, detect format and sample rows
+	preview, err := service.PreviewUpload(fileHeader, c.PostForm("sheet"), rows)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot read file: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, preview)
+}
+
+// POST /api/uploads/:uploadId/analyze
+// Pre-flight report of a previewed upload (duplicates, syntax errors, disposable
+// and role counts, domains) before a bulk verification is started
+func AnalyzeUploadHandler(c *gin.Context) {
+	upload, err := service.GetUpload(c.Param("uploadId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "upload not found"})
+		return
+	}
+	sheet := upload.Sheet
+	if s := c.PostForm("sheet"); s != "" {
+		sheet = s
+	}
+	headers, _, err := service.ReadSpooledHeader(upload.SpoolPath, sheet)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot read file header: " + err.Error()})
+		return
+	}
+	colIndex, err := service.ResolveColumn(upload.SpoolPath, sheet, headers, c.PostForm("emailCol"), model.WorkKindVerify)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "email column not found"})
+		return
+	}
+	skipDuplicates := c.DefaultPostForm("skipDuplicates", "true") != "false"
+	report, err := service.AnalyzeSpooledList(upload.SpoolPath, sheet, colIndex, skipDuplicates)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot read file: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"uploadId": upload.ID, "emailColumn": headers[colIndex], "preflight": report})
+}
+
+// bulkSource is the list a bulk job reads: a new file or a previewed upload
+type bulkSource struct {
+	uploadID  string
+	fileName  string
+	spoolPath string
+	sheet     string
+}
+
+var errFileRequired = errors.New("file is required")
+
+// openBulkSource spools the "file" form field, or resolves "uploadId" when set
+func openBulkSource(c *gin.Context) (bulkSource, error) {
+	if id := c.PostForm("uploadId"); id != "" {
+		upload, err := service.GetUpload(id)
+		if err != nil {
+			return bulkSource{}, err
+		}
+		sheet := upload.Sheet
+		if s := c.PostForm("sheet"); s != "" {
+			sheet = s
+		}
+		return bulkSource{uploadID: upload.ID, fileName: upload.FileName, spoolPath: upload.SpoolPath, sheet: sheet}, nil
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		if isUploadTooLarge(err) {
+			return bulkSource{}, err
+		}
+		return bulkSource{}, errFileRequired
+	}
+	spoolPath, err := service.SpoolUpload(fileHeader)
+	if err != nil {
+		return bulkSource{}, err
+	}
+	return bulkSource{fileName: fileHeader.Filename, spoolPath: spoolPath, sheet: c.PostForm("sheet")}, nil
+}
+
+// claim hands a previewed upload over to the job being created; from then on
+// the file belongs to this request and discard removes it
+func (s *bulkSource) claim() error {
+	if s.uploadID == "" {
+		return nil
+	}
+	if err := service.ClaimUpload(s.uploadID); err != nil {
+		return err
+	}
+	s.uploadID = ""
+	return nil
+}
+
+// discard removes a file spooled for this request; a previewed upload is kept
+// so that the caller can retry with other options
+func (s bulkSource) discard() {
+	if s.uploadID == "" {
+		os.Remove(s.spoolPath)
+	}
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"backend/internal/service"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type createWebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"` // job.finished, job.failed, job.cancelled
+	Secret string   `json:"secret"` // generated when empty
+}
+
+// POST /api/webhooks
+// Register an account webhook, notified when any job ends. The signing secret
+// is only returned in this response.
+func CreateWebhookHandler(c *gin.Context) {
+	var req createWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+	hook, secret, err := service.CreateWebhook(req.URL, req.Events, req.Secret)
+	if errors.Is(err, service.ErrInvalidWebhookURL) || errors.Is(err, service.ErrInvalidWebhookEvent) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"webhook": hook, "secret": secret})
+}
+
+// GET /api/webhooks
+func ListWebhooksHandler(c *gin.Context) {
+	hooks, err := service.ListWebhooks()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"webhooks": hooks})
+}
+
+// DELETE /api/webhooks/:webhookId
+func DeleteWebhookHandler(c *gin.Context) {
+	err := service.DeleteWebhook(c.Param("webhookId"))
+	if errors.Is(err, service.ErrWebhookNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "webhook not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GET /api/webhooks/deliveries?jobId=&webhookId=&status=pending|delivered|failed&page=&pageSize=
+// Delivery log, most recent first
+func ListWebhookDeliveriesHandler(c *gin.Context) {
+	query := service.DeliveryQuery{
+		JobID:     c.Query("jobId"),
+		WebhookID: c.Query("webhookId"),
+		Status:    c.Query("status"),
+		Page:      1,
+		PageSize:  50,
+	}
+	if p, err := strconv.Atoi(c.Query("page")); err == nil && p > 0 {
+		query.Page = p
+	}
+	if ps, err := strconv.Atoi(c.Query("pageSize")); err == nil && ps > 0 {
+		query.PageSize = min(ps, 200)
+	}
+	deliveries, total, err := service.ListWebhookDeliveries(query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"deliveries": deliveries, "total": total, "page": query.Page, "pageSize": query.PageSize})
+}
+
+// POST /api/webhooks/deliveries/:deliveryId/redeliver
+func RedeliverWebhookHandler(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("deliveryId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid delivery id"})
+		return
+	}
+	err = service.RedeliverWebhook(id)
+	if errors.Is(err, service.ErrWebhookNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "delivery not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"deliveryId": id, "status": "pending"})
+}
+
+// jobWebhook reads the optional per-job webhook form fields; a secret is
+// generated when webhookUrl is set without webhookSecret
+func jobWebhook(c *gin.Context) (url, secret string, err error) {
+	url = c.PostForm("webhookUrl")
+	if url == "" {
+		return "", "", nil
+	}
+	if err := service.ValidateWebhookURL(url); err != nil {
+		return "", "", err
+	}
+	secret = c.PostForm("webhookSecret")
+	if secret == "" {
+		secret = service.NewWebhookSecret()
+	}
+	return url, secret, nil
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config regroupe les paramètres lus depuis l'environnement
+type Config struct {
+	DatabaseURL string
+	HTTPAddr    string
+	// Nombre de goroutines qui consomment la file (0 = aucun worker dans ce processus)
+	QueueWorkers int
+	// Types de travail traités par ce processus (verify, extract)
+	WorkerKinds []string
+	// Répertoire où les fichiers uploadés sont spoolés avant ingestion
+	UploadDir string
+	// Taille maximale d'un upload en octets
+	MaxUploadBytes int64
+	// Nombre maximal de lignes de données ingérées par upload
+	MaxUploadRows int
+	// Durée de conservation d'un fichier prévisualisé qui n'a pas été utilisé par un job
+	UploadTTL time.Duration
+	// Nombre maximal de pages visitées par site: extraction fast (page d'accueil et
+	// pages de contact) et deep (site crawlé, pages de contact d'abord)
+	CrawlFastPages int
+	CrawlDeepPages int
+	// Serveur SMTP des notifications par email (désactivées si SMTPHost est vide)
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUser     string
+	SMTPPassword string
+	SMTPFrom     string
+	// Autorise les webhooks vers des adresses privées ou locales (développement uniquement)
+	WebhookAllowPrivate bool
+}
+
+// Load lit la configuration depuis les variables d'environnement
+func Load() Config {
+	return Config{
+		DatabaseURL:    getEnv("DATABASE_URL", "host=localhost user=postgres password=postgres dbname=emailverifier1 port=5432 sslmode=disable"),
+		HTTPAddr:       getEnv("HTTP_ADDR", ":3009"),
+		QueueWorkers:   getEnvInt("QUEUE_WORKERS", runtime.NumCPU()*4),
+		WorkerKinds:    getEnvList("WORKER_KINDS", []string{"verify", "extract"}),
+		UploadDir:      getEnv("UPLOAD_DIR", filepath.Join(os.TempDir(), "mailhound-uploads")),
+		MaxUploadBytes: int64(getEnvInt("MAX_UPLOAD_MB", 200)) << 20,
+		MaxUploadRows:  getEnvInt("MAX_UPLOAD_ROWS", 2000000),
+		UploadTTL:      time.Duration(getEnvInt("UPLOAD_TTL_HOURS", 24)) * time.Hour,
+		CrawlFastPages: getEnvInt("CRAWL_FAST_PAGES", 3),
+		CrawlDeepPages: getEnvInt("CRAWL_DEEP_PAGES", 20),
+		SMTPHost:       getEnv("SMTP_HOST", ""),
+		SMTPPort:       getEnvInt("SMTP_PORT", 587),
+		SMTPUser:       getEnv("SMTP_USER", ""),
+		SMTPPassword:   getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:       getEnv("SMTP_FROM", "mailhound@localhost"),
+
+		WebhookAllowPrivate: getEnvBool("WEBHOOK_ALLOW_PRIVATE", false),
+	}
+}
+
+func getEnv(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+func getEnvInt(key string, def int) int {
+	if v := os.Getenv(key); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			return n
+		}
+	}
+	return def
+}
+
+func getEnvBool(key string, def bool) bool {
+	if v := os.Getenv(key); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return def
+}
+
+func getEnvList(key string, def []string) []string {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	var out []string
+	for _, p := range strings.Split(v, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
@@ -1,6 +1,7 @@
 package infra
 
 import (
+	"backend/internal/config"
 	"log"
 	"sync"
 
@@ -13,10 +14,7 @@ var once sync.Once
 
 func GetDB() *gorm.DB {
 	once.Do(func() {
-		// dsn := os.Getenv("DATABASE_URL")
-		// if dsn == "" {
-		dsn := "host=localhost user=postgres password=postgres dbname=emailverifier1 port=5432 sslmode=disable"
-		// }
+		dsn := config.Load().DatabaseURL
 		var err error
 		db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
 
@@ -4,14 +4,65 @@ import (
 	"time"
 )
 
+// Statuts possibles d'un job bulk
+const (
+	JobStatusQueued     = "queued"
+	JobStatusProcessing = "processing"
+	JobStatusDone       = "done"
+	JobStatusFailed     = "failed"
+	JobStatusPaused     = "paused"
+	JobStatusCancelled  = "cancelled"
+)
+
+// Niveaux de priorité d'un job: les jobs de priorité supérieure sont servis d'abord,
+// les jobs de même priorité se partagent les workers
+const (
+	JobPriorityLow    = -1
+	JobPriorityNormal = 0
+	JobPriorityHigh   = 1
+	JobPriorityUrgent = 2
+)
+
+// Modes d'un job d'extraction: page d'accueil seule, ou crawl du site (emails, téléphones, réseaux sociaux)
+const (
+	ExtractModeFast = "fast"
+	ExtractModeDeep = "deep"
+)
+
 // / Modèle pour l'historique des jobs bulk
 // (à migrer avec GORM)
 type BulkJob struct {
-	ID         string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
-	FileName   string    `json:"fileName"`
-	UploadedAt time.Time `json:"uploadedAt"`
-	// Status          string    `json:"status"`
-	TotalEmails int `json:"totalEmails"`
-	// ProcessedEmails int `json:"processedEmails"`
+	ID              string     `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	Kind            string     `gorm:"default:verify" json:"kind"`
+	ParentJobID     *string    `gorm:"index;type:uuid" json:"parentJobId,omitempty"` // job re-vérifié
+	ScheduleID      *string    `gorm:"index;type:uuid" json:"scheduleId,omitempty"`  // re-vérification planifiée
+	ListID          *string    `gorm:"index;type:uuid" json:"listId,omitempty"`      // liste vérifiée
+	ExtractMode     string     `json:"extractMode,omitempty"`                        // job d'extraction: fast ou deep
+	IgnoreRobots    bool       `gorm:"default:false" json:"ignoreRobots,omitempty"`  // job d'extraction: robots.txt ignoré
+	FileName        string     `json:"fileName"`                                     // fichier uploadé, jamais modifié
+	DisplayName     string     `json:"displayName,omitempty"`                        // nom donné par l'utilisateur
+	Tags            string     `gorm:"type:jsonb;default:'[]'" json:"-"`             // étiquettes (JSON)
+	Headers         string     `gorm:"type:text" json:"-"`                           // en-tête CSV d'origine (JSON)
+	UploadedAt      time.Time  `json:"uploadedAt"`
+	Status          string     `gorm:"index" json:"status"`
+	Priority        int        `gorm:"not null;default:0" json:"priority"`
+	Owner           string     `gorm:"index" json:"owner,omitempty"` // utilisateur ou équipe (partage équitable)
+	TotalEmails     int        `json:"totalEmails"`                  // sites pour un job d'extraction
+	ProcessedEmails int        `json:"processedEmails"`
+	ErrorCount      int        `json:"errorCount"`
+	LeasedItems     int        `gorm:"not null;default:0" json:"-"`    // éléments réservés par les workers (ordonnancement)
+	Ingesting       bool       `gorm:"default:false" json:"ingesting"` // lecture du fichier en cours
+	SpoolPath       string     `json:"-"`                              // fichier uploadé sur disque
+	SourceColumn    int        `json:"-"`                              // index de la colonne email/site
+	SourceFormat    string     `json:"sourceFormat,omitempty"`         // csv, xlsx, json, ndjson, txt
+	SourceSheet     string     `json:"sourceSheet,omitempty"`          // feuille lue pour un XLSX
+	IngestedRows    int        `json:"ingestedRows"`                   // lignes de données déjà lues
+	SkipDuplicates  bool       `gorm:"default:false" json:"skipDuplicates"`
+	Preflight       string     `gorm:"type:text" json:"-"` // rapport d'analyse avant vérification (JSON)
+	ErrorMessage    string     `json:"errorMessage,omitempty"`
+	WebhookURL      string     `json:"webhookUrl,omitempty"` // notifié à la fin du job
+	WebhookSecret   string     `json:"-"`
+	StartedAt       *time.Time `json:"startedAt,omitempty"`
+	FinishedAt      *time.Time `json:"finishedAt,omitempty"`
 	// ResultsJSON datatypes.JSON `gorm:"type:jsonb"` // Optional
 }
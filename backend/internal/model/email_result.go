@@ -5,12 +5,20 @@ import (
 )
 
 type EmailResult struct {
-	ID         string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
-	JobID      string    `gorm:"index;type:uuid" json:"jobId"`
-	Email      string    `json:"email"`
-	IsValid    bool      `json:"isValid"`
-	Reason     string    `json:"reason"`
-	BounceType *string   `json:"bounceType,omitempty"`
-	CheckedAt  time.Time `json:"checkedAt"`
-	Status     string    `json:"status"`
+	ID           string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	JobID        string    `gorm:"index;index:idx_email_results_job_row,priority:1;type:uuid" json:"jobId"`
+	RowIndex     int       `gorm:"index:idx_email_results_job_row,priority:2" json:"rowIndex"`
+	SourceRow    string    `gorm:"type:text" json:"-"` // colonnes d'origine (JSON)
+	Email        string    `json:"email"`
+	IsValid      bool      `json:"isValid"`
+	Reason       string    `json:"reason"`
+	BounceType   *string   `json:"bounceType,omitempty"`
+	CheckedAt    time.Time `json:"checkedAt"`
+	Status       string    `json:"status"`
+	Score        int       `json:"score"`
+	IsRoleBased  bool      `json:"isRoleBased"`
+	IsDisposable bool      `json:"isDisposable"`
+	IsCatchAll   bool      `json:"isCatchAll"`
+	IsFree       bool      `json:"isFree"`
+	Suggestion   string    `json:"suggestion,omitempty"`
 }
@@ -0,0 +1,62 @@
+package model
+
+import (
+	"time"
+)
+
+// Email trouvé sur un site lors d'un job d'extraction bulk
+type ExtractResult struct {
+	ID         string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	JobID      string    `gorm:"index;type:uuid" json:"jobId"`
+	RowIndex   int       `json:"rowIndex"`
+	Site       string    `json:"site"`
+	Email      string    `json:"email"`
+	Domain     string    `json:"domain"`
+	Suppressed bool      `gorm:"default:false" json:"suppressed"` // adresse de la liste de suppression
+	SourceURL  string    `json:"sourceUrl,omitempty"`             // page où l'email a été trouvé
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// Types des contacts autres que les emails (extraction approfondie)
+const (
+	ContactPhone  = "phone"
+	ContactSocial = "social"
+)
+
+// Téléphone ou profil social trouvé sur un site lors d'une extraction approfondie
+type ExtractContact struct {
+	ID        string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	JobID     string    `gorm:"index;type:uuid" json:"jobId"`
+	RowIndex  int       `json:"rowIndex"`
+	Site      string    `json:"site"`
+	Kind      string    `json:"kind"`                // phone ou social
+	Value     string    `json:"value"`               // téléphone au format E.164
+	Country   string    `json:"country,omitempty"`   // téléphone: pays du numéro
+	PhoneType string    `json:"phoneType,omitempty"` // mobile, landline, toll_free ou unknown
+	SourceURL string    `json:"sourceUrl,omitempty"` // page où le contact a été trouvé
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Page d'un site non visitée car interdite par robots.txt
+type RobotsSkip struct {
+	ID        string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	JobID     string    `gorm:"index;type:uuid" json:"jobId"`
+	RowIndex  int       `json:"rowIndex"`
+	Site      string    `json:"site"`
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Organisation ou personne décrite par les données structurées d'un site (extraction approfondie)
+type ExtractOrganization struct {
+	ID        string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	JobID     string    `gorm:"index;type:uuid" json:"jobId"`
+	RowIndex  int       `json:"rowIndex"`
+	Site      string    `json:"site"`
+	Type      string    `json:"type"` // type schema.org: Organization, LocalBusiness, Person...
+	Name      string    `json:"name"`
+	Format    string    `json:"format"`              // jsonld, microdata, hcard ou vcard
+	Data      string    `gorm:"type:jsonb" json:"-"` // fiche complète: emails, téléphones, adresses, sameAs (JSON)
+	SourceURL string    `json:"sourceUrl,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
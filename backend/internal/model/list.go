@@ -0,0 +1,44 @@
+package model
+
+import "time"
+
+// Origine d'une liste
+const (
+	ListSourceManual   = "manual"
+	ListSourceUpload   = "upload"
+	ListSourceJob      = "job" // résultats d'un job de vérification ou d'extraction
+	ListSourceMerge    = "merge"
+	ListSourceSubtract = "subtract"
+	ListSourceSegment  = "segment"
+)
+
+// List est une liste de contacts nommée, réutilisable entre vérifications
+type List struct {
+	ID          string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Source      string    `json:"source"`                                 // manual, upload, job, merge, subtract, segment
+	SourceJobID *string   `gorm:"type:uuid" json:"sourceJobId,omitempty"` // job d'origine (source=job)
+	Owner       string    `gorm:"index" json:"owner,omitempty"`           // utilisateur ou équipe
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// ListMember est une adresse d'une liste avec son dernier résultat de vérification
+type ListMember struct {
+	ID             int64      `gorm:"primaryKey;autoIncrement" json:"id"`
+	ListID         string     `gorm:"type:uuid;uniqueIndex:idx_list_members_email,priority:1;index:idx_list_members_canonical,priority:1" json:"listId"`
+	Email          string     `gorm:"uniqueIndex:idx_list_members_email,priority:2" json:"email"` // en minuscules
+	Canonical      string     `gorm:"index:idx_list_members_canonical,priority:2" json:"-"`       // même boîte (+tag, points Gmail)
+	Domain         string     `gorm:"index" json:"domain"`
+	Status         string     `json:"status"` // vide tant que l'adresse n'a pas été vérifiée
+	Reason         string     `json:"reason,omitempty"`
+	Score          int        `json:"score"`
+	IsRoleBased    bool       `gorm:"default:false" json:"isRoleBased"`
+	IsDisposable   bool       `gorm:"default:false" json:"isDisposable"`
+	IsCatchAll     bool       `gorm:"default:false" json:"isCatchAll"`
+	IsFree         bool       `gorm:"default:false" json:"isFree"`
+	LastVerifiedAt *time.Time `json:"lastVerifiedAt,omitempty"`
+	LastJobID      *string    `gorm:"type:uuid" json:"lastJobId,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+// Schedule re-vérifie périodiquement les résultats d'un job ou une liste
+type Schedule struct {
+	ID            string     `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	JobID         *string    `gorm:"index;type:uuid" json:"jobId,omitempty"`  // job dont les résultats sont mis à jour
+	ListID        *string    `gorm:"index;type:uuid" json:"listId,omitempty"` // ou liste re-vérifiée
+	Cron          string     `json:"cron"`
+	Timezone      string     `json:"timezone"`                         // fuseau IANA de l'expression cron
+	Statuses      string     `gorm:"type:jsonb;default:'[]'" json:"-"` // statuts re-vérifiés (JSON)
+	Active        bool       `gorm:"default:false" json:"active"`
+	NextRunAt     *time.Time `gorm:"index" json:"nextRunAt,omitempty"`
+	LastRunAt     *time.Time `json:"lastRunAt,omitempty"`
+	LastRunJobID  *string    `gorm:"type:uuid" json:"lastRunJobId,omitempty"` // dernier job de re-vérification
+	LastError     string     `json:"lastError,omitempty"`                     // raison du dernier lancement manqué
+	WebhookURL    string     `json:"webhookUrl,omitempty"`                    // reçoit le rapport de changements
+	WebhookSecret string     `json:"-"`
+	NotifyEmail   string     `json:"notifyEmail,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+}
+
+// StatusChange est un email dont le statut a changé lors d'une re-vérification
+type StatusChange struct {
+	ID            int64     `gorm:"primaryKey;autoIncrement" json:"-"`
+	JobID         string    `gorm:"index;type:uuid" json:"jobId"` // job de re-vérification
+	ParentJobID   *string   `gorm:"type:uuid" json:"parentJobId,omitempty"`
+	ListID        *string   `gorm:"type:uuid" json:"listId,omitempty"`
+	RowIndex      int       `json:"rowIndex"`
+	Email         string    `json:"email"`
+	FromStatus    string    `json:"fromStatus"`
+	ToStatus      string    `json:"toStatus"`
+	NewlyCatchAll bool      `gorm:"default:false" json:"newlyCatchAll"` // domaine devenu catch-all
+	CreatedAt     time.Time `json:"createdAt"`
+}
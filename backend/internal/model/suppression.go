@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// Types d'entrée de la liste de suppression
+const (
+	SuppressionEmail     = "email"     // adresse exacte
+	SuppressionCanonical = "canonical" // même boîte (+tag, points Gmail)
+	SuppressionDomain    = "domain"    // domaine et ses sous-domaines
+	SuppressionPattern   = "pattern"   // motif avec * et ? (ex. *@*.gov, info@*)
+)
+
+// Suppression est une entrée de la liste de suppression du compte: les adresses
+// correspondantes ne sont jamais vérifiées (aucune connexion SMTP)
+type Suppression struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	Kind      string    `gorm:"uniqueIndex:idx_suppressions_value,priority:1" json:"kind"`
+	Value     string    `gorm:"uniqueIndex:idx_suppressions_value,priority:2" json:"value"` // en minuscules
+	Reason    string    `json:"reason,omitempty"`                                           // désinscription, plainte, bounce...
+	Source    string    `json:"source,omitempty"`                                           // manual, import, api...
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// Upload est un fichier spoolé par /api/uploads/preview, en attente d'être
+// utilisé par un job (uploadId) au lieu d'être renvoyé
+type Upload struct {
+	ID        string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	FileName  string    `json:"fileName"`
+	SpoolPath string    `json:"-"`
+	Size      int64     `json:"size"`
+	Format    string    `json:"format"`
+	Sheet     string    `json:"sheet,omitempty"`
+	CreatedAt time.Time `gorm:"index" json:"createdAt"`
+}
@@ -0,0 +1,47 @@
+package model
+
+import "time"
+
+// Événements envoyés aux webhooks
+const (
+	WebhookEventJobFinished  = "job.finished"
+	WebhookEventJobFailed    = "job.failed"
+	WebhookEventJobCancelled = "job.cancelled"
+	// Rapport de changements d'une re-vérification planifiée
+	WebhookEventScheduleDiff = "schedule.diff"
+)
+
+// Statuts d'une livraison de webhook
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed" // abandonnée après le dernier essai
+)
+
+// Webhook du compte: notifié pour tous les jobs
+type Webhook struct {
+	ID        string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"-"`
+	Events    string    `gorm:"type:jsonb;default:'[]'" json:"-"` // événements souscrits (JSON)
+	Active    bool      `gorm:"default:true" json:"active"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// WebhookDelivery est un envoi d'événement, réessayé jusqu'à réussite ou abandon
+type WebhookDelivery struct {
+	ID             int64      `gorm:"primaryKey;autoIncrement" json:"id"`
+	WebhookID      *string    `gorm:"index;type:uuid" json:"webhookId,omitempty"` // nil: webhook du job
+	JobID          string     `gorm:"index;type:uuid" json:"jobId"`
+	URL            string     `json:"url"`
+	Secret         string     `json:"-"` // secret au moment de l'événement
+	Event          string     `json:"event"`
+	Payload        string     `gorm:"type:text" json:"payload"`
+	Status         string     `gorm:"index:idx_webhook_deliveries_due,priority:1" json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  time.Time  `gorm:"index:idx_webhook_deliveries_due,priority:2" json:"nextAttemptAt"`
+	LastStatusCode int        `json:"lastStatusCode,omitempty"`
+	LastError      string     `json:"lastError,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	DeliveredAt    *time.Time `json:"deliveredAt,omitempty"`
+}
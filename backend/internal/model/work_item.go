@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+)
+
+// Types de travail placés dans la file
+const (
+	WorkKindVerify  = "verify"
+	WorkKindExtract = "extract"
+)
+
+// Statuts d'un élément de la file
+const (
+	WorkItemPending   = "pending"
+	WorkItemLeased    = "leased"
+	WorkItemDone      = "done"
+	WorkItemCancelled = "cancelled"
+)
+
+// Élément de travail unitaire (un email à vérifier ou un site à extraire) de la file persistante.
+// Les workers les réservent avec SELECT ... FOR UPDATE SKIP LOCKED.
+type WorkItem struct {
+	ID         int64      `gorm:"primaryKey;autoIncrement" json:"id"`
+	JobID      string     `gorm:"type:uuid;index:idx_work_items_job_status,priority:1" json:"jobId"`
+	Kind       string     `gorm:"index:idx_work_items_claim,priority:2" json:"kind"`
+	RowIndex   int        `json:"rowIndex"`
+	Payload    string     `json:"payload"`
+	SourceRow  string     `gorm:"type:text" json:"-"` // colonnes d'origine (JSON)
+	Status     string     `gorm:"index:idx_work_items_claim,priority:1;index:idx_work_items_job_status,priority:2" json:"status"`
+	Attempts   int        `json:"attempts"`
+	LeasedBy   string     `json:"leasedBy,omitempty"`
+	LeaseUntil *time.Time `json:"leaseUntil,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+}
@@ -0,0 +1,16 @@
+package model
+
+import (
+	"time"
+)
+
+// Processus worker enregistré auprès de la file (serveur API ou cmd/worker)
+type Worker struct {
+	ID            string    `gorm:"primaryKey" json:"id"`
+	Hostname      string    `json:"hostname"`
+	PID           int       `json:"pid"`
+	Kinds         string    `json:"kinds"`
+	Concurrency   int       `json:"concurrency"`
+	StartedAt     time.Time `json:"startedAt"`
+	LastHeartbeat time.Time `gorm:"index" json:"lastHeartbeat"`
+}
@@ -3,15 +3,120 @@ package service
 import (
 	"backend/internal/infra"
 	"backend/internal/model"
+	"context"
+	"sort"
+	"time"
 )
 
-func GetResultsForDownload(jobId, typ string) []model.EmailResult {
+// Statuts téléchargés séparément (feuilles XLSX, fichiers du ZIP); les quatre
+// premiers sont toujours présents, les autres seulement s'ils ont des lignes
+var downloadStatuses = []string{string(StatusValid), string(StatusInvalid), string(StatusAcceptAll), statusUnknown, string(StatusDuplicate), string(StatusSuppressed)}
+
+// DownloadStatuses retourne les statuts d'un job à télécharger séparément, ou
+// ceux du filtre s'il en donne
+func DownloadStatuses(jobId string, filter ResultFilter) ([]string, error) {
+	if len(filter.Statuses) > 0 {
+		return filter.Statuses, nil
+	}
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	err := infra.GetDB().Model(&model.EmailResult{}).Select("status, COUNT(*) AS count").
+		Where("job_id = ?", jobId).Where(resultNotBlank).Group("status").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	present := map[string]bool{}
+	for _, r := range rows {
+		present[resultStatus(r.Status)] = true
+	}
+	var statuses []string
+	for i, s := range downloadStatuses {
+		if i < 4 || present[s] {
+			statuses = append(statuses, s)
+		}
+		delete(present, s)
+	}
+	// Statuts hors de la liste (anciennes versions): à la fin, dans l'ordre alphabétique
+	others := make([]string, 0, len(present))
+	for s := range present {
+		others = append(others, s)
+	}
+	sort.Strings(others)
+	return append(statuses, others...), nil
+}
+
+// StreamJobResults parcourt les résultats d'un job correspondant au filtre, dans
+// son ordre de tri, avec un curseur: les résultats ne sont jamais tous chargés en mémoire
+func StreamJobResults(jobId string, filter ResultFilter, fn func(model.EmailResult) error) error {
 	db := infra.GetDB()
-	var results []model.EmailResult
-	q := db.Where("job_id = ?", jobId)
-	if typ != "" && typ != "all" {
-		q = q.Where("status = ?", typ)
+	q := filter.apply(db.Model(&model.EmailResult{}).Where("job_id = ?", jobId))
+	rows, err := filter.order(q).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var r model.EmailResult
+		if err := db.ScanRows(rows, &r); err != nil {
+			return err
+		}
+		if err := fn(r); err != nil {
+			return err
+		}
 	}
-	q.Order("checked_at ASC").Find(&results)
+	return rows.Err()
+}
+
+// GetExtractResults retourne les emails extraits d'un job dans l'ordre du fichier
+func GetExtractResults(jobId string) []model.ExtractResult {
+	db := infra.GetDB()
+	var results []model.ExtractResult
+	db.Where("job_id = ?", jobId).Order("row_index ASC, email ASC").Find(&results)
 	return results
 }
+
+// GetExtractContacts retourne les téléphones et profils sociaux trouvés par un job d'extraction approfondie
+func GetExtractContacts(jobId string) []model.ExtractContact {
+	db := infra.GetDB()
+	var contacts []model.ExtractContact
+	db.Where("job_id = ?", jobId).Order("row_index ASC, kind ASC, value ASC").Find(&contacts)
+	return contacts
+}
+
+// GetExtractOrganizations retourne les organisations trouvées par un job d'extraction approfondie
+func GetExtractOrganizations(jobId string) []model.ExtractOrganization {
+	db := infra.GetDB()
+	var orgs []model.ExtractOrganization
+	db.Where("job_id = ?", jobId).Order("row_index ASC, created_at ASC").Find(&orgs)
+	return orgs
+}
+
+// GetRobotsSkips retourne les pages interdites par robots.txt et non visitées par un job d'extraction
+func GetRobotsSkips(jobId string) []model.RobotsSkip {
+	db := infra.GetDB()
+	var skips []model.RobotsSkip
+	db.Where("job_id = ?", jobId).Order("row_index ASC, url ASC").Find(&skips)
+	return skips
+}
+
+// WaitForBulkJob attend la fin d'un job (done, failed ou cancelled) ou l'annulation du contexte
+func WaitForBulkJob(ctx context.Context, jobId string) (model.BulkJob, error) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		job, err := GetBulkJobByID(jobId)
+		if err != nil {
+			return job, err
+		}
+		if !IsJobActive(job.Status) {
+			return job, nil
+		}
+		select {
+		case <-ctx.Done():
+			return job, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package service
+
+import (
+	"container/heap"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// Mots-clés des liens vers les pages où se trouvent les adresses de contact
+// (FR/EN/AR/ES/DE), comparés au chemin et au texte du lien normalisés: minuscules,
+// séparateurs (- _ / .) remplacés par des espaces. Un mot-clé doit former des mots
+// entiers ("team" ne correspond pas à "steam"), au pluriel près ("contacts").
+var crawlKeywords = []struct {
+	score    int
+	keywords []string
+}{
+	{100, []string{"contact", "contactus", "kontakt", "nous contacter", "contactez", "contacto", "contactanos",
+		"contáctanos", "اتصل", "تواصل", "اتصال"}},
+	{80, []string{"impressum", "imprint", "mentions legales", "mentions légales", "aviso legal",
+		"legal notice", "informations legales", "informations légales", "قانونية"}},
+	{60, []string{"about", "aboutus", "a propos", "à propos", "qui sommes nous", "ueber uns", "über uns",
+		"uber uns", "sobre nosotros", "quienes somos", "quiénes somos", "من نحن", "عن الشركة"}},
+	{40, []string{"team", "equipe", "équipe", "equipo", "unser team", "فريق", "staff"}},
+	{20, []string{"support", "soporte", "hilfe", "help", "الدعم"}},
+}
+
+// Liens à visiter en dernier: comptes, panier, recherche, archives de blog
+var crawlPenalties = []string{
+	"login", "signin", "sign in", "connexion", "anmelden", "logout", "register", "inscription",
+	"account", "compte", "cart", "panier", "warenkorb", "carrito", "checkout", "wp admin",
+	"search", "recherche", "tag", "category", "feed", "blog", "news", "actualites",
+}
+
+// Une page moins profonde est préférée à score égal
+const (
+	crawlPenalty      = 50
+	crawlDepthPenalty = 5
+	crawlVCardScore   = 90 // fiche contact .vcf liée depuis le site
+)
+
+// scoreLink note un lien d'après son chemin, son texte et son titre
+func scoreLink(u *url.URL, text string) int {
+	p, _ := url.PathUnescape(u.EscapedPath())
+	words := " " + crawlWords(p+" "+u.RawQuery+" "+text) + " "
+	score := 0
+	for _, group := range crawlKeywords {
+		if group.score <= score {
+			continue
+		}
+		for _, kw := range group.keywords {
+			if strings.Contains(words, " "+kw+" ") || strings.Contains(words, " "+kw+"s ") {
+				score = group.score
+				break
+			}
+		}
+	}
+	for _, kw := range crawlPenalties {
+		if strings.Contains(words, " "+kw+" ") {
+			score -= crawlPenalty
+			break
+		}
+	}
+	return score
+}
+
+func crawlWords(s string) string {
+	s = strings.ToLower(s)
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case '-', '_', '/', '.', '?', '&', '=', '\t', '\n', '\r':
+			return ' '
+		}
+		return r
+	}, s)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// crawlLink est une page de la frontière du crawl
+type crawlLink struct {
+	url   string
+	score int
+	depth int
+	seq   int // ordre de découverte, départage les égalités
+}
+
+// crawlFrontier est une file de priorité: meilleur score, puis moins profond, puis découvert en premier
+type crawlFrontier []crawlLink
+
+func (f crawlFrontier) Len() int { return len(f) }
+func (f crawlFrontier) Less(i, j int) bool {
+	if f[i].score != f[j].score {
+		return f[i].score > f[j].score
+	}
+	if f[i].depth != f[j].depth {
+		return f[i].depth < f[j].depth
+	}
+	return f[i].seq < f[j].seq
+}
+func (f crawlFrontier) Swap(i, j int) { f[i], f[j] = f[j], f[i] }
+func (f *crawlFrontier) Push(x any)   { *f = append(*f, x.(crawlLink)) }
+func (f *crawlFrontier) Pop() any {
+	old := *f
+	link := old[len(old)-1]
+	*f = old[:len(old)-1]
+	return link
+}
+
+// crawlOptions borne un crawl
+type crawlOptions struct {
+	maxPages     int           // pages visitées au plus, page de départ comprise
+	maxDepth     int           // profondeur des liens suivis depuis la page de départ
+	onlyScored   bool          // ne suivre que les liens de score positif (pages de contact)
+	timeout      time.Duration // par requête
+	delay        time.Duration // délai aléatoire entre deux pages
+	ignoreRobots bool          // site dont on est propriétaire: Disallow et Crawl-delay ignorés
+	sitemaps     bool          // chercher les pages de contact dans les sitemaps (dans le budget de pages)
+}
+
+// crawlSite visite la page de départ puis les liens du même site par ordre de
+// priorité (pages de contact d'abord) jusqu'à épuiser le budget de pages. Avec
+// opts.sitemaps, les pages de contact listées par les sitemaps entrent dans la
+// frontière comme les liens de la page de départ; chaque fichier sitemap lu compte
+// comme une page du budget. onPage reçoit chaque page HTML; les pages sont
+// visitées l'une après l'autre. Si onVCard n'est pas nil, les fichiers vCard liés
+// sont aussi téléchargés (dans le budget) et lui sont passés. Retourne les pages
+// interdites par robots.txt.
+func crawlSite(startURL string, opts crawlOptions, onPage func(e *colly.HTMLElement), onVCard func(r *colly.Response)) ([]string, error) {
+	start, err := url.Parse(startURL)
+	if err != nil {
+		return nil, err
+	}
+	host := strings.ToLower(getDomain(startURL))
+	c := colly.NewCollector(colly.AllowedDomains(host))
+	c.SetRequestTimeout(opts.timeout)
+
+	frontier := &crawlFrontier{}
+	seen := map[string]bool{normalizeURL(start.String()): true}
+	seq, depth, visited := 0, 0, 0
+	push := func(link *url.URL, score, depth int) {
+		seen[normalizeURL(link.String())] = true
+		seq++
+		heap.Push(frontier, crawlLink{url: link.String(), score: score, depth: depth, seq: seq})
+	}
+
+	// robots.txt est lu hors budget, avec ignoreRobots seulement pour ses lignes
+	// Sitemap; les sitemaps laissent au moins la page de départ
+	var policy robotsPolicy
+	client := &http.Client{Timeout: opts.timeout}
+	if !opts.ignoreRobots || opts.sitemaps {
+		policy = fetchRobots(client, start, c.UserAgent)
+	}
+	if files := min(maxSitemaps, opts.maxPages-1); opts.sitemaps && files > 0 {
+		locs, read := sitemapURLs(client, start, policy, c.UserAgent, files)
+		visited += read
+		for _, loc := range locs {
+			link, ok := crawlTarget(loc, host)
+			if !ok || seen[normalizeURL(link.String())] {
+				continue
+			}
+			if score := scoreLink(link, "") - crawlDepthPenalty; score > 0 {
+				push(link, score, 1)
+			}
+		}
+	}
+	// Règles Disallow et Crawl-delay ignorées pour un site dont on est propriétaire
+	if opts.ignoreRobots {
+		policy = robotsPolicy{}
+	}
+	// Crawl-delay du site, plus le délai aléatoire du mode
+	if delay := policy.crawlDelay(); delay > 0 || opts.delay > 0 {
+		c.Limit(&colly.LimitRule{DomainGlob: "*", Delay: delay, RandomDelay: opts.delay})
+	}
+
+	c.OnRequest(func(r *colly.Request) { visited++ })
+	c.OnHTML("html", onPage)
+	if onVCard != nil {
+		c.OnResponse(func(r *colly.Response) {
+			if isVCardLink(r.Request.URL) || strings.Contains(strings.ToLower(r.Headers.Get("Content-Type")), "vcard") {
+				onVCard(r)
+			}
+		})
+	}
+	c.OnHTML("a[href]", func(e *colly.HTMLElement) {
+		if depth >= opts.maxDepth {
+			return
+		}
+		link, ok := crawlTarget(e.Request.AbsoluteURL(e.Attr("href")), host)
+		if !ok || seen[normalizeURL(link.String())] {
+			return
+		}
+		score := scoreLink(link, e.Text+" "+e.Attr("title")) - (depth+1)*crawlDepthPenalty
+		if isVCardLink(link) {
+			if onVCard == nil {
+				return
+			}
+			score = crawlVCardScore - (depth+1)*crawlDepthPenalty
+		}
+		if opts.onlyScored && score <= 0 {
+			return
+		}
+		push(link, score, depth+1)
+	})
+
+	// La page de départ doit répondre, les autres pages sont facultatives
+	if !policy.allowed(start) {
+		return []string{startURL}, nil
+	}
+	if err := c.Visit(startURL); err != nil {
+		return nil, err
+	}
+	var skipped []string
+	for frontier.Len() > 0 && visited < opts.maxPages {
+		link := heap.Pop(frontier).(crawlLink)
+		if u, err := url.Parse(link.url); err != nil || !policy.allowed(u) {
+			skipped = append(skipped, link.url)
+			continue
+		}
+		depth = link.depth
+		c.Visit(link.url)
+	}
+	return skipped, nil
+}
+
+// crawlTarget retourne un lien à suivre: http(s), même hôte (avec ou sans www.),
+// sans fragment ni extension de ressource statique
+func crawlTarget(raw, host string) (*url.URL, bool) {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, false
+	}
+	if strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.") != strings.TrimPrefix(host, "www.") {
+		return nil, false
+	}
+	// AllowedDomains compare le nom d'hôte exact
+	if !strings.EqualFold(u.Hostname(), host) {
+		u.Host = strings.Replace(u.Host, u.Hostname(), host, 1)
+	}
+	ext := strings.ToLower(path.Ext(u.Path))
+	for _, forbidden := range forbiddenExt {
+		if ext == forbidden {
+			return nil, false
+		}
+	}
+	u.Fragment = ""
+	return u, true
+}
+
+func isVCardLink(u *url.URL) bool {
+	ext := strings.ToLower(path.Ext(u.Path))
+	return ext == ".vcf" || ext == ".vcard"
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"container/heap"
+	"net/url"
+	"testing"
+)
+
+func TestScoreLink(t *testing.T) {
+	tests := []struct {
+		link string
+		text string
+		want int
+	}{
+		{"https://x.com/contact", "", 100},
+		{"https://x.com/contact-us", "", 100},
+		{"https://x.com/contacts", "", 100},
+		{"https://x.com/fr/nous-contacter.html", "", 100},
+		{"https://x.com/page?id=3", "Contactez-nous", 100},
+		{"https://x.com/de/kontakt", "", 100},
+		{"https://x.com/contactus.aspx", "", 100},
+		{"https://x.com/es/contacto", "", 100},
+		{"https://x.com/p", "Contáctanos", 100},
+		{"https://x.com/%D8%A7%D8%AA%D8%B5%D9%84-%D8%A8%D9%86%D8%A7", "", 100}, // اتصل بنا
+		{"https://x.com/impressum", "", 80},
+		{"https://x.com/mentions-legales", "", 80},
+		{"https://x.com/p", "Mentions légales", 80},
+		{"https://x.com/about", "", 60},
+		{"https://x.com/a-propos", "", 60},
+		{"https://x.com/aboutus", "", 60},
+		{"https://x.com/qui-sommes-nous", "", 60},
+		{"https://x.com/our-team", "", 40},
+		{"https://x.com/teams", "", 40},
+		{"https://x.com/help", "", 20},
+		// Le meilleur groupe l'emporte
+		{"https://x.com/about/contact", "", 100},
+		{"https://x.com/team", "Contact", 100},
+		// Mots qui contiennent un mot-clé sans en être un
+		{"https://x.com/steam-cleaning", "", 0},
+		{"https://x.com/roundabout", "", 0},
+		{"https://x.com/contactless-payment", "", 0},
+		{"https://x.com/helpers", "", 0},
+		{"https://x.com/products", "Steamboat", 0},
+		{"https://x.com/", "", 0},
+		// Pénalités: comptes, panier, recherche, blog
+		{"https://x.com/login", "", -crawlPenalty},
+		{"https://x.com/blog/contact-tips", "", 100 - crawlPenalty},
+		{"https://x.com/search?q=contact", "", 100 - crawlPenalty},
+		{"https://x.com/account", "Help", 20 - crawlPenalty},
+		{"https://x.com/tags", "", 0},
+		{"https://x.com/catalog", "", 0},
+	}
+	for _, tt := range tests {
+		u, err := url.Parse(tt.link)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := scoreLink(u, tt.text); got != tt.want {
+			t.Errorf("scoreLink(%s, %q) = %d, want %d", tt.link, tt.text, got, tt.want)
+		}
+	}
+}
+
+// La frontière sert le meilleur score, puis la page la moins profonde, puis la
+// première découverte
+func TestCrawlFrontierOrder(t *testing.T) {
+	links := []crawlLink{
+		{url: "a", score: 40, depth: 1, seq: 1},
+		{url: "b", score: 100, depth: 2, seq: 2},
+		{url: "c", score: 100, depth: 1, seq: 3},
+		{url: "d", score: 0, depth: 1, seq: 4},
+		{url: "e", score: 100, depth: 1, seq: 5},
+		{url: "f", score: -50, depth: 1, seq: 6},
+		{url: "g", score: 40, depth: 1, seq: 7},
+		{url: "h", score: 100, depth: 2, seq: 8},
+	}
+	frontier := &crawlFrontier{}
+	for _, l := range links {
+		heap.Push(frontier, l)
+	}
+	var got string
+	for frontier.Len() > 0 {
+		got += heap.Pop(frontier).(crawlLink).url
+	}
+	if want := "cebhagdf"; got != want {
+		t.Errorf("order = %s, want %s", got, want)
+	}
+}
@@ -20,17 +20,18 @@ func SaveResultToDB(res EmailValidationResult) error {
 	return db.Create(&m).Error
 }
 
-// Récupère les résultats d'un job avec pagination et les stats globales
-func GetJobResultsPaginated(jobId string, page, pageSize int) ([]EmailValidationResult, int64, int64, int64, int64, error) {
+// Récupère une page des résultats d'un job correspondant au filtre (total: lignes
+// du filtre) et les stats globales du job
+func GetJobResultsPaginated(jobId string, filter ResultFilter, page, pageSize int) ([]EmailValidationResult, int64, int64, int64, int64, error) {
 	db := infra.GetDB()
 	var results []model.EmailResult
 	var total, valid, invalid, acceptAll int64
-	db.Model(&model.EmailResult{}).Where("job_id = ?", jobId).Count(&total)
+	q := filter.apply(db.Model(&model.EmailResult{}).Where("job_id = ?", jobId).Where(resultNotBlank))
+	q.Count(&total)
 	db.Model(&model.EmailResult{}).Where("job_id = ? AND status = ?", jobId, "valid").Count(&valid)
 	db.Model(&model.EmailResult{}).Where("job_id = ? AND status = ?", jobId, "invalid").Count(&invalid)
 	db.Model(&model.EmailResult{}).Where("job_id = ? AND status = ?", jobId, "accept_all").Count(&acceptAll)
-	err := db.Where("job_id = ?", jobId).
-		Order("checked_at ASC").
+	err := filter.order(q).
 		Limit(pageSize).
 		Offset((page - 1) * pageSize).
 		Find(&results).Error
@@ -40,13 +41,20 @@ func GetJobResultsPaginated(jobId string, page, pageSize int) ([]EmailValidation
 	converted := make([]EmailValidationResult, len(results))
 	for i, r := range results {
 		converted[i] = EmailValidationResult{
-			Email:      r.Email,
-			IsValid:    r.IsValid,
-			Reason:     r.Reason,
-			BounceType: r.BounceType,
-			CheckedAt:  r.CheckedAt,
-			JobID:      r.JobID,
-			Status:     EmailStatus(r.Status),
+			Email:        r.Email,
+			IsValid:      r.IsValid,
+			IsCatchAll:   r.IsCatchAll,
+			IsRoleBased:  r.IsRoleBased,
+			IsDisposable: r.IsDisposable,
+			Reason:       r.Reason,
+			BounceType:   r.BounceType,
+			CheckedAt:    r.CheckedAt,
+			JobID:        r.JobID,
+			RowIndex:     r.RowIndex,
+			Status:       EmailStatus(r.Status),
+			IsFree:       r.IsFree,
+			Score:        r.Score,
+			Suggestion:   r.Suggestion,
 		}
 	}
 	return converted, total, valid, invalid, acceptAll, nil
@@ -58,13 +66,20 @@ func BatchSaveResults(results []EmailValidationResult) error {
 	var toSave []model.EmailResult
 	for _, r := range results {
 		toSave = append(toSave, model.EmailResult{
-			JobID:      r.JobID,
-			Email:      r.Email,
-			IsValid:    r.IsValid,
-			Status:     string(r.Status),
-			Reason:     r.Reason,
-			BounceType: r.BounceType,
-			CheckedAt:  r.CheckedAt,
+			JobID:        r.JobID,
+			RowIndex:     r.RowIndex,
+			Email:        r.Email,
+			IsValid:      r.IsValid,
+			Status:       string(r.Status),
+			Reason:       r.Reason,
+			BounceType:   r.BounceType,
+			CheckedAt:    r.CheckedAt,
+			Score:        r.Score,
+			IsRoleBased:  r.IsRoleBased,
+			IsDisposable: r.IsDisposable,
+			IsCatchAll:   r.IsCatchAll,
+			IsFree:       r.IsFree,
+			Suggestion:   r.Suggestion,
 		})
 	}
 	return db.Create(&toSave).Error
@@ -74,11 +89,11 @@ func BatchSaveResults(results []EmailValidationResult) error {
 func CreateBulkJob(fileName string, totalEmails int) model.BulkJob {
 	db := infra.GetDB()
 	job := model.BulkJob{
-		FileName:   fileName,
-		UploadedAt: time.Now(),
-		// Status:          "processing",
-		TotalEmails: totalEmails,
-		// ProcessedEmails: 0,
+		FileName:        fileName,
+		UploadedAt:      time.Now(),
+		Status:          model.JobStatusQueued,
+		TotalEmails:     totalEmails,
+		ProcessedEmails: 0,
 	}
 	db.Create(&job)
 	return job
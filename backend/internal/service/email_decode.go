@@ -0,0 +1,166 @@
+package service
+
+import (
+	"encoding/hex"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Décodage des emails masqués dans une page: protection email de Cloudflare,
+// entités HTML, texte inversé par CSS et concaténation JavaScript. Les textes
+// décodés passent ensuite par la même recherche que le texte de la page.
+
+var (
+	// data-cfemail="..." et /cdn-cgi/l/email-protection#...
+	cfEmailRegex = regexp.MustCompile(`(?:data-cfemail=["']?|/cdn-cgi/l/email-protection#)([0-9a-fA-F]{4,})`)
+	// Paramètres d'un lien mailto: dans le HTML rendu
+	mailtoQueryRegex = regexp.MustCompile(`(?i)(mailto:[^"'?\s<>]*)\?[^"'\s<>]*`)
+	// Règle CSS qui inverse l'affichage: .cls { unicode-bidi: bidi-override; direction: rtl }
+	cssRuleRegex  = regexp.MustCompile(`([^{}]+)\{([^{}]*)\}`)
+	cssClassRegex = regexp.MustCompile(`^\.([\w-]+)$`)
+	// Chaînes et variables JavaScript concaténées: 'info' + '@' + domain
+	jsOperand      = `(?:'[^'\n]*'|"[^"\n]*"|[A-Za-z_$][\w$]*)`
+	jsConcatRegex  = regexp.MustCompile(jsOperand + `(?:\s*\+\s*` + jsOperand + `)+`)
+	jsOperandRegex = regexp.MustCompile(jsOperand)
+	// var user = 'info'; let domain = "example.com"
+	jsAssignRegex = regexp.MustCompile(`(?:var|let|const)\s+([A-Za-z_$][\w$]*)\s*=\s*('[^'\n]*'|"[^"\n]*")`)
+)
+
+// pageEmails retourne les emails valides d'une page, en clair ou masqués, sans doublon
+func pageEmails(dom *goquery.Selection, htmlRaw string) []string {
+	// HTML rendu aux entités décodées: texte, attributs et scripts en une seule lecture.
+	// Les paramètres des liens mailto: (?subject=...) sont retirés, pageLinks les lit.
+	content := []string{html.UnescapeString(mailtoQueryRegex.ReplaceAllString(htmlRaw, "$1"))}
+	reversed := reversedTexts(dom)
+	content = append(content, reversed...)
+	content = append(content, decodeObfuscated(dom, htmlRaw)...)
+
+	// Un texte inversé lu à l'envers peut ressembler à un email (moc.x@eod.nhoj)
+	decoys := map[string]bool{}
+	for _, text := range reversed {
+		for _, raw := range findEmails(reverseString(text)) {
+			decoys[raw] = true
+		}
+	}
+	// Liens mailto: lus explicitement (%40, ?subject=, cc)
+	emails, _ := pageLinks(dom)
+	for _, email := range findEmails(strings.Join(content, "\n")) {
+		if !decoys[email] {
+			emails = append(emails, email)
+		}
+	}
+	return uniqueStrings(emails)
+}
+
+// findEmails retourne les emails valides d'un texte, variantes [at]/(dot) comprises
+func findEmails(text string) []string {
+	var emails []string
+	for _, raw := range emailObfuscatedRegex.FindAllString(text, -1) {
+		email := strings.ToLower(strings.TrimSpace(cleanObfuscatedEmail(raw)))
+		if isValidEmail(email) {
+			emails = append(emails, email)
+		}
+	}
+	return emails
+}
+
+// decodeObfuscated retourne les textes décodés de la protection Cloudflare et des
+// concaténations JavaScript
+func decodeObfuscated(dom *goquery.Selection, htmlRaw string) []string {
+	var decoded []string
+	for _, m := range cfEmailRegex.FindAllStringSubmatch(htmlRaw, -1) {
+		if email, ok := decodeCFEmail(m[1]); ok {
+			decoded = append(decoded, email)
+		}
+	}
+	dom.Find("script").Each(func(_ int, s *goquery.Selection) {
+		decoded = append(decoded, jsConcatStrings(html.UnescapeString(s.Text()))...)
+	})
+	return decoded
+}
+
+// decodeCFEmail décode la protection email de Cloudflare: le premier octet est
+// la clé XOR des octets suivants
+func decodeCFEmail(encoded string) (string, bool) {
+	b, err := hex.DecodeString(encoded)
+	if err != nil || len(b) < 2 {
+		return "", false
+	}
+	key := b[0]
+	out := make([]byte, len(b)-1)
+	for i, c := range b[1:] {
+		out[i] = c ^ key
+	}
+	return string(out), true
+}
+
+// reversedTexts retourne, remis à l'endroit, le texte des éléments affichés de
+// droite à gauche par CSS (attribut style ou classe d'une règle <style>)
+func reversedTexts(dom *goquery.Selection) []string {
+	var texts []string
+	reverse := func(_ int, s *goquery.Selection) {
+		texts = append(texts, reverseString(s.Text()))
+	}
+	dom.Find("[style]").Each(func(i int, s *goquery.Selection) {
+		if isReversedStyle(s.AttrOr("style", "")) {
+			reverse(i, s)
+		}
+	})
+	dom.Find("style").Each(func(_ int, style *goquery.Selection) {
+		for _, rule := range cssRuleRegex.FindAllStringSubmatch(style.Text(), -1) {
+			if !isReversedStyle(rule[2]) {
+				continue
+			}
+			for _, selector := range strings.Split(rule[1], ",") {
+				if m := cssClassRegex.FindStringSubmatch(strings.TrimSpace(selector)); m != nil {
+					dom.Find("." + m[1]).Each(reverse)
+				}
+			}
+		}
+	})
+	return texts
+}
+
+func isReversedStyle(style string) bool {
+	style = strings.ToLower(strings.Join(strings.Fields(style), ""))
+	return strings.Contains(style, "direction:rtl") && strings.Contains(style, "unicode-bidi:bidi-override")
+}
+
+func reverseString(s string) string {
+	r := []rune(s)
+	for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+		r[i], r[j] = r[j], r[i]
+	}
+	return string(r)
+}
+
+// jsConcatStrings évalue les concaténations de chaînes d'un script; les variables
+// sont résolues par leur affectation littérale, une variable inconnue écarte l'expression
+func jsConcatStrings(script string) []string {
+	vars := map[string]string{}
+	for _, m := range jsAssignRegex.FindAllStringSubmatch(script, -1) {
+		vars[m[1]] = m[2][1 : len(m[2])-1]
+	}
+	var out []string
+	for _, expr := range jsConcatRegex.FindAllString(script, -1) {
+		var b strings.Builder
+		ok := true
+		for _, operand := range jsOperandRegex.FindAllString(expr, -1) {
+			if operand[0] == '\'' || operand[0] == '"' {
+				b.WriteString(operand[1 : len(operand)-1])
+			} else if v, known := vars[operand]; known {
+				b.WriteString(v)
+			} else {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			out = append(out, b.String())
+		}
+	}
+	return out
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Chaque page de testdata/emails commence par <!-- expect: email1 email2 ... -->
+var expectRegex = regexp.MustCompile(`<!--\s*expect:([^>]*)-->`)
+
+func TestPageEmailsFixtures(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "emails", "*.html"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no fixtures: %v", err)
+	}
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			page, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			m := expectRegex.FindSubmatch(page)
+			if m == nil {
+				t.Fatal("missing expect comment")
+			}
+			want := strings.Fields(string(m[1]))
+			sort.Strings(want)
+
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(string(page)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			dom := doc.Find("html")
+			htmlRaw, _ := dom.Html()
+			got := pageEmails(dom, htmlRaw)
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("pageEmails = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestDecodeCFEmail(t *testing.T) {
+	tests := []struct {
+		encoded, want string
+		ok            bool
+	}{
+		{"5a3935342e3b392e1a3b2e3f36333f28773e2f2a35342e743c28", "contact@atelier-dupont.fr", true},
+		{"00", "", false},
+		{"zz1234", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := decodeCFEmail(tt.encoded)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("decodeCFEmail(%q) = %q, %t, want %q, %t", tt.encoded, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestJSConcatStrings(t *testing.T) {
+	script := `var u = 'info'; const d = "example.com";
+		a = u + '@' + d;
+		b = 'sales' + "@" + 'example.org';
+		c = u + missing;`
+	got := jsConcatStrings(script)
+	want := []string{"info@example.com", "sales@example.org"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("jsConcatStrings = %q, want %q", got, want)
+	}
+}
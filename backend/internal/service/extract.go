@@ -1,29 +1,76 @@
 package service
 
 import (
+	"backend/internal/config"
+	"backend/internal/model"
+	"errors"
 	"fmt"
 	"net/mail"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/gocolly/colly/v2"
 )
 
-// Expressions régulières pour emails et téléphones
+// Expressions régulières pour emails et réseaux sociaux (téléphones: voir phone.go)
 var (
 	emailRegex           = regexp.MustCompile(`[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}`)
 	emailObfuscatedRegex = regexp.MustCompile(`[a-zA-Z0-9._%+\-]+(?:@|\[at\]|\(at\)|\{at\}|<at>)[a-zA-Z0-9.\-]+(?:\.|\[dot\]|\(dot\)|\{dot\}|<dot>)[a-zA-Z]{2,}`)
-	phoneRegex           = regexp.MustCompile(`(?m)\b(?:\+?\d{1,3}[-.\s])?(?:\(?\d{3}\)?[-.\s]?)\d{3}[-.\s]?\d{4}\b`)
-	socialRegex          = regexp.MustCompile(`https?://(www\.)?(facebook\.com|twitter\.com|x\.com|linkedin\.com/company|instagram\.com)(/[^/?#]+)(/)?(\s|$|"|')`)
+	socialRegex          = regexp.MustCompile(`https?://(www\.)?(facebook\.com|twitter\.com|x\.com|linkedin\.com/company|instagram\.com)(/[^/?#"'\s<>,]+)(/)?(\s|$|"|')`)
 )
 
 var forbiddenExt = []string{".png", ".jpg", ".jpeg", ".gif", ".svg", ".webp", ".css", ".js", ".ico", ".pdf"}
 
+var ErrInvalidExtractMode = errors.New("extract mode must be fast or deep")
+
 // ExtractedEmail structure pour email + domaine
 type ExtractedEmail struct {
-	Email  string `json:"email"`
-	Domain string `json:"domain"`
+	Email      string `json:"email"`
+	Domain     string `json:"domain"`
+	Source     string `json:"source,omitempty"`     // page où l'email a été trouvé
+	Suppressed bool   `json:"suppressed,omitempty"` // adresse de la liste de suppression
+}
+
+// SiteContact est un téléphone (E.164) ou un profil social avec la page où il a été trouvé
+type SiteContact struct {
+	Value   string `json:"value"`
+	Source  string `json:"source"`
+	Country string `json:"country,omitempty"` // téléphone: pays du numéro
+	Type    string `json:"type,omitempty"`    // téléphone: mobile, landline, toll_free ou unknown
+}
+
+// SiteContacts regroupe les contacts trouvés sur un site
+type SiteContacts struct {
+	Emails  []ExtractedEmail `json:"emails"`
+	Phones  []SiteContact    `json:"phones"`
+	Socials []SiteContact    `json:"socials"`
+	// Organisations et personnes des données structurées (mode deep)
+	Organizations []SiteOrganization `json:"organizations,omitempty"`
+	// Pages non visitées car interdites par robots.txt
+	RobotsSkipped []string `json:"robotsSkipped,omitempty"`
+}
+
+// ParseExtractMode valide le mode d'extraction (fast par défaut)
+func ParseExtractMode(mode string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "", model.ExtractModeFast:
+		return model.ExtractModeFast, nil
+	case model.ExtractModeDeep:
+		return model.ExtractModeDeep, nil
+	}
+	return "", ErrInvalidExtractMode
+}
+
+// ExtractSite extrait les contacts d'un site: emails de la page d'accueil et des
+// pages de contact en mode fast, emails, téléphones et réseaux sociaux du site crawlé en mode deep.
+// ignoreRobots désactive les règles de robots.txt (sites dont on est propriétaire).
+func ExtractSite(url, mode string, ignoreRobots bool) (SiteContacts, error) {
+	if mode == model.ExtractModeDeep {
+		return ExtractContactsFromSite(url, ignoreRobots)
+	}
+	return ExtractEmailsFromSiteFast(url, ignoreRobots)
 }
 
 func isValidEmail(email string) bool {
@@ -64,100 +111,129 @@ func uniqueStrings(input []string) []string {
 	return result
 }
 
-// ExtractEmailsFromSiteFast extrait seulement les emails et domaines de la page principale
-func ExtractEmailsFromSiteFast(url string) (emails []ExtractedEmail, err error) {
-	c := colly.NewCollector(
-		colly.MaxDepth(1),
-		colly.AllowedDomains(getDomain(url)),
-		colly.Async(true),
-	)
-	c.SetRequestTimeout(10 * time.Second)
+// fastCrawl et deepCrawl bornent les deux modes d'extraction; le nombre de pages
+// vient de la configuration (CRAWL_FAST_PAGES, CRAWL_DEEP_PAGES)
+func fastCrawl(ignoreRobots bool) crawlOptions {
+	return crawlOptions{maxPages: config.Load().CrawlFastPages, maxDepth: 1, onlyScored: true, timeout: 10 * time.Second, ignoreRobots: ignoreRobots}
+}
+
+func deepCrawl(ignoreRobots bool) crawlOptions {
+	return crawlOptions{maxPages: config.Load().CrawlDeepPages, maxDepth: 2, timeout: 15 * time.Second, delay: time.Second, ignoreRobots: ignoreRobots, sitemaps: true}
+}
 
-	foundEmails := make(map[string]struct{})
+// ExtractEmailsFromSiteFast extrait les emails de la page principale et des pages
+// de contact qu'elle référence (contact, mentions légales, à propos...)
+func ExtractEmailsFromSiteFast(url string, ignoreRobots bool) (SiteContacts, error) {
+	// email -> page où il a été trouvé
+	foundEmails := make(map[string]string)
 
-	c.OnHTML("html", func(e *colly.HTMLElement) {
-		htmlText := e.DOM.Text()
+	skipped, err := crawlSite(url, fastCrawl(ignoreRobots), func(e *colly.HTMLElement) {
+		// Emails en clair ou masqués (voir email_decode.go)
 		htmlRaw, _ := e.DOM.Html()
-		htmlContent := htmlText + htmlRaw
-		matches := emailObfuscatedRegex.FindAllString(htmlContent, -1)
-		for _, raw := range matches {
-			email := cleanObfuscatedEmail(raw)
-			email = strings.ToLower(strings.TrimSpace(email))
-			if isValidEmail(email) {
-				foundEmails[email] = struct{}{}
+		for _, email := range pageEmails(e.DOM, htmlRaw) {
+			if _, ok := foundEmails[email]; !ok {
+				foundEmails[email] = e.Request.URL.String()
 			}
 		}
-	})
-
-	err = c.Visit(url)
+	}, nil)
 	if err != nil {
-		return
+		return SiteContacts{}, err
 	}
-	c.Wait()
 
 	// Unicité
-	emails = make([]ExtractedEmail, 0, len(foundEmails))
-	for em := range foundEmails {
-		domain := ""
-		if at := strings.LastIndex(em, "@"); at != -1 && at+1 < len(em) {
-			domain = em[at+1:]
-		}
-		emails = append(emails, ExtractedEmail{Email: em, Domain: domain})
+	contacts := SiteContacts{Emails: make([]ExtractedEmail, 0, len(foundEmails)), RobotsSkipped: skipped}
+	for _, em := range sortedKeys(foundEmails) {
+		contacts.Emails = append(contacts.Emails, ExtractedEmail{Email: em, Domain: emailDomain(em), Source: foundEmails[em]})
 	}
-	return
+	return contacts, nil
 }
 
-// ExtractContactsFromSite crawl plusieurs pages d'un site (profondeur 2) et extrait emails, téléphones, réseaux sociaux
-func ExtractContactsFromSite(url string) (emails, phones, socials []string, err error) {
-	c := colly.NewCollector(
-		colly.MaxDepth(2),
-		colly.AllowedDomains(getDomain(url)),
-		colly.Async(true),
-	)
-
-	// Limiter le temps d'attente et le nombre de pages
-	c.SetRequestTimeout(15 * time.Second)
-	c.Limit(&colly.LimitRule{
-		DomainGlob:  "*",
-		Parallelism: 2,
-		RandomDelay: 1 * time.Second,
-	})
-
-	foundEmails := make(map[string]struct{})
-	foundPhones := make(map[string]struct{})
-	foundSocials := make(map[string]struct{})
+// ExtractContactsFromSite crawl un site (profondeur 2, pages de contact d'abord) et
+// extrait emails, téléphones, réseaux sociaux et organisations décrites par les
+// données structurées, chacun avec la première page où il apparaît
+func ExtractContactsFromSite(url string, ignoreRobots bool) (SiteContacts, error) {
+	// valeur -> page où elle a été trouvée
+	foundEmails := make(map[string]string)
+	foundPhones := make(map[string]SiteContact) // par numéro E.164
+	foundSocials := make(map[string]string)
+	var orgs []SiteOrganization
+	seenOrgs := make(map[string]bool)
+	record := func(found map[string]string, value, page string) {
+		if _, ok := found[value]; !ok {
+			found[value] = page
+		}
+	}
+	recordPhone := func(raw, country, page string) {
+		ph, err := ParsePhone(raw, country)
+		if err != nil {
+			return
+		}
+		if _, ok := foundPhones[ph.E164]; !ok {
+			foundPhones[ph.E164] = SiteContact{Value: ph.E164, Source: page, Country: ph.Country, Type: ph.Type}
+		}
+	}
+	// Les emails, téléphones et profils sociaux des organisations rejoignent les listes à plat
+	recordOrgs := func(found []SiteOrganization, country string) {
+		for _, org := range found {
+			if seenOrgs[org.key()] {
+				continue
+			}
+			seenOrgs[org.key()] = true
+			orgs = append(orgs, org)
+			for _, email := range org.Emails {
+				record(foundEmails, email, org.Source)
+			}
+			for _, phone := range org.Phones {
+				recordPhone(phone, country, org.Source)
+			}
+			for _, link := range org.SameAs {
+				if isSocialProfile(link) {
+					record(foundSocials, strings.TrimRight(link, "/"), org.Source)
+				}
+			}
+		}
+	}
 
-	c.OnHTML("html", func(e *colly.HTMLElement) {
-		htmlText := e.DOM.Text()
+	skipped, err := crawlSite(url, deepCrawl(ignoreRobots), func(e *colly.HTMLElement) {
 		htmlRaw, _ := e.DOM.Html()
-		htmlContent := htmlText + htmlRaw
-		for _, em := range emailRegex.FindAllString(htmlContent, -1) {
-			foundEmails[em] = struct{}{}
+		page := e.Request.URL.String()
+		for _, email := range pageEmails(e.DOM, htmlRaw) {
+			record(foundEmails, email, page)
 		}
-		for _, ph := range phoneRegex.FindAllString(htmlContent, -1) {
-			foundPhones[ph] = struct{}{}
+		// Numéros nationaux lus dans le pays du site (extension du domaine, puis langue)
+		country := InferPhoneCountry(page, e.Attr("lang"))
+		_, tels := pageLinks(e.DOM)
+		for _, tel := range tels {
+			recordPhone(tel, country, page)
 		}
-		for _, so := range socialRegex.FindAllString(htmlContent, -1) {
-			foundSocials[so] = struct{}{}
+		for _, ph := range ExtractPhones(e.DOM.Text(), country) {
+			recordPhone(ph.E164, country, page)
 		}
+		for _, so := range socialRegex.FindAllString(htmlRaw, -1) {
+			record(foundSocials, strings.TrimRight(so, "/\"' \t\r\n"), page)
+		}
+		recordOrgs(pageOrganizations(e.DOM, page, country), country)
+	}, func(r *colly.Response) {
+		file := r.Request.URL.String()
+		country := InferPhoneCountry(file, "")
+		recordOrgs(parseVCards(r.Body, file, country), country)
 	})
-
-	c.OnHTML("a[href]", func(e *colly.HTMLElement) {
-		href := e.Request.AbsoluteURL(e.Attr("href"))
-		c.Visit(href)
-	})
-
-	err = c.Visit(url)
 	if err != nil {
-		return
+		return SiteContacts{}, err
 	}
 
-	c.Wait() // Attendre que le crawling soit terminé
-
-	emails = mapKeys(foundEmails)
-	phones = mapKeys(foundPhones)
-	socials = mapKeys(foundSocials)
-	return
+	contacts := SiteContacts{RobotsSkipped: skipped, Organizations: orgs}
+	for _, em := range sortedKeys(foundEmails) {
+		contacts.Emails = append(contacts.Emails, ExtractedEmail{Email: em, Domain: emailDomain(em), Source: foundEmails[em]})
+	}
+	for _, sc := range foundPhones {
+		contacts.Phones = append(contacts.Phones, sc)
+	}
+	sort.Slice(contacts.Phones, func(i, j int) bool { return contacts.Phones[i].Value < contacts.Phones[j].Value })
+	for _, so := range sortedKeys(foundSocials) {
+		contacts.Socials = append(contacts.Socials, SiteContact{Value: so, Source: foundSocials[so]})
+	}
+	return contacts, nil
 }
 
 // getDomain extrait le domaine d'une URL pour limiter le crawl
@@ -174,16 +250,12 @@ func getDomain(url string) string {
 	if idx := regexp.MustCompile(`/`).FindStringIndex(url[start:]); idx != nil {
 		end = start + idx[0]
 	}
-	return url[start:end]
-}
-
-// mapKeys retourne les clés d'une map[string]struct{} sous forme de slice
-func mapKeys(m map[string]struct{}) []string {
-	res := make([]string, 0, len(m))
-	for k := range m {
-		res = append(res, k)
+	// colly compare le nom d'hôte, sans le port
+	host := url[start:end]
+	if i := strings.LastIndex(host, ":"); i != -1 && !strings.Contains(host[i:], "]") {
+		host = host[:i]
 	}
-	return res
+	return host
 }
 
 func normalizeURL(u string) string {
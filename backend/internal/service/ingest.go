@@ -0,0 +1,462 @@
+package service
+
+import (
+	"backend/internal/config"
+	"backend/internal/infra"
+	"backend/internal/model"
+	"backend/internal/util"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Nombre de lignes insérées dans la file par transaction
+const ingestBatchSize = 1000
+
+// Au-delà de ce nombre d'éléments en attente pour un job, l'ingestion attend
+// que les workers avancent (contre-pression)
+const ingestMaxPending = 50000
+
+// ErrNoRows est retourné quand le fichier ne contient aucune valeur à traiter
+var ErrNoRows = errors.New("no rows to process")
+
+// ErrRowLimit est retourné quand le fichier a plus de lignes à traiter que MAX_UPLOAD_ROWS
+var ErrRowLimit = errors.New("row limit exceeded")
+
+var errIngestionCancelled = errors.New("job cancelled during ingestion")
+
+var errListIngestionInterrupted = errors.New("list verification interrupted while queueing members")
+
+var errReverifyIngestionInterrupted = errors.New("re-verification interrupted while queueing rows")
+
+// SpoolUpload copie le fichier uploadé dans UPLOAD_DIR et retourne son chemin
+func SpoolUpload(fileHeader *multipart.FileHeader) (string, error) {
+	dir := config.Load().UploadDir
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	src, err := fileHeader.Open()
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	name := make([]byte, 16)
+	rand.Read(name)
+	path := filepath.Join(dir, hex.EncodeToString(name)+filepath.Ext(fileHeader.Filename))
+	dst, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		os.Remove(path)
+		return "", err
+	}
+	return path, dst.Close()
+}
+
+// openSpooledList ouvre le fichier spoolé (CSV, TSV, XLSX, JSON, texte) et lit son en-tête
+func openSpooledList(path, sheet string) (util.ListReader, error) {
+	return util.OpenListReader(path, util.ListOptions{Sheet: sheet})
+}
+
+// ReadSpooledHeader retourne l'en-tête du fichier spoolé et le format détecté
+func ReadSpooledHeader(path, sheet string) ([]string, util.ListInfo, error) {
+	reader, err := openSpooledList(path, sheet)
+	if err != nil {
+		return nil, util.ListInfo{}, err
+	}
+	defer reader.Close()
+	return reader.Header(), reader.Info(), nil
+}
+
+// FindColumn retourne l'index de la colonne nommée name (insensible à la casse), ou -1
+func FindColumn(headers []string, name string) int {
+	for i, h := range headers {
+		if strings.EqualFold(strings.TrimSpace(h), strings.TrimSpace(name)) {
+			return i
+		}
+	}
+	return -1
+}
+
+// hasRows vérifie, sans tout charger, qu'au moins une ligne a une valeur dans la colonne
+func hasRows(path, sheet string, column int) (bool, error) {
+	reader, err := openSpooledList(path, sheet)
+	if err != nil {
+		return false, err
+	}
+	defer reader.Close()
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return false, nil
+		}
+		if err != nil && !util.IsRowError(err) {
+			return false, err
+		}
+		if err != nil || column >= len(record) {
+			continue
+		}
+		if strings.TrimSpace(record[column]) != "" {
+			return true, nil
+		}
+	}
+}
+
+// IngestOptions regroupe les choix de l'utilisateur appliqués pendant l'ingestion
+type IngestOptions struct {
+	// Ne vérifier qu'une fois les adresses en double (job de vérification)
+	SkipDuplicates bool
+	// Niveau de priorité (model.JobPriority*) et propriétaire pour le partage des workers
+	Priority int
+	Owner    string
+	// Notifié à la fin du job, corps signé avec WebhookSecret
+	WebhookURL    string
+	WebhookSecret string
+	// Mode d'un job d'extraction (model.ExtractMode*)
+	ExtractMode string
+	// Extraction de sites dont on est propriétaire: robots.txt ignoré
+	IgnoreRobots bool
+}
+
+// CreateIngestingBulkJob crée un job à partir d'un fichier spoolé et lance son
+// ingestion en arrière-plan: les lignes sont lues au fil de l'eau et mises en
+// file par lots, les workers commençant à traiter les premiers lots aussitôt.
+func CreateIngestingBulkJob(kind, fileName, spoolPath string, info util.ListInfo, headers []string, column int, opts IngestOptions) (model.BulkJob, error) {
+	ok, err := hasRows(spoolPath, info.Sheet, column)
+	if err != nil {
+		return model.BulkJob{}, err
+	}
+	if !ok {
+		return model.BulkJob{}, ErrNoRows
+	}
+
+	db := infra.GetDB()
+	headersJSON, _ := json.Marshal(headers)
+	job := model.BulkJob{
+		Kind:         kind,
+		FileName:     fileName,
+		Headers:      string(headersJSON),
+		UploadedAt:   time.Now(),
+		Status:       model.JobStatusQueued,
+		Ingesting:    true,
+		SpoolPath:    spoolPath,
+		SourceColumn: column,
+		SourceFormat: info.Format,
+		SourceSheet:  info.Sheet,
+		// Les doublons ne concernent que la vérification
+		SkipDuplicates: opts.SkipDuplicates && kind == model.WorkKindVerify,
+		WebhookURL:     opts.WebhookURL,
+		WebhookSecret:  opts.WebhookSecret,
+		Priority:       opts.Priority,
+		Owner:          opts.Owner,
+	}
+	if kind == model.WorkKindExtract {
+		job.ExtractMode = opts.ExtractMode
+		job.IgnoreRobots = opts.IgnoreRobots
+		if job.ExtractMode == "" {
+			job.ExtractMode = model.ExtractModeFast
+		}
+	}
+	if err := db.Create(&job).Error; err != nil {
+		return job, err
+	}
+	go runIngestion(job.ID)
+	return job, nil
+}
+
+func runIngestion(jobId string) {
+	if err := ingestUpload(jobId); err != nil {
+		log.Printf("ingest job %s: %v", jobId, err)
+		failIngestion(jobId, err)
+	}
+}
+
+// ingestUpload lit le fichier spoolé à partir de la dernière ligne enregistrée
+// (reprise après redémarrage) et insère les lignes dans la file par lots
+func ingestUpload(jobId string) error {
+	job, err := GetBulkJobByID(jobId)
+	if err != nil {
+		return err
+	}
+	reader, err := openSpooledList(job.SpoolPath, job.SourceSheet)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	// Première lecture: rapport pré-vérification et limite de lignes, avant toute mise en file
+	queued, report, err := countQueuedRows(job)
+	if err != nil {
+		return err
+	}
+	if report != nil {
+		data, _ := json.Marshal(report)
+		if err := infra.GetDB().Model(&model.BulkJob{}).Where("id = ?", jobId).Update("preflight", string(data)).Error; err != nil {
+			return err
+		}
+	}
+	if maxRows := config.Load().MaxUploadRows; queued > maxRows {
+		return fmt.Errorf("%w (max %d, file has %d)", ErrRowLimit, maxRows, queued)
+	}
+	index := 0
+	batch := make([]SourceRow, 0, ingestBatchSize)
+	// Analyse pré-vérification: syntaxe invalide et doublons ne sont pas mis en file
+	var analyzer *preflightAnalyzer
+	var skipped []model.EmailResult
+	if job.Kind == model.WorkKindVerify {
+		analyzer = newPreflightAnalyzer(job.SkipDuplicates)
+	}
+	// consumed = nombre de lignes de données lues, enregistré avec le lot pour la reprise
+	flush := func(consumed int) error {
+		if err := waitForQueueCapacity(jobId); err != nil {
+			return err
+		}
+		if current, err := GetBulkJobByID(jobId); err != nil || current.Status == model.JobStatusCancelled {
+			return errIngestionCancelled
+		}
+		err := infra.GetDB().Transaction(func(tx *gorm.DB) error {
+			if len(batch) > 0 {
+				if err := enqueueWorkItems(tx, jobId, job.Kind, batch); err != nil {
+					return err
+				}
+			}
+			if len(skipped) > 0 {
+				if err := tx.CreateInBatches(&skipped, 1000).Error; err != nil {
+					return err
+				}
+			}
+			return tx.Model(&model.BulkJob{}).Where("id = ?", jobId).Updates(map[string]interface{}{
+				"total_emails":  gorm.Expr("total_emails + ?", len(batch)),
+				"ingested_rows": consumed,
+			}).Error
+		})
+		batch = batch[:0]
+		skipped = skipped[:0]
+		return err
+	}
+
+	for ; ; index++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil && !util.IsRowError(err) {
+			return err
+		}
+		if err != nil {
+			continue
+		}
+		value := ""
+		if job.SourceColumn < len(record) {
+			value = strings.TrimSpace(record[job.SourceColumn])
+		}
+		var outcome preflightOutcome
+		if analyzer != nil {
+			outcome = analyzer.add(value)
+		}
+		// Lignes déjà traitées avant une interruption: relues seulement pour reconstruire
+		// l'analyse. Une ligne sans adresse n'est conservée que par la vérification.
+		if index < job.IngestedRows || (value == "" && analyzer == nil) {
+			continue
+		}
+		if analyzer != nil && !outcome.queue {
+			skipped = append(skipped, skippedResult(jobId, SourceRow{Index: index, Value: value, Columns: record}, outcome))
+			if len(batch)+len(skipped) >= ingestBatchSize {
+				if err := flush(index + 1); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		batch = append(batch, SourceRow{Index: index, Value: value, Columns: record})
+		if len(batch)+len(skipped) >= ingestBatchSize {
+			if err := flush(index + 1); err != nil {
+				return err
+			}
+		}
+	}
+	if err := flush(index); err != nil {
+		return err
+	}
+
+	// Fin de lecture: le job peut être clôturé quand la file sera vide
+	updates := map[string]interface{}{
+		"ingesting":  false,
+		"spool_path": "",
+	}
+	err = infra.GetDB().Model(&model.BulkJob{}).Where("id = ?", jobId).Updates(updates).Error
+	if err != nil {
+		return err
+	}
+	os.Remove(job.SpoolPath)
+	return finalizeJobIfComplete(jobId)
+}
+
+// countQueuedRows lit une première fois le fichier et compte les lignes qui
+// seront mises en file, après l'analyse pré-vérification dont le rapport est
+// retourné (nil pour l'extraction)
+func countQueuedRows(job model.BulkJob) (int, *PreflightReport, error) {
+	if job.Kind == model.WorkKindVerify {
+		report, err := AnalyzeSpooledList(job.SpoolPath, job.SourceSheet, job.SourceColumn, job.SkipDuplicates)
+		if err != nil {
+			return 0, nil, err
+		}
+		return report.Queued, &report, nil
+	}
+	reader, err := openSpooledList(job.SpoolPath, job.SourceSheet)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer reader.Close()
+	queued := 0
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return queued, nil, nil
+		}
+		if err != nil && !util.IsRowError(err) {
+			return 0, nil, err
+		}
+		if err == nil && job.SourceColumn < len(record) && strings.TrimSpace(record[job.SourceColumn]) != "" {
+			queued++
+		}
+	}
+}
+
+// waitForQueueCapacity bloque tant que le job a trop d'éléments en attente,
+// ou jusqu'à ce qu'il soit annulé
+func waitForQueueCapacity(jobId string) error {
+	db := infra.GetDB()
+	for {
+		var pending int64
+		if err := db.Model(&model.WorkItem{}).
+			Where("job_id = ? AND status = ?", jobId, model.WorkItemPending).
+			Count(&pending).Error; err != nil {
+			return err
+		}
+		if pending < ingestMaxPending {
+			return nil
+		}
+		job, err := GetBulkJobByID(jobId)
+		if err != nil {
+			return err
+		}
+		if job.Status == model.JobStatusCancelled {
+			return errIngestionCancelled
+		}
+		time.Sleep(2 * time.Second)
+	}
+}
+
+// queueRowsBatch met en file un lot lu depuis la base (membres d'une liste,
+// lignes re-vérifiées) avec la contre-pression de l'ingestion d'un fichier, dans
+// une transaction qui ajoute le lot au total du job
+func queueRowsBatch(job model.BulkJob, rows []SourceRow) error {
+	if err := waitForQueueCapacity(job.ID); err != nil {
+		return err
+	}
+	if current, err := GetBulkJobByID(job.ID); err != nil || current.Status == model.JobStatusCancelled {
+		return errIngestionCancelled
+	}
+	return infra.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := enqueueWorkItems(tx, job.ID, job.Kind, rows); err != nil {
+			return err
+		}
+		return tx.Model(&model.BulkJob{}).Where("id = ?", job.ID).
+			Update("total_emails", gorm.Expr("total_emails + ?", len(rows))).Error
+	})
+}
+
+// finishQueueing marque la lecture terminée: le job peut être clôturé quand la file sera vide
+func finishQueueing(jobId string) error {
+	if err := infra.GetDB().Model(&model.BulkJob{}).Where("id = ?", jobId).Update("ingesting", false).Error; err != nil {
+		return err
+	}
+	return finalizeJobIfComplete(jobId)
+}
+
+// failIngestion arrête la lecture du fichier; le job passe en échec sauf s'il a été annulé
+func failIngestion(jobId string, cause error) {
+	db := infra.GetDB()
+	now := time.Now()
+	job, _ := GetBulkJobByID(jobId)
+	failed := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.BulkJob{}).Where("id = ?", jobId).Updates(map[string]interface{}{
+			"ingesting":  false,
+			"spool_path": "",
+		}).Error
+		if err != nil {
+			return err
+		}
+		res := tx.Model(&model.BulkJob{}).
+			Where("id = ? AND status IN ?", jobId, []string{model.JobStatusQueued, model.JobStatusProcessing, model.JobStatusPaused}).
+			Updates(map[string]interface{}{
+				"status":        model.JobStatusFailed,
+				"error_message": cause.Error(),
+				"finished_at":   &now,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		failed = res.RowsAffected == 1
+		return tx.Model(&model.WorkItem{}).
+			Where("job_id = ? AND status = ?", jobId, model.WorkItemPending).
+			Update("status", model.WorkItemCancelled).Error
+	})
+	if err != nil {
+		log.Printf("ingest job %s: cannot mark job failed: %v", jobId, err)
+		failed = false
+	}
+	if job.SpoolPath != "" {
+		os.Remove(job.SpoolPath)
+	}
+	if progress, err := GetJobProgress(jobId); err == nil {
+		PublishJobEvent(jobId, JobEventDone, progress)
+	}
+	// Un job annulé pendant la lecture a déjà été notifié par CancelBulkJob
+	if failed {
+		jobEnded(jobId)
+	}
+}
+
+// resumeIngestions relance la lecture des fichiers interrompue par un redémarrage
+func resumeIngestions() error {
+	db := infra.GetDB()
+	var jobs []model.BulkJob
+	err := db.Select("id", "list_id", "parent_job_id").
+		Where("ingesting = ? AND status IN ?", true, []string{model.JobStatusQueued, model.JobStatusProcessing, model.JobStatusPaused}).
+		Find(&jobs).Error
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		// La mise en file des membres d'une liste ou des lignes re-vérifiées n'est
+		// pas reprise: le job échoue et la vérification peut être relancée
+		if job.ListID != nil {
+			failIngestion(job.ID, errListIngestionInterrupted)
+			continue
+		}
+		if job.ParentJobID != nil {
+			failIngestion(job.ID, errReverifyIngestionInterrupted)
+			continue
+		}
+		log.Printf("ingest job %s: resuming", job.ID)
+		go runIngestion(job.ID)
+	}
+	return nil
+}
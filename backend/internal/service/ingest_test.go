@@ -0,0 +1,182 @@
+package service
+
+import (
+	"backend/internal/model"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// ingestTestCSV crée un job de vérification sur un CSV et lit le fichier
+// entièrement (sans passer par la goroutine de CreateIngestingBulkJob)
+func ingestTestCSV(t *testing.T, content string) (model.BulkJob, error) {
+	t.Helper()
+	db := testDB(t)
+	path := filepath.Join(t.TempDir(), "list.csv")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	headers, info, err := ReadSpooledHeader(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	job := model.BulkJob{
+		Kind:         model.WorkKindVerify,
+		FileName:     "list.csv",
+		Headers:      `["email","name"]`,
+		Status:       model.JobStatusQueued,
+		Ingesting:    true,
+		SpoolPath:    path,
+		SourceColumn: FindColumn(headers, "email"),
+		SourceFormat: info.Format,
+	}
+	if err := db.Create(&job).Error; err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { deleteTestJob(db, job.ID) })
+	err = ingestUpload(job.ID)
+	job, _ = GetBulkJobByID(job.ID)
+	return job, err
+}
+
+// Les lignes sans adresse sont gardées (statut vide) pour le fichier enrichi,
+// sans être mises en file ni comptées dans les statuts
+func TestIngestKeepsBlankRows(t *testing.T) {
+	db := testDB(t)
+	job, err := ingestTestCSV(t, "email,name\na@example.com,Ann\n,Bob\nb@example.com,Cid\n")
+	if err != nil {
+		t.Fatalf("ingest: %v", err)
+	}
+	if job.TotalEmails != 2 {
+		t.Errorf("TotalEmails = %d, want 2", job.TotalEmails)
+	}
+	var results []model.EmailResult
+	db.Where("job_id = ?", job.ID).Find(&results)
+	if len(results) != 1 || results[0].RowIndex != 1 || results[0].Status != "" || results[0].SourceRow != `["","Bob"]` {
+		t.Fatalf("blank rows = %+v", results)
+	}
+	tallies, err := GetJobStatusTallies(job.ID)
+	if err != nil || len(tallies) != 0 {
+		t.Errorf("tallies = %v, %v", tallies, err)
+	}
+	if report := GetPreflightReport(job); report == nil || report.BlankRows != 1 {
+		t.Errorf("preflight = %+v", report)
+	}
+}
+
+func TestCountQueuedRows(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "list.csv")
+	content := "email,name\na@example.com,Ann\nA@example.com,Ann\nnot-an-email,Bob\n,Cid\nb@example.com,Dan\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		job    model.BulkJob
+		queued int
+		report bool
+	}{
+		// Adresses valides mises en file: a, A et b
+		{"verify", model.BulkJob{Kind: model.WorkKindVerify}, 3, true},
+		// Doublons écartés
+		{"verify without duplicates", model.BulkJob{Kind: model.WorkKindVerify, SkipDuplicates: true}, 2, true},
+		// Extraction: toutes les valeurs non vides, sans rapport
+		{"extract", model.BulkJob{Kind: model.WorkKindExtract}, 4, false},
+	}
+	for _, tt := range tests {
+		tt.job.SpoolPath = path
+		queued, report, err := countQueuedRows(tt.job)
+		if err != nil || queued != tt.queued || (report != nil) != tt.report {
+			t.Errorf("%s: countQueuedRows = %d, %v, %v; want %d", tt.name, queued, report, err, tt.queued)
+		}
+		if report != nil && (report.Rows != 4 || report.BlankRows != 1 || report.SyntaxErrors != 1) {
+			t.Errorf("%s: report = %+v", tt.name, *report)
+		}
+	}
+}
+
+// Le rapport est enregistré avant que le job quitte l'ingestion, même si celle-ci échoue
+func TestIngestStoresPreflightFirst(t *testing.T) {
+	t.Setenv("MAX_UPLOAD_ROWS", "1")
+	job, err := ingestTestCSV(t, "email,name\na@example.com,Ann\nnot-an-email,Bob\nb@example.com,Cid\n")
+	if !errors.Is(err, ErrRowLimit) {
+		t.Fatalf("err = %v, want ErrRowLimit", err)
+	}
+	report := GetPreflightReport(job)
+	if report == nil || report.Queued != 2 || report.SyntaxErrors != 1 {
+		t.Fatalf("preflight = %+v", report)
+	}
+}
+
+// Un fichier au-delà de la limite échoue sans qu'aucune ligne soit mise en file
+func TestIngestRowLimitEnqueuesNothing(t *testing.T) {
+	db := testDB(t)
+	t.Setenv("MAX_UPLOAD_ROWS", "2")
+	job, err := ingestTestCSV(t, "email,name\na@example.com,Ann\nb@example.com,Bob\nc@example.com,Cid\n")
+	if !errors.Is(err, ErrRowLimit) {
+		t.Fatalf("err = %v, want ErrRowLimit", err)
+	}
+	var items int64
+	db.Model(&model.WorkItem{}).Where("job_id = ?", job.ID).Count(&items)
+	if items != 0 || job.TotalEmails != 0 {
+		t.Fatalf("%d items enqueued, TotalEmails = %d", items, job.TotalEmails)
+	}
+}
+
+func TestFindColumn(t *testing.T) {
+	headers := []string{"Name", " Email ", "phone"}
+	tests := []struct {
+		headers []string
+		name    string
+		want    int
+	}{
+		{headers, "email", 1},
+		{headers, "EMAIL ", 1},
+		{headers, "Phone", 2},
+		{headers, "website", -1},
+		{[]string{"phone"}, "email", -1}, // une colonne seule n'est pas retenue d'office
+		{[]string{"value"}, "value", 0},
+		{nil, "email", -1},
+	}
+	for _, tt := range tests {
+		if got := FindColumn(tt.headers, tt.name); got != tt.want {
+			t.Errorf("FindColumn(%q, %q) = %d, want %d", tt.headers, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestResolveColumnSingleColumn(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		file, content, name, kind string
+		want                      int
+		wantErr                   error
+	}{
+		// Fichier texte sans en-tête: colonne "value" générée, valeurs emails
+		{"emails.txt", "a@example.com\nb@example.com\n", "email", model.WorkKindVerify, 0, nil},
+		// Tableau JSON de chaînes
+		{"emails.json", `["a@example.com","b@example.com"]`, "Email", model.WorkKindVerify, 0, nil},
+		{"sites.txt", "example.com\nexample.org\n", "website", model.WorkKindExtract, 0, nil},
+		// Une seule colonne qui ne contient pas d'emails: refusée
+		{"phones.txt", "phone\n+33 1 23 45 67 89\n+33 6 12 34 56 78\n", "email", model.WorkKindVerify, -1, ErrColumnNotFound},
+		{"names.csv", "name\nAnn\nBob\n", "email", model.WorkKindVerify, -1, ErrColumnNotFound},
+		// Plusieurs colonnes: le nom doit exister
+		{"multi.csv", "name,mail\nAnn,a@example.com\n", "email", model.WorkKindVerify, -1, ErrColumnNotFound},
+		{"multi2.csv", "name,mail\nAnn,a@example.com\n", "mail", model.WorkKindVerify, 1, nil},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.file)
+		if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		headers, _, err := ReadSpooledHeader(path, "")
+		if err != nil {
+			t.Fatalf("%s: %v", tt.file, err)
+		}
+		got, err := ResolveColumn(path, "", headers, tt.name, tt.kind)
+		if got != tt.want || !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: ResolveColumn(%q) = %d, %v; want %d, %v", tt.file, tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"backend/internal/infra"
+	"backend/internal/model"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidJobTransition est retourné quand l'action n'est pas possible dans l'état actuel du job
+var ErrInvalidJobTransition = errors.New("invalid job state transition")
+
+// PauseBulkJob suspend un job: les workers terminent l'élément en cours puis
+// ne réservent plus d'éléments de ce job
+func PauseBulkJob(jobId string) (model.BulkJob, error) {
+	db := infra.GetDB()
+	res := db.Model(&model.BulkJob{}).
+		Where("id = ? AND status IN ?", jobId, []string{model.JobStatusQueued, model.JobStatusProcessing}).
+		Update("status", model.JobStatusPaused)
+	return afterJobTransition(jobId, res, JobEventProgress)
+}
+
+// ResumeBulkJob relance un job suspendu. Les derniers éléments ont pu se terminer
+// pendant la suspension: le job est alors clôturé aussitôt.
+func ResumeBulkJob(jobId string) (model.BulkJob, error) {
+	db := infra.GetDB()
+	status := gorm.Expr("CASE WHEN started_at IS NULL THEN ? ELSE ? END", model.JobStatusQueued, model.JobStatusProcessing)
+	res := db.Model(&model.BulkJob{}).
+		Where("id = ? AND status = ?", jobId, model.JobStatusPaused).
+		Update("status", status)
+	job, err := afterJobTransition(jobId, res, JobEventProgress)
+	if err != nil {
+		return job, err
+	}
+	if err := finalizeJobIfComplete(jobId); err != nil {
+		return job, err
+	}
+	return GetBulkJobByID(jobId)
+}
+
+// CancelBulkJob arrête définitivement un job. Les éléments en attente sont
+// annulés; les résultats déjà obtenus restent consultables et téléchargeables.
+func CancelBulkJob(jobId string) (model.BulkJob, error) {
+	db := infra.GetDB()
+	var res *gorm.DB
+	err := db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		res = tx.Model(&model.BulkJob{}).
+			Where("id = ? AND status IN ?", jobId, []string{model.JobStatusQueued, model.JobStatusProcessing, model.JobStatusPaused}).
+			Updates(map[string]interface{}{
+				"status":      model.JobStatusCancelled,
+				"finished_at": &now,
+			})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		return tx.Model(&model.WorkItem{}).
+			Where("job_id = ? AND status = ?", jobId, model.WorkItemPending).
+			Update("status", model.WorkItemCancelled).Error
+	})
+	if err != nil {
+		return model.BulkJob{}, err
+	}
+	return afterJobTransition(jobId, res, JobEventDone)
+}
+
+// afterJobTransition recharge le job et diffuse son nouvel état aux abonnés SSE
+func afterJobTransition(jobId string, res *gorm.DB, event string) (model.BulkJob, error) {
+	if res.Error != nil {
+		return model.BulkJob{}, res.Error
+	}
+	job, err := GetBulkJobByID(jobId)
+	if err != nil {
+		return job, err
+	}
+	if res.RowsAffected == 0 {
+		return job, ErrInvalidJobTransition
+	}
+	if progress, err := GetJobProgress(jobId); err == nil {
+		PublishJobEvent(jobId, event, progress)
+	}
+	if event == JobEventDone {
+		jobEnded(jobId)
+	}
+	return job, nil
+}
+
+// jobEnded notifie la fin d'un job; appelé une seule fois, par le processus qui
+// effectue la transition vers l'état final
+func jobEnded(jobId string) {
+	enqueueJobWebhooks(jobId)
+	notifyScheduledRun(jobId)
+}
+
+// IsJobActive indique si le job peut encore produire des résultats
+func IsJobActive(status string) bool {
+	return status == model.JobStatusQueued || status == model.JobStatusProcessing || status == model.JobStatusPaused
+}
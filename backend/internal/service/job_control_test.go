@@ -0,0 +1,54 @@
+package service
+
+import (
+	"backend/internal/model"
+	"testing"
+	"time"
+)
+
+// Les éléments réservés avant la suspension se terminent pendant celle-ci: la
+// reprise doit clôturer le job plutôt que le laisser "processing" sans travail
+func TestResumeFinalizesJobCompletedWhilePaused(t *testing.T) {
+	testDB(t)
+	job := createTestJob(t, model.BulkJob{FileName: "pause.csv"}, "a@example.com", "b@example.com")
+	items := claimTestItems(t, job.ID, "worker-a")
+
+	if _, err := PauseBulkJob(job.ID); err != nil {
+		t.Fatalf("pause: %v", err)
+	}
+	for _, item := range items {
+		res := EmailValidationResult{JobID: job.ID, Email: item.Payload, Status: "valid", CheckedAt: time.Now()}
+		if err := CompleteVerifyItem(item, res, false); err != nil {
+			t.Fatalf("complete %s: %v", item.Payload, err)
+		}
+	}
+	if got, _ := GetBulkJobByID(job.ID); got.Status != model.JobStatusPaused {
+		t.Fatalf("status while paused = %s", got.Status)
+	}
+
+	got, err := ResumeBulkJob(job.ID)
+	if err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	if got.Status != model.JobStatusDone || got.FinishedAt == nil {
+		t.Fatalf("after resume: status = %s, finishedAt = %v", got.Status, got.FinishedAt)
+	}
+}
+
+// Un job repris avec du travail restant repart en traitement
+func TestResumeKeepsJobWithPendingItems(t *testing.T) {
+	testDB(t)
+	job := createTestJob(t, model.BulkJob{FileName: "pause.csv"}, "a@example.com")
+	claimTestItems(t, job.ID, "worker-a")
+
+	if _, err := PauseBulkJob(job.ID); err != nil {
+		t.Fatalf("pause: %v", err)
+	}
+	got, err := ResumeBulkJob(job.ID)
+	if err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	if got.Status != model.JobStatusProcessing {
+		t.Fatalf("status = %s, want processing", got.Status)
+	}
+}
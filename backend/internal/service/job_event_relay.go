@@ -0,0 +1,111 @@
+package service
+
+import (
+	"backend/internal/config"
+	"backend/internal/infra"
+	"context"
+	"encoding/json"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Relais des événements de job entre processus par LISTEN/NOTIFY Postgres: chaque
+// événement publié est aussi notifié sur jobEventChannel, et le serveur API le
+// rediffuse à ses abonnés SSE. Les jobs traités par cmd/worker (ou par un autre
+// nœud API) atteignent ainsi tous les clients, sans table ni broker supplémentaire.
+const jobEventChannel = "job_events"
+
+// NOTIFY refuse les charges de plus de 8000 octets: un événement plus gros n'est
+// diffusé que localement (les "progress" et "done", toujours petits, passent)
+const maxNotifyPayload = 7900
+
+// Délai avant de rouvrir la connexion d'écoute perdue
+const jobEventRelayRetry = 5 * time.Second
+
+// relayedJobEvent est la charge d'une notification
+type relayedJobEvent struct {
+	Origin string          `json:"origin"`
+	JobID  string          `json:"jobId"`
+	Type   string          `json:"type"`
+	Data   json.RawMessage `json:"data"`
+}
+
+var (
+	// Identifiant du processus, pour ignorer ses propres notifications
+	jobEventOrigin = NewWorkerID()
+	// Vrai une fois EnableJobEventNotify ou RunJobEventListener appelé
+	jobEventNotify atomic.Bool
+	// Vrai tant que l'écoute est établie
+	jobEventListening atomic.Bool
+)
+
+// EnableJobEventNotify active la notification des événements publiés par ce processus
+func EnableJobEventNotify() {
+	jobEventNotify.Store(true)
+}
+
+// JobEventRelayActive indique si ce processus reçoit les événements des autres processus
+func JobEventRelayActive() bool {
+	return jobEventListening.Load()
+}
+
+// notifyJobEvent transmet un événement aux autres processus
+func notifyJobEvent(jobID, typ string, data interface{}) {
+	if !jobEventNotify.Load() {
+		return
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	payload, err := json.Marshal(relayedJobEvent{Origin: jobEventOrigin, JobID: jobID, Type: typ, Data: raw})
+	if err != nil || len(payload) > maxNotifyPayload {
+		return
+	}
+	db := infra.GetDB()
+	if err := db.Exec("SELECT pg_notify(?, ?)", jobEventChannel, string(payload)).Error; err != nil {
+		log.Printf("job events: notify failed: %v", err)
+	}
+}
+
+// RunJobEventListener écoute les événements des autres processus et les
+// rediffuse localement jusqu'à l'annulation du contexte
+func RunJobEventListener(ctx context.Context) {
+	EnableJobEventNotify()
+	for ctx.Err() == nil {
+		if err := listenJobEvents(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("job events: listener stopped: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(jobEventRelayRetry):
+		}
+	}
+}
+
+func listenJobEvents(ctx context.Context) error {
+	conn, err := pgx.Connect(ctx, config.Load().DatabaseURL)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+	if _, err := conn.Exec(ctx, "LISTEN "+jobEventChannel); err != nil {
+		return err
+	}
+	jobEventListening.Store(true)
+	defer jobEventListening.Store(false)
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		var ev relayedJobEvent
+		if json.Unmarshal([]byte(n.Payload), &ev) != nil || ev.Origin == jobEventOrigin {
+			continue
+		}
+		publishLocalJobEvent(ev.JobID, ev.Type, ev.Data)
+	}
+}
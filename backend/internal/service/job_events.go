@@ -0,0 +1,261 @@
+package service
+
+import (
+	"backend/internal/infra"
+	"backend/internal/model"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Types d'événements diffusés pour un job bulk; chaque type a une seule forme de données
+const (
+	JobEventProgress     = "progress"
+	JobEventResult       = "result"       // résultat de vérification, ou email trouvé par une extraction
+	JobEventContact      = "contact"      // téléphone ou réseau social trouvé par une extraction
+	JobEventOrganization = "organization" // organisation décrite par les données structurées d'un site
+	JobEventDone         = "done"
+)
+
+// Nombre d'événements conservés par job pour la reprise (Last-Event-ID)
+const jobEventHistorySize = 2000
+
+// Durée de conservation d'un flux après la fin du job
+const jobEventRetention = 10 * time.Minute
+
+// JobEvent est un événement numéroté d'un job; l'ID est croissant dans un flux,
+// l'époque distingue les flux successifs d'un même job (redémarrage, autre processus)
+type JobEvent struct {
+	ID    int64       `json:"id"`
+	Epoch string      `json:"epoch"`
+	Type  string      `json:"type"`
+	Data  interface{} `json:"data"`
+}
+
+// EventID retourne l'identifiant SSE de l'événement, "<époque>-<id>"
+func (ev JobEvent) EventID() string {
+	return ev.Epoch + "-" + strconv.FormatInt(ev.ID, 10)
+}
+
+// parseJobEventID découpe un identifiant produit par EventID
+func parseJobEventID(v string) (epoch string, id int64, ok bool) {
+	epoch, seq, found := strings.Cut(v, "-")
+	if !found || epoch == "" {
+		return "", 0, false
+	}
+	id, err := strconv.ParseInt(seq, 10, 64)
+	if err != nil || id < 0 {
+		return "", 0, false
+	}
+	return epoch, id, true
+}
+
+// JobProgress résume l'avancement d'un job bulk
+type JobProgress struct {
+	JobID           string         `json:"jobId"`
+	Status          string         `json:"status"`
+	TotalEmails     int            `json:"totalEmails"`
+	ProcessedEmails int            `json:"processedEmails"`
+	ErrorCount      int            `json:"errorCount"`
+	Tallies         map[string]int `json:"tallies"`
+	ETASeconds      *int64         `json:"etaSeconds,omitempty"`
+	// Resync indique que des événements ont été perdus depuis le Last-Event-ID
+	Resync bool `json:"resync,omitempty"`
+}
+
+type jobStream struct {
+	mu          sync.Mutex
+	epoch       string
+	nextID      int64
+	history     []JobEvent
+	subscribers map[chan JobEvent]struct{}
+	finished    bool
+}
+
+var (
+	jobStreamsMu sync.Mutex
+	jobStreams   = map[string]*jobStream{}
+)
+
+func getJobStream(jobID string) *jobStream {
+	jobStreamsMu.Lock()
+	defer jobStreamsMu.Unlock()
+	s, ok := jobStreams[jobID]
+	if !ok {
+		epoch := strconv.FormatInt(time.Now().UnixNano(), 36)
+		s = &jobStream{epoch: epoch, nextID: 1, subscribers: map[chan JobEvent]struct{}{}}
+		jobStreams[jobID] = s
+	}
+	return s
+}
+
+func lookupJobStream(jobID string) *jobStream {
+	jobStreamsMu.Lock()
+	defer jobStreamsMu.Unlock()
+	return jobStreams[jobID]
+}
+
+// PublishJobEvent ajoute un événement à l'historique du job, le diffuse aux
+// abonnés et le transmet aux autres processus (voir job_event_relay.go)
+func PublishJobEvent(jobID, typ string, data interface{}) {
+	publishLocalJobEvent(jobID, typ, data)
+	notifyJobEvent(jobID, typ, data)
+}
+
+// OpenJobStream crée le flux d'un job actif traité par un autre processus, pour
+// que ses événements relayés soient conservés et diffusés ici
+func OpenJobStream(jobID string) {
+	getJobStream(jobID)
+}
+
+func publishLocalJobEvent(jobID, typ string, data interface{}) {
+	s := getJobStream(jobID)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ev := JobEvent{ID: s.nextID, Epoch: s.epoch, Type: typ, Data: data}
+	s.nextID++
+	s.history = append(s.history, ev)
+	if len(s.history) > jobEventHistorySize {
+		s.history = s.history[len(s.history)-jobEventHistorySize:]
+	}
+	for ch := range s.subscribers {
+		select {
+		case ch <- ev:
+		default:
+			// Abonné trop lent: on le déconnecte, il reprendra avec Last-Event-ID
+			delete(s.subscribers, ch)
+			close(ch)
+		}
+	}
+	if typ == JobEventDone {
+		s.finished = true
+		for ch := range s.subscribers {
+			delete(s.subscribers, ch)
+			close(ch)
+		}
+		time.AfterFunc(jobEventRetention, func() {
+			jobStreamsMu.Lock()
+			delete(jobStreams, jobID)
+			jobStreamsMu.Unlock()
+		})
+	}
+}
+
+// SubscribeJobEvents retourne les événements postérieurs à lastEventID (un
+// identifiant EventID, vide pour une première connexion) encore en mémoire, puis
+// un canal pour les suivants. missed vaut true si une partie de l'historique
+// demandé n'est plus disponible, notamment quand lastEventID vient d'un autre flux
+// (redémarrage de l'API, reconnexion sur un autre processus): tout l'historique du
+// flux courant est alors rejoué. Le canal est nil si le job n'a pas de flux actif
+// (job terminé depuis longtemps ou traité par un autre processus).
+func SubscribeJobEvents(jobID string, lastEventID string) (replay []JobEvent, ch chan JobEvent, missed bool, cancel func()) {
+	cancel = func() {}
+	s := lookupJobStream(jobID)
+	if s == nil {
+		return nil, nil, lastEventID != "", cancel
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var after int64
+	if lastEventID != "" {
+		epoch, id, ok := parseJobEventID(lastEventID)
+		switch {
+		case !ok || epoch != s.epoch || id >= s.nextID:
+			missed = true
+		case len(s.history) > 0 && s.history[0].ID > id+1:
+			missed = true
+			after = id
+		default:
+			after = id
+		}
+	}
+	for _, ev := range s.history {
+		if ev.ID > after {
+			replay = append(replay, ev)
+		}
+	}
+	if s.finished {
+		return replay, nil, missed, cancel
+	}
+	ch = make(chan JobEvent, 256)
+	s.subscribers[ch] = struct{}{}
+	cancel = func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if _, ok := s.subscribers[ch]; ok {
+			delete(s.subscribers, ch)
+			close(ch)
+		}
+	}
+	return replay, ch, missed, cancel
+}
+
+// GetJobProgress calcule l'avancement d'un job à partir de la base
+func GetJobProgress(jobID string) (JobProgress, error) {
+	job, err := GetBulkJobByID(jobID)
+	if err != nil {
+		return JobProgress{}, err
+	}
+	tallies, err := GetJobStatusTallies(jobID)
+	if err != nil {
+		return JobProgress{}, err
+	}
+	return buildJobProgress(job, tallies), nil
+}
+
+// GetJobStatusTallies compte les résultats d'un job par statut
+func GetJobStatusTallies(jobID string) (map[string]int, error) {
+	db := infra.GetDB()
+	var rows []struct {
+		Status string
+		Count  int
+	}
+	err := db.Model(&model.EmailResult{}).
+		Select("status, count(*) as count").
+		Where("job_id = ?", jobID).Where(resultNotBlank).
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	tallies := map[string]int{}
+	for _, r := range rows {
+		tallies[r.Status] = r.Count
+	}
+	return tallies, nil
+}
+
+// snapshot copie la progression pour qu'elle puisse être diffusée sans partage de map
+func (p JobProgress) snapshot() JobProgress {
+	tallies := make(map[string]int, len(p.Tallies))
+	for k, v := range p.Tallies {
+		tallies[k] = v
+	}
+	p.Tallies = tallies
+	return p
+}
+
+func buildJobProgress(job model.BulkJob, tallies map[string]int) JobProgress {
+	p := JobProgress{
+		JobID:           job.ID,
+		Status:          job.Status,
+		TotalEmails:     job.TotalEmails,
+		ProcessedEmails: job.ProcessedEmails,
+		ErrorCount:      job.ErrorCount,
+		Tallies:         tallies,
+	}
+	p.ETASeconds = estimateETA(job.StartedAt, job.ProcessedEmails, job.TotalEmails)
+	return p
+}
+
+// estimateETA extrapole le temps restant à partir du débit moyen depuis le démarrage
+func estimateETA(startedAt *time.Time, processed, total int) *int64 {
+	if startedAt == nil || processed == 0 || processed >= total {
+		return nil
+	}
+	elapsed := time.Since(*startedAt)
+	remaining := time.Duration(float64(elapsed) / float64(processed) * float64(total-processed))
+	secs := int64(remaining.Seconds())
+	return &secs
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestSubscribeJobEventsResume(t *testing.T) {
+	jobID := "test-events-resume"
+	t.Cleanup(func() {
+		jobStreamsMu.Lock()
+		delete(jobStreams, jobID)
+		jobStreamsMu.Unlock()
+	})
+	for i := 0; i < 3; i++ {
+		publishLocalJobEvent(jobID, JobEventProgress, i)
+	}
+	epoch := lookupJobStream(jobID).epoch
+
+	tests := []struct {
+		name        string
+		lastEventID string
+		replayed    int
+		missed      bool
+	}{
+		{"première connexion", "", 3, false},
+		{"reprise dans le même flux", epoch + "-2", 1, false},
+		{"à jour", epoch + "-3", 0, false},
+		// Identifiant d'un flux précédent (redémarrage de l'API, autre processus)
+		{"autre époque", "0-500", 3, true},
+		{"même époque, numéro inconnu", epoch + "-500", 3, true},
+		// Ancien format numérique ou identifiant illisible
+		{"sans époque", "2", 3, true},
+		{"illisible", epoch + "-x", 3, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			replay, ch, missed, cancel := SubscribeJobEvents(jobID, tt.lastEventID)
+			defer cancel()
+			if ch == nil {
+				t.Fatal("pas de canal pour un flux actif")
+			}
+			if len(replay) != tt.replayed || missed != tt.missed {
+				t.Errorf("replay = %d, missed = %v; want %d, %v", len(replay), missed, tt.replayed, tt.missed)
+			}
+		})
+	}
+}
+
+func TestSubscribeJobEventsTrimmedHistory(t *testing.T) {
+	jobID := "test-events-trimmed"
+	t.Cleanup(func() {
+		jobStreamsMu.Lock()
+		delete(jobStreams, jobID)
+		jobStreamsMu.Unlock()
+	})
+	for i := 0; i < jobEventHistorySize+10; i++ {
+		publishLocalJobEvent(jobID, JobEventProgress, i)
+	}
+	epoch := lookupJobStream(jobID).epoch
+
+	// L'événement 5 est sorti de l'historique: resync, puis ce qui reste
+	replay, _, missed, cancel := SubscribeJobEvents(jobID, epoch+"-5")
+	defer cancel()
+	if !missed || len(replay) != jobEventHistorySize {
+		t.Errorf("replay = %d, missed = %v; want %d, true", len(replay), missed, jobEventHistorySize)
+	}
+	if got := replay[0].EventID(); got != epoch+"-"+strconv.Itoa(11) {
+		t.Errorf("premier événement rejoué = %s, want %s-11", got, epoch)
+	}
+}
+
+func TestSubscribeJobEventsNoStream(t *testing.T) {
+	// Job sans flux dans ce processus: instantané depuis la base si le client reprend
+	if _, ch, missed, _ := SubscribeJobEvents("test-events-none", ""); ch != nil || missed {
+		t.Errorf("première connexion: ch = %v, missed = %v", ch, missed)
+	}
+	if _, _, missed, _ := SubscribeJobEvents("test-events-none", "abc-12"); !missed {
+		t.Error("reprise sans flux: missed = false")
+	}
+}
@@ -0,0 +1,292 @@
+package service
+
+import (
+	"backend/internal/infra"
+	"backend/internal/model"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrJobNotFound est retourné quand le job n'existe pas
+var ErrJobNotFound = errors.New("job not found")
+
+// Nom affiché d'un job: le nom donné par l'utilisateur, sinon le fichier uploadé
+const jobNameExpr = "COALESCE(NULLIF(display_name, ''), file_name)"
+
+// Colonnes de tri autorisées pour la liste des jobs (paramètre -> colonne)
+var jobSortColumns = map[string]string{
+	"uploadedAt":      "uploaded_at",
+	"finishedAt":      "finished_at",
+	"fileName":        "file_name",
+	"name":            jobNameExpr,
+	"status":          "status",
+	"totalEmails":     "total_emails",
+	"processedEmails": "processed_emails",
+}
+
+// JobListQuery regroupe la pagination, le tri et les filtres de la liste des jobs
+type JobListQuery struct {
+	Page     int
+	PageSize int
+	Sort     string // clé de jobSortColumns, uploadedAt par défaut
+	Desc     bool
+	Statuses []string
+	Kind     string
+	Search   string // sous-chaîne du nom affiché ou du nom de fichier
+	Tag      string
+	Owner    string
+	From     *time.Time // uploadé à partir de
+	To       *time.Time // uploadé avant
+}
+
+// JobSummary est un job de la liste avec ses compteurs par statut
+type JobSummary struct {
+	model.BulkJob
+	Tags     []string       `json:"tags"`
+	Progress float64        `json:"progress"`
+	Counts   map[string]int `json:"counts"` // résultats par statut (verify) ou emails trouvés (extract)
+}
+
+// ListBulkJobs retourne une page de jobs et le nombre total de jobs correspondant aux filtres
+func ListBulkJobs(query JobListQuery) ([]JobSummary, int64, error) {
+	db := infra.GetDB()
+	q := db.Model(&model.BulkJob{})
+	if len(query.Statuses) > 0 {
+		q = q.Where("status IN ?", query.Statuses)
+	}
+	if query.Kind != "" {
+		q = q.Where("kind = ?", query.Kind)
+	}
+	if s := strings.TrimSpace(query.Search); s != "" {
+		q = q.Where("file_name ILIKE ? OR display_name ILIKE ?", "%"+s+"%", "%"+s+"%")
+	}
+	if query.Tag != "" {
+		tag, _ := json.Marshal([]string{query.Tag})
+		q = q.Where("tags @> ?::jsonb", string(tag))
+	}
+	if query.Owner != "" {
+		q = q.Where("owner = ?", query.Owner)
+	}
+	if query.From != nil {
+		q = q.Where("uploaded_at >= ?", *query.From)
+	}
+	if query.To != nil {
+		q = q.Where("uploaded_at < ?", *query.To)
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	column, ok := jobSortColumns[query.Sort]
+	if !ok {
+		column = "uploaded_at"
+	}
+	order := column + " ASC"
+	if query.Desc {
+		order = column + " DESC NULLS LAST"
+	}
+	var jobs []model.BulkJob
+	err := q.Order(order).Order("id").
+		Limit(query.PageSize).
+		Offset((query.Page - 1) * query.PageSize).
+		Find(&jobs).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	counts, err := jobCounts(jobs)
+	if err != nil {
+		return nil, 0, err
+	}
+	summaries := make([]JobSummary, len(jobs))
+	for i, job := range jobs {
+		summaries[i] = summarizeJob(job, counts[job.ID])
+	}
+	return summaries, total, nil
+}
+
+// jobCounts compte en deux requêtes les résultats par statut des jobs de la page
+func jobCounts(jobs []model.BulkJob) (map[string]map[string]int, error) {
+	db := infra.GetDB()
+	counts := map[string]map[string]int{}
+	var verifyIDs, extractIDs []string
+	for _, job := range jobs {
+		counts[job.ID] = map[string]int{}
+		if job.Kind == model.WorkKindExtract {
+			extractIDs = append(extractIDs, job.ID)
+		} else {
+			verifyIDs = append(verifyIDs, job.ID)
+		}
+	}
+
+	var rows []struct {
+		JobID  string
+		Status string
+		Count  int
+	}
+	if len(verifyIDs) > 0 {
+		err := db.Model(&model.EmailResult{}).
+			Select("job_id, status, count(*) as count").
+			Where("job_id IN ?", verifyIDs).Where(resultNotBlank).
+			Group("job_id, status").
+			Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(extractIDs) > 0 {
+		var extractRows []struct {
+			JobID  string
+			Status string
+			Count  int
+		}
+		err := db.Model(&model.ExtractResult{}).
+			Select("job_id, 'emails' as status, count(*) as count").
+			Where("job_id IN ?", extractIDs).
+			Group("job_id").
+			Scan(&extractRows).Error
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, extractRows...)
+	}
+	for _, r := range rows {
+		counts[r.JobID][resultStatus(r.Status)] += r.Count
+	}
+	return counts, nil
+}
+
+func summarizeJob(job model.BulkJob, counts map[string]int) JobSummary {
+	summary := JobSummary{BulkJob: job, Tags: jobTags(job), Counts: counts}
+	if summary.Counts == nil {
+		summary.Counts = map[string]int{}
+	}
+	if job.TotalEmails > 0 {
+		summary.Progress = float64(job.ProcessedEmails) * 100 / float64(job.TotalEmails)
+	}
+	return summary
+}
+
+// jobDisplayName retourne le nom affiché d'un job (voir jobNameExpr)
+func jobDisplayName(job model.BulkJob) string {
+	if job.DisplayName != "" {
+		return job.DisplayName
+	}
+	return job.FileName
+}
+
+func jobTags(job model.BulkJob) []string {
+	tags := []string{}
+	if job.Tags != "" {
+		json.Unmarshal([]byte(job.Tags), &tags)
+	}
+	return tags
+}
+
+// JobUpdate contient les champs modifiables d'un job (nil = inchangé)
+type JobUpdate struct {
+	Name     *string   `json:"name"`
+	Tags     *[]string `json:"tags"`
+	Priority *string   `json:"priority"` // low, normal, high, urgent
+}
+
+// UpdateBulkJob renomme un job, remplace ses tags et/ou change sa priorité
+// (prise en compte dès la prochaine réservation d'un worker). Le nom du fichier
+// uploadé est conservé; un nom vide rétablit son affichage.
+func UpdateBulkJob(jobId string, update JobUpdate) (JobSummary, error) {
+	db := infra.GetDB()
+	fields := map[string]interface{}{}
+	if update.Name != nil {
+		fields["display_name"] = strings.TrimSpace(*update.Name)
+	}
+	if update.Tags != nil {
+		tags := []string{}
+		seen := map[string]bool{}
+		for _, t := range *update.Tags {
+			if t = strings.TrimSpace(t); t != "" && !seen[t] {
+				seen[t] = true
+				tags = append(tags, t)
+			}
+		}
+		encoded, _ := json.Marshal(tags)
+		fields["tags"] = string(encoded)
+	}
+	if update.Priority != nil {
+		priority, err := ParsePriority(*update.Priority)
+		if err != nil {
+			return JobSummary{}, err
+		}
+		fields["priority"] = priority
+	}
+	if len(fields) > 0 {
+		res := db.Model(&model.BulkJob{}).Where("id = ?", jobId).Updates(fields)
+		if res.Error != nil {
+			return JobSummary{}, res.Error
+		}
+		if res.RowsAffected == 0 {
+			return JobSummary{}, ErrJobNotFound
+		}
+	}
+	job, err := GetBulkJobByID(jobId)
+	if err != nil {
+		return JobSummary{}, ErrJobNotFound
+	}
+	counts, err := jobCounts([]model.BulkJob{job})
+	if err != nil {
+		return JobSummary{}, err
+	}
+	return summarizeJob(job, counts[job.ID]), nil
+}
+
+// DeleteBulkJob supprime un job terminé avec ses résultats, ses éléments de file,
+// ses re-vérifications et leurs planifications. Un job en cours doit d'abord être annulé.
+func DeleteBulkJob(jobId string) error {
+	db := infra.GetDB()
+	job, err := GetBulkJobByID(jobId)
+	if err != nil {
+		return ErrJobNotFound
+	}
+
+	// Le job et ses re-vérifications (récursivement)
+	ids := []string{job.ID}
+	for frontier := ids; len(frontier) > 0; {
+		var children []string
+		if err := db.Model(&model.BulkJob{}).Where("parent_job_id IN ?", frontier).Pluck("id", &children).Error; err != nil {
+			return err
+		}
+		ids = append(ids, children...)
+		frontier = children
+	}
+	var active int64
+	if err := db.Model(&model.BulkJob{}).
+		Where("id IN ? AND (status IN ? OR ingesting = ?)", ids, []string{model.JobStatusQueued, model.JobStatusProcessing, model.JobStatusPaused}, true).
+		Count(&active).Error; err != nil {
+		return err
+	}
+	if active > 0 {
+		return ErrJobActive
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for _, m := range []interface{}{&model.EmailResult{}, &model.ExtractResult{}, &model.ExtractContact{}, &model.RobotsSkip{}, &model.ExtractOrganization{}, &model.WorkItem{}, &model.StatusChange{}, &model.Schedule{}} {
+			if err := tx.Where("job_id IN ?", ids).Delete(m).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Where("id IN ?", ids).Delete(&model.BulkJob{}).Error
+	})
+	if err != nil {
+		return err
+	}
+	if job.SpoolPath != "" {
+		os.Remove(job.SpoolPath)
+	}
+	return nil
+}
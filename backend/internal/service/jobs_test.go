@@ -0,0 +1,43 @@
+package service
+
+import (
+	"backend/internal/model"
+	"testing"
+)
+
+// Renommer un job ne touche pas au nom du fichier uploadé
+func TestUpdateBulkJobRenameKeepsFileName(t *testing.T) {
+	testDB(t)
+	job := createTestJob(t, model.BulkJob{FileName: "leads-2024.csv"}, "a@example.com")
+
+	name := "  Spring campaign  "
+	summary, err := UpdateBulkJob(job.ID, JobUpdate{Name: &name})
+	if err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+	if summary.FileName != "leads-2024.csv" || summary.DisplayName != "Spring campaign" {
+		t.Fatalf("fileName = %q, displayName = %q", summary.FileName, summary.DisplayName)
+	}
+	if got := jobDisplayName(summary.BulkJob); got != "Spring campaign" {
+		t.Errorf("jobDisplayName = %q", got)
+	}
+
+	// Recherche sur le nom affiché comme sur le nom de fichier
+	for _, q := range []string{"spring", "leads-2024"} {
+		jobs, _, err := ListBulkJobs(JobListQuery{Page: 1, PageSize: 50, Search: q})
+		found := false
+		for _, j := range jobs {
+			found = found || j.ID == job.ID
+		}
+		if err != nil || !found {
+			t.Errorf("search %q: job not found (%v)", q, err)
+		}
+	}
+
+	// Un nom vide rétablit le nom du fichier
+	empty := ""
+	summary, err = UpdateBulkJob(job.ID, JobUpdate{Name: &empty})
+	if err != nil || summary.DisplayName != "" || jobDisplayName(summary.BulkJob) != "leads-2024.csv" {
+		t.Fatalf("reset: %+v, %v", summary.BulkJob, err)
+	}
+}
@@ -0,0 +1,771 @@
+package service
+
+import (
+	"backend/internal/config"
+	"backend/internal/infra"
+	"backend/internal/model"
+	"backend/internal/util"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Taille des lots d'insertion des membres
+const listBatchSize = 1000
+
+var (
+	ErrListNotFound     = errors.New("list not found")
+	ErrListNameRequired = errors.New("list name is required")
+	ErrInvalidDedupe    = errors.New("dedupe must be exact or canonical")
+)
+
+// ListView est une liste avec son nombre de membres et leur répartition par statut
+type ListView struct {
+	model.List
+	Members int            `json:"members"`
+	Counts  map[string]int `json:"counts"` // unknown = jamais vérifié ou vérification en échec
+}
+
+// ListQuery pagine et filtre les listes
+type ListQuery struct {
+	Page     int
+	PageSize int
+	Search   string // sous-chaîne du nom
+	Owner    string
+}
+
+// ListUpdate contient les champs modifiables d'une liste (nil = inchangé)
+type ListUpdate struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+}
+
+// ListImportReport résume l'ajout d'adresses à une liste
+type ListImportReport struct {
+	Rows       int `json:"rows"`       // valeurs lues
+	Added      int `json:"added"`      // nouveaux membres
+	Duplicates int `json:"duplicates"` // déjà dans la liste
+	Invalid    int `json:"invalid"`    // syntaxe invalide
+}
+
+// MemberFilter sélectionne des membres (segmentation, consultation, vérification)
+type MemberFilter struct {
+	Statuses       []string `json:"statuses"` // unknown = jamais vérifié
+	MinScore       *int     `json:"minScore"`
+	MaxScore       *int     `json:"maxScore"`
+	Domains        []string `json:"domains"`
+	ExcludeDomains []string `json:"excludeDomains"`
+	RoleBased      *bool    `json:"roleBased"`
+	Disposable     *bool    `json:"disposable"`
+	CatchAll       *bool    `json:"catchAll"`
+	Free           *bool    `json:"free"`
+	Search         string   `json:"search"` // sous-chaîne de l'adresse
+}
+
+// apply ajoute les conditions du filtre à une requête sur list_members
+func (f MemberFilter) apply(q *gorm.DB) *gorm.DB {
+	if len(f.Statuses) > 0 {
+		statuses := append([]string{}, f.Statuses...)
+		for _, s := range f.Statuses {
+			if s == statusUnknown {
+				statuses = append(statuses, "")
+				break
+			}
+		}
+		q = q.Where("status IN ?", statuses)
+	}
+	if f.MinScore != nil {
+		q = q.Where("score >= ?", *f.MinScore)
+	}
+	if f.MaxScore != nil {
+		q = q.Where("score <= ?", *f.MaxScore)
+	}
+	if len(f.Domains) > 0 {
+		q = q.Where("domain IN ?", lowerAll(f.Domains))
+	}
+	if len(f.ExcludeDomains) > 0 {
+		q = q.Where("domain NOT IN ?", lowerAll(f.ExcludeDomains))
+	}
+	flags := []struct {
+		column string
+		value  *bool
+	}{
+		{"is_role_based", f.RoleBased},
+		{"is_disposable", f.Disposable},
+		{"is_catch_all", f.CatchAll},
+		{"is_free", f.Free},
+	}
+	for _, flag := range flags {
+		if flag.value != nil {
+			q = q.Where(flag.column+" = ?", *flag.value)
+		}
+	}
+	if s := strings.TrimSpace(f.Search); s != "" {
+		q = q.Where("email ILIKE ?", "%"+s+"%")
+	}
+	return q
+}
+
+func lowerAll(values []string) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = strings.ToLower(strings.TrimSpace(v))
+	}
+	return out
+}
+
+// newListMember prépare un membre; false si la syntaxe de l'adresse est invalide
+func newListMember(listId, value string) (model.ListMember, bool) {
+	email := strings.ToLower(strings.TrimSpace(value))
+	if !looksLikeEmail(email) {
+		return model.ListMember{}, false
+	}
+	return model.ListMember{
+		ListID:       listId,
+		Email:        email,
+		Canonical:    canonicalEmail(email),
+		Domain:       emailDomain(email),
+		IsRoleBased:  isRoleBased(email),
+		IsDisposable: isDisposable(email),
+		IsFree:       isFreeEmail(email),
+	}, true
+}
+
+// insertMembers ajoute des membres en ignorant les adresses déjà présentes;
+// retourne le nombre de membres ajoutés
+func insertMembers(tx *gorm.DB, members []model.ListMember) (int, error) {
+	if len(members) == 0 {
+		return 0, nil
+	}
+	res := tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&members, listBatchSize)
+	return int(res.RowsAffected), res.Error
+}
+
+func newList(name, description, source, owner string) (model.List, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return model.List{}, ErrListNameRequired
+	}
+	return model.List{Name: name, Description: strings.TrimSpace(description), Source: source, Owner: owner}, nil
+}
+
+// CreateList crée une liste, éventuellement avec ses premières adresses
+func CreateList(name, description, owner string, emails []string) (ListView, ListImportReport, error) {
+	list, err := newList(name, description, model.ListSourceManual, owner)
+	if err != nil {
+		return ListView{}, ListImportReport{}, err
+	}
+	var report ListImportReport
+	err = infra.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&list).Error; err != nil {
+			return err
+		}
+		report, err = addMembers(tx, list.ID, emails)
+		return err
+	})
+	if err != nil {
+		return ListView{}, ListImportReport{}, err
+	}
+	view, err := GetList(list.ID)
+	return view, report, err
+}
+
+// AddListMembers ajoute des adresses à une liste existante
+func AddListMembers(listId string, emails []string) (ListImportReport, error) {
+	if _, err := getList(listId); err != nil {
+		return ListImportReport{}, err
+	}
+	var report ListImportReport
+	err := infra.GetDB().Transaction(func(tx *gorm.DB) error {
+		var err error
+		report, err = addMembers(tx, listId, emails)
+		return err
+	})
+	return report, err
+}
+
+func addMembers(tx *gorm.DB, listId string, emails []string) (ListImportReport, error) {
+	var report ListImportReport
+	members := make([]model.ListMember, 0, len(emails))
+	for _, e := range emails {
+		if strings.TrimSpace(e) == "" {
+			continue
+		}
+		report.Rows++
+		m, ok := newListMember(listId, e)
+		if !ok {
+			report.Invalid++
+			continue
+		}
+		members = append(members, m)
+	}
+	added, err := insertMembers(tx, members)
+	report.Added = added
+	report.Duplicates = len(members) - added
+	return report, err
+}
+
+// RemoveListMembers retire des adresses d'une liste
+func RemoveListMembers(listId string, emails []string) (int64, error) {
+	if _, err := getList(listId); err != nil {
+		return 0, err
+	}
+	res := infra.GetDB().Where("list_id = ? AND email IN ?", listId, lowerAll(emails)).Delete(&model.ListMember{})
+	return res.RowsAffected, res.Error
+}
+
+// ImportList crée une liste depuis la colonne email d'un fichier spoolé. Les
+// adresses sont lues au fil de l'eau et ajoutées par lots, chacun dans sa propre
+// transaction; la liste est supprimée si l'import échoue en cours de route.
+func ImportList(name, description, owner, path, sheet string, column int) (ListView, ListImportReport, error) {
+	list, err := newList(name, description, model.ListSourceUpload, owner)
+	if err != nil {
+		return ListView{}, ListImportReport{}, err
+	}
+	reader, err := openSpooledList(path, sheet)
+	if err != nil {
+		return ListView{}, ListImportReport{}, err
+	}
+	defer reader.Close()
+
+	db := infra.GetDB()
+	if err := db.Create(&list).Error; err != nil {
+		return ListView{}, ListImportReport{}, err
+	}
+	report, err := importMembers(db, list.ID, reader, column)
+	if err != nil {
+		if cleanup := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("list_id = ?", list.ID).Delete(&model.ListMember{}).Error; err != nil {
+				return err
+			}
+			return tx.Where("id = ?", list.ID).Delete(&model.List{}).Error
+		}); cleanup != nil {
+			log.Printf("lists: cannot remove failed import %s: %v", list.ID, cleanup)
+		}
+		return ListView{}, ListImportReport{}, err
+	}
+	view, err := GetList(list.ID)
+	return view, report, err
+}
+
+// importMembers ajoute à la liste les adresses de la colonne, par lots de listBatchSize
+func importMembers(db *gorm.DB, listId string, reader util.ListReader, column int) (ListImportReport, error) {
+	maxRows := config.Load().MaxUploadRows
+	var report ListImportReport
+	batch := make([]string, 0, listBatchSize)
+	flush := func() error {
+		var r ListImportReport
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var err error
+			r, err = addMembers(tx, listId, batch)
+			return err
+		})
+		report.Rows += r.Rows
+		report.Added += r.Added
+		report.Duplicates += r.Duplicates
+		report.Invalid += r.Invalid
+		batch = batch[:0]
+		return err
+	}
+	for rows := 0; ; rows++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil && !util.IsRowError(err) {
+			return report, err
+		}
+		if err != nil || column >= len(record) {
+			continue
+		}
+		if rows >= maxRows {
+			return report, fmt.Errorf("%w (max %d)", ErrRowLimit, maxRows)
+		}
+		batch = append(batch, record[column])
+		if len(batch) >= listBatchSize {
+			if err := flush(); err != nil {
+				return report, err
+			}
+		}
+	}
+	return report, flush()
+}
+
+// CreateListFromJob crée une liste depuis les résultats d'un job: résultats de
+// vérification (statut et indicateurs conservés, filtrés par statut) ou emails
+// trouvés par une extraction
+func CreateListFromJob(jobId, name, owner string, statuses []string) (ListView, ListImportReport, error) {
+	job, err := GetBulkJobByID(jobId)
+	if err != nil {
+		return ListView{}, ListImportReport{}, ErrJobNotFound
+	}
+	if strings.TrimSpace(name) == "" {
+		name = jobDisplayName(job)
+	}
+	list, err := newList(name, "", model.ListSourceJob, owner)
+	if err != nil {
+		return ListView{}, ListImportReport{}, err
+	}
+	list.SourceJobID = &job.ID
+
+	var report ListImportReport
+	err = infra.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&list).Error; err != nil {
+			return err
+		}
+		collect := func(members []model.ListMember) error {
+			added, err := insertMembers(tx, members)
+			report.Added += added
+			report.Duplicates += len(members) - added
+			return err
+		}
+
+		// Les adresses de la liste de suppression ne sont pas reprises
+		if job.Kind == model.WorkKindExtract {
+			var batch []model.ExtractResult
+			return tx.Model(&model.ExtractResult{}).Where("job_id = ? AND suppressed = ?", job.ID, false).
+				FindInBatches(&batch, listBatchSize, func(_ *gorm.DB, _ int) error {
+					members := make([]model.ListMember, 0, len(batch))
+					for _, r := range batch {
+						report.Rows++
+						if m, ok := newListMember(list.ID, r.Email); ok {
+							members = append(members, m)
+						} else {
+							report.Invalid++
+						}
+					}
+					return collect(members)
+				}).Error
+		}
+
+		q := tx.Model(&model.EmailResult{}).Where("job_id = ? AND status NOT IN ?", job.ID, []string{string(StatusDuplicate), string(StatusSuppressed)}).Where(resultNotBlank)
+		if len(statuses) > 0 {
+			q = MemberFilter{Statuses: statuses}.apply(q)
+		}
+		var batch []model.EmailResult
+		return q.Order("row_index ASC").FindInBatches(&batch, listBatchSize, func(_ *gorm.DB, _ int) error {
+			members := make([]model.ListMember, 0, len(batch))
+			for _, r := range batch {
+				report.Rows++
+				m, ok := newListMember(list.ID, r.Email)
+				if !ok {
+					report.Invalid++
+					continue
+				}
+				m.Status = r.Status
+				m.Reason = r.Reason
+				m.Score = r.Score
+				m.IsCatchAll = r.IsCatchAll
+				checkedAt := r.CheckedAt
+				m.LastVerifiedAt = &checkedAt
+				m.LastJobID = &job.ID
+				members = append(members, m)
+			}
+			return collect(members)
+		}).Error
+	})
+	if err != nil {
+		return ListView{}, ListImportReport{}, err
+	}
+	view, err := GetList(list.ID)
+	return view, report, err
+}
+
+// copyMembers copie dans listId les membres sélectionnés par src, une adresse
+// par clé de dédoublonnage (email ou canonical), la plus récemment vérifiée d'abord
+func copyMembers(tx *gorm.DB, listId string, src *gorm.DB, key string) error {
+	sub := src.Select("DISTINCT ON (" + key + ") *").
+		Order(key).Order("last_verified_at DESC NULLS LAST").Order("id")
+	return tx.Exec(`
+		INSERT INTO list_members (list_id, email, canonical, domain, status, reason, score,
+			is_role_based, is_disposable, is_catch_all, is_free, last_verified_at, last_job_id, created_at)
+		SELECT ?, email, canonical, domain, status, reason, score,
+			is_role_based, is_disposable, is_catch_all, is_free, last_verified_at, last_job_id, now()
+		FROM (?) AS src
+		ON CONFLICT (list_id, email) DO NOTHING`, listId, sub).Error
+}
+
+// deriveList crée une liste à partir des membres sélectionnés par build
+func deriveList(list model.List, key string, build func(tx *gorm.DB) *gorm.DB) (ListView, error) {
+	err := infra.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&list).Error; err != nil {
+			return err
+		}
+		return copyMembers(tx, list.ID, build(tx.Model(&model.ListMember{})), key)
+	})
+	if err != nil {
+		return ListView{}, err
+	}
+	return GetList(list.ID)
+}
+
+// MergeLists crée une liste réunissant les membres de plusieurs listes,
+// dédoublonnés par adresse (exact) ou par boîte (canonical: +tag, points Gmail)
+func MergeLists(name, owner string, listIds []string, dedupe string) (ListView, error) {
+	key := "email"
+	switch dedupe {
+	case "", "exact":
+	case "canonical":
+		key = "canonical"
+	default:
+		return ListView{}, ErrInvalidDedupe
+	}
+	for _, id := range listIds {
+		if _, err := getList(id); err != nil {
+			return ListView{}, err
+		}
+	}
+	list, err := newList(name, "", model.ListSourceMerge, owner)
+	if err != nil {
+		return ListView{}, err
+	}
+	return deriveList(list, key, func(q *gorm.DB) *gorm.DB {
+		return q.Where("list_id IN ?", listIds)
+	})
+}
+
+// SubtractList crée une liste des membres de listId absents de otherId
+// (comparaison par boîte, ex. liste moins désinscrits)
+func SubtractList(listId, otherId, name string) (ListView, error) {
+	base, err := getList(listId)
+	if err != nil {
+		return ListView{}, err
+	}
+	other, err := getList(otherId)
+	if err != nil {
+		return ListView{}, err
+	}
+	if strings.TrimSpace(name) == "" {
+		name = base.Name + " - " + other.Name
+	}
+	list, err := newList(name, "", model.ListSourceSubtract, base.Owner)
+	if err != nil {
+		return ListView{}, err
+	}
+	return deriveList(list, "email", func(q *gorm.DB) *gorm.DB {
+		return q.Where("list_id = ?", listId).
+			Where("canonical NOT IN (SELECT canonical FROM list_members WHERE list_id = ?)", otherId)
+	})
+}
+
+// SegmentList crée une liste des membres de listId qui correspondent au filtre
+func SegmentList(listId, name string, filter MemberFilter) (ListView, error) {
+	base, err := getList(listId)
+	if err != nil {
+		return ListView{}, err
+	}
+	list, err := newList(name, "", model.ListSourceSegment, base.Owner)
+	if err != nil {
+		return ListView{}, err
+	}
+	return deriveList(list, "email", func(q *gorm.DB) *gorm.DB {
+		return filter.apply(q.Where("list_id = ?", listId))
+	})
+}
+
+func getList(id string) (model.List, error) {
+	var list model.List
+	if err := infra.GetDB().Where("id = ?", id).First(&list).Error; err != nil {
+		return list, ErrListNotFound
+	}
+	return list, nil
+}
+
+// GetList retourne une liste avec ses compteurs
+func GetList(id string) (ListView, error) {
+	list, err := getList(id)
+	if err != nil {
+		return ListView{}, err
+	}
+	counts, err := listCounts([]string{id})
+	if err != nil {
+		return ListView{}, err
+	}
+	return listView(list, counts[id]), nil
+}
+
+func listView(list model.List, counts map[string]int) ListView {
+	view := ListView{List: list, Counts: counts}
+	if view.Counts == nil {
+		view.Counts = map[string]int{}
+	}
+	for _, n := range view.Counts {
+		view.Members += n
+	}
+	return view
+}
+
+// listCounts compte en une requête les membres par statut des listes
+func listCounts(ids []string) (map[string]map[string]int, error) {
+	counts := map[string]map[string]int{}
+	if len(ids) == 0 {
+		return counts, nil
+	}
+	var rows []struct {
+		ListID string
+		Status string
+		Count  int
+	}
+	err := infra.GetDB().Model(&model.ListMember{}).
+		Select("list_id, status, count(*) as count").
+		Where("list_id IN ?", ids).
+		Group("list_id, status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		if counts[r.ListID] == nil {
+			counts[r.ListID] = map[string]int{}
+		}
+		counts[r.ListID][resultStatus(r.Status)] += r.Count
+	}
+	return counts, nil
+}
+
+// ListLists retourne une page de listes, les plus récentes d'abord
+func ListLists(query ListQuery) ([]ListView, int64, error) {
+	q := infra.GetDB().Model(&model.List{})
+	if s := strings.TrimSpace(query.Search); s != "" {
+		q = q.Where("name ILIKE ?", "%"+s+"%")
+	}
+	if query.Owner != "" {
+		q = q.Where("owner = ?", query.Owner)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var lists []model.List
+	err := q.Order("created_at DESC").Order("id").
+		Limit(query.PageSize).Offset((query.Page - 1) * query.PageSize).
+		Find(&lists).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	ids := make([]string, len(lists))
+	for i, l := range lists {
+		ids[i] = l.ID
+	}
+	counts, err := listCounts(ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	views := make([]ListView, len(lists))
+	for i, l := range lists {
+		views[i] = listView(l, counts[l.ID])
+	}
+	return views, total, nil
+}
+
+// UpdateList renomme une liste ou change sa description
+func UpdateList(id string, update ListUpdate) (ListView, error) {
+	fields := map[string]interface{}{}
+	if update.Name != nil {
+		name := strings.TrimSpace(*update.Name)
+		if name == "" {
+			return ListView{}, ErrListNameRequired
+		}
+		fields["name"] = name
+	}
+	if update.Description != nil {
+		fields["description"] = strings.TrimSpace(*update.Description)
+	}
+	if len(fields) > 0 {
+		res := infra.GetDB().Model(&model.List{}).Where("id = ?", id).Updates(fields)
+		if res.Error != nil {
+			return ListView{}, res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ListView{}, ErrListNotFound
+		}
+	}
+	return GetList(id)
+}
+
+// DeleteList supprime une liste, ses membres et ses planifications; les jobs
+// de vérification de la liste sont conservés
+func DeleteList(id string) error {
+	if _, err := getList(id); err != nil {
+		return err
+	}
+	if active, err := listVerifying(id); err != nil || active {
+		if err == nil {
+			err = ErrJobActive
+		}
+		return err
+	}
+	return infra.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("list_id = ?", id).Delete(&model.ListMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("list_id = ?", id).Delete(&model.Schedule{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&model.List{}).Error
+	})
+}
+
+// ListListMembers retourne une page des membres d'une liste correspondant au filtre
+func ListListMembers(listId string, filter MemberFilter, page, pageSize int) ([]model.ListMember, int64, error) {
+	if _, err := getList(listId); err != nil {
+		return nil, 0, err
+	}
+	q := filter.apply(infra.GetDB().Model(&model.ListMember{}).Where("list_id = ?", listId))
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var members []model.ListMember
+	err := q.Order("id ASC").Limit(pageSize).Offset((page - 1) * pageSize).Find(&members).Error
+	return members, total, err
+}
+
+// listVerifying indique si une vérification de la liste est en cours
+func listVerifying(listId string) (bool, error) {
+	var running int64
+	err := infra.GetDB().Model(&model.BulkJob{}).
+		Where("list_id = ? AND status IN ?", listId, []string{model.JobStatusQueued, model.JobStatusProcessing, model.JobStatusPaused}).
+		Count(&running).Error
+	return running > 0, err
+}
+
+// ListVerifyOptions configure la vérification d'une liste
+type ListVerifyOptions struct {
+	Filter        MemberFilter // membres vérifiés (tous par défaut)
+	Priority      int
+	Owner         string
+	WebhookURL    string
+	WebhookSecret string
+	ScheduleID    *string
+}
+
+// VerifyList crée un job de vérification des membres d'une liste; chaque
+// résultat met à jour le membre (voir mergeIntoList). Le job est retourné
+// aussitôt: les membres sont lus et mis en file par lots en arrière-plan, avec
+// la même contre-pression qu'un fichier en cours d'ingestion, et la liste n'est
+// jamais chargée entière en mémoire.
+func VerifyList(listId string, opts ListVerifyOptions) (model.BulkJob, error) {
+	job, err := createListVerifyJob(listId, opts)
+	if err != nil {
+		return model.BulkJob{}, err
+	}
+	go runListIngestion(job.ID, listId, opts.Filter)
+	return job, nil
+}
+
+// createListVerifyJob crée le job en cours d'ingestion d'une vérification de liste
+func createListVerifyJob(listId string, opts ListVerifyOptions) (model.BulkJob, error) {
+	list, err := getList(listId)
+	if err != nil {
+		return model.BulkJob{}, err
+	}
+	if active, err := listVerifying(listId); err != nil || active {
+		if err == nil {
+			err = ErrJobActive
+		}
+		return model.BulkJob{}, err
+	}
+
+	var first []int64
+	if err := listMembersQuery(listId, opts.Filter).Limit(1).Pluck("id", &first).Error; err != nil {
+		return model.BulkJob{}, err
+	}
+	if len(first) == 0 {
+		return model.BulkJob{}, ErrNothingToReverify
+	}
+
+	owner := opts.Owner
+	if owner == "" {
+		owner = list.Owner
+	}
+	job := model.BulkJob{
+		Kind:          model.WorkKindVerify,
+		FileName:      list.Name,
+		Headers:       `["email"]`,
+		ListID:        &list.ID,
+		ScheduleID:    opts.ScheduleID,
+		UploadedAt:    time.Now(),
+		Status:        model.JobStatusQueued,
+		Ingesting:     true,
+		Priority:      opts.Priority,
+		Owner:         owner,
+		WebhookURL:    opts.WebhookURL,
+		WebhookSecret: opts.WebhookSecret,
+	}
+	err = infra.GetDB().Create(&job).Error
+	return job, err
+}
+
+func listMembersQuery(listId string, filter MemberFilter) *gorm.DB {
+	return filter.apply(infra.GetDB().Model(&model.ListMember{}).Where("list_id = ?", listId))
+}
+
+func runListIngestion(jobId, listId string, filter MemberFilter) {
+	if err := enqueueListMembers(jobId, listId, filter); err != nil {
+		log.Printf("ingest job %s: %v", jobId, err)
+		failIngestion(jobId, err)
+	}
+}
+
+// enqueueListMembers met en file les membres sélectionnés par lots, chacun dans sa
+// transaction avec le total du job, puis marque la lecture terminée. Un
+// redémarrage pendant la lecture fait échouer le job (voir resumeIngestions).
+func enqueueListMembers(jobId, listId string, filter MemberFilter) error {
+	job, err := GetBulkJobByID(jobId)
+	if err != nil {
+		return err
+	}
+	index := 0
+	var batch []model.ListMember
+	err = listMembersQuery(listId, filter).Order("id ASC").FindInBatches(&batch, listBatchSize, func(_ *gorm.DB, _ int) error {
+		rows := make([]SourceRow, len(batch))
+		for i, m := range batch {
+			rows[i] = SourceRow{Index: index, Value: m.Email, Columns: []string{m.Email}}
+			index++
+		}
+		return queueRowsBatch(job, rows)
+	}).Error
+	if err != nil {
+		return err
+	}
+	return finishQueueing(jobId)
+}
+
+// mergeIntoList enregistre le résultat d'une vérification de liste sur le membre;
+// un changement de statut d'une adresse déjà vérifiée est ajouté au rapport du job
+func mergeIntoList(tx *gorm.DB, jobId, listId string, m model.EmailResult) error {
+	var member model.ListMember
+	err := tx.Where("list_id = ? AND email = ?", listId, strings.ToLower(m.Email)).Limit(1).Find(&member).Error
+	if err != nil || member.ID == 0 {
+		// Membre retiré de la liste pendant la vérification
+		return err
+	}
+	if member.LastVerifiedAt != nil {
+		change := model.StatusChange{JobID: jobId, ListID: &listId}
+		if err := recordStatusChange(tx, change, member.Status, member.IsCatchAll, m); err != nil {
+			return err
+		}
+	}
+	checkedAt := m.CheckedAt
+	if checkedAt.IsZero() {
+		checkedAt = time.Now()
+	}
+	return tx.Model(&model.ListMember{}).Where("id = ?", member.ID).Updates(map[string]interface{}{
+		"status":           m.Status,
+		"reason":           m.Reason,
+		"score":            m.Score,
+		"is_role_based":    m.IsRoleBased,
+		"is_disposable":    m.IsDisposable,
+		"is_catch_all":     m.IsCatchAll,
+		"is_free":          m.IsFree,
+		"last_verified_at": &checkedAt,
+		"last_job_id":      jobId,
+	}).Error
+}
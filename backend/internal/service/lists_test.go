@@ -0,0 +1,221 @@
+package service
+
+import (
+	"backend/internal/model"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// cleanupTestList supprime à la fin du test la liste, ses membres et ses jobs de vérification
+func cleanupTestList(t *testing.T, db *gorm.DB, listId string) {
+	t.Cleanup(func() {
+		var jobIDs []string
+		db.Model(&model.BulkJob{}).Where("list_id = ?", listId).Pluck("id", &jobIDs)
+		for _, id := range jobIDs {
+			deleteTestJob(db, id)
+		}
+		db.Where("list_id = ?", listId).Delete(&model.ListMember{})
+		db.Where("id = ?", listId).Delete(&model.List{})
+	})
+}
+
+// importTestCSV importe un CSV dans une nouvelle liste
+func importTestCSV(t *testing.T, name, content string) (ListView, ListImportReport, error) {
+	t.Helper()
+	db := testDB(t)
+	path := filepath.Join(t.TempDir(), "list.csv")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	view, report, err := ImportList(name, "", "", path, "", 0)
+	if err == nil {
+		cleanupTestList(t, db, view.ID)
+	}
+	return view, report, err
+}
+
+// verifyTestList crée le job de vérification d'une liste et met ses membres en
+// file entièrement (sans passer par la goroutine de VerifyList)
+func verifyTestList(t *testing.T, listId string, opts ListVerifyOptions) (model.BulkJob, error) {
+	t.Helper()
+	job, err := createListVerifyJob(listId, opts)
+	if err != nil {
+		return job, err
+	}
+	err = enqueueListMembers(job.ID, listId, opts.Filter)
+	job, _ = GetBulkJobByID(job.ID)
+	return job, err
+}
+
+// Les doublons sont ignorés à l'intérieur d'un lot comme d'un lot à l'autre,
+// sans distinction de casse
+func TestImportListDedup(t *testing.T) {
+	db := testDB(t)
+	var b strings.Builder
+	b.WriteString("email\n")
+	for i := 0; i < 2*listBatchSize+500; i++ {
+		fmt.Fprintf(&b, "user%d@example.com\n", i%(listBatchSize+200))
+	}
+	b.WriteString("USER1@Example.com\nuser1@example.com\nnot-an-email\n\nlast@example.com\n")
+
+	view, report, err := importTestCSV(t, "dedup import", b.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	unique := listBatchSize + 200 + 1
+	want := ListImportReport{Rows: 2*listBatchSize + 500 + 4, Added: unique, Invalid: 1}
+	want.Duplicates = want.Rows - want.Added - want.Invalid
+	if report != want {
+		t.Errorf("report = %+v, want %+v", report, want)
+	}
+	var members int64
+	db.Model(&model.ListMember{}).Where("list_id = ?", view.ID).Count(&members)
+	if members != int64(unique) || view.Members != unique {
+		t.Errorf("members = %d (view %d), want %d", members, view.Members, unique)
+	}
+}
+
+// Au-delà de MAX_UPLOAD_ROWS l'import échoue et la liste partielle est supprimée
+func TestImportListRowLimit(t *testing.T) {
+	db := testDB(t)
+	t.Setenv("MAX_UPLOAD_ROWS", "2")
+	name := fmt.Sprintf("row limit %d", time.Now().UnixNano())
+	_, _, err := importTestCSV(t, name, "email\na@example.com\nb@example.com\nc@example.com\n")
+	if !errors.Is(err, ErrRowLimit) {
+		t.Fatalf("err = %v, want ErrRowLimit", err)
+	}
+	var lists int64
+	db.Model(&model.List{}).Where("name = ?", name).Count(&lists)
+	if lists != 0 {
+		t.Errorf("%d partial lists left", lists)
+	}
+}
+
+// La vérification d'une liste met les membres en file par lots, dans l'ordre
+func TestVerifyListQueuesMembersInBatches(t *testing.T) {
+	db := testDB(t)
+	emails := make([]string, listBatchSize+10)
+	for i := range emails {
+		emails[i] = fmt.Sprintf("member%d@example.com", i)
+	}
+	view, _, err := CreateList("verify batches", "", "", emails)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cleanupTestList(t, db, view.ID)
+
+	job, err := verifyTestList(t, view.ID, ListVerifyOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.TotalEmails != len(emails) || job.Ingesting || job.Status != model.JobStatusQueued {
+		t.Errorf("job total = %d, ingesting = %v, status = %s", job.TotalEmails, job.Ingesting, job.Status)
+	}
+	var items []model.WorkItem
+	db.Where("job_id = ?", job.ID).Order("row_index").Find(&items)
+	if len(items) != len(emails) {
+		t.Fatalf("%d work items, want %d", len(items), len(emails))
+	}
+	for i, it := range items {
+		if it.RowIndex != i || it.Payload != emails[i] {
+			t.Fatalf("item %d = row %d %q, want %q", i, it.RowIndex, it.Payload, emails[i])
+		}
+	}
+
+	if _, err := VerifyList(view.ID, ListVerifyOptions{}); !errors.Is(err, ErrJobActive) {
+		t.Errorf("second verification: err = %v, want ErrJobActive", err)
+	}
+	_, err = VerifyList(view.ID, ListVerifyOptions{Filter: MemberFilter{Statuses: []string{"invalid"}}})
+	if !errors.Is(err, ErrJobActive) {
+		t.Errorf("filtered verification while running: err = %v", err)
+	}
+}
+
+// Un job annulé pendant la mise en file n'en reçoit plus et reste annulé
+func TestVerifyListCancelledWhileQueueing(t *testing.T) {
+	db := testDB(t)
+	view, _, err := CreateList("verify cancelled", "", "", []string{"a@example.com", "b@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cleanupTestList(t, db, view.ID)
+
+	job, err := createListVerifyJob(view.ID, ListVerifyOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !job.Ingesting {
+		t.Fatal("job créé sans ingestion en cours")
+	}
+	if _, err := CancelBulkJob(job.ID); err != nil {
+		t.Fatal(err)
+	}
+	runListIngestion(job.ID, view.ID, MemberFilter{})
+	var queued int64
+	db.Model(&model.WorkItem{}).Where("job_id = ?", job.ID).Count(&queued)
+	job, _ = GetBulkJobByID(job.ID)
+	if queued != 0 || job.Status != model.JobStatusCancelled || job.Ingesting {
+		t.Errorf("queued = %d, status = %s, ingesting = %v", queued, job.Status, job.Ingesting)
+	}
+}
+
+// Chaque résultat met à jour le membre; une seconde vérification enregistre les
+// changements de statut des adresses déjà vérifiées
+func TestVerifyListMergesMembers(t *testing.T) {
+	db := testDB(t)
+	view, _, err := CreateList("merge members", "", "", []string{"a@example.com", "b@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cleanupTestList(t, db, view.ID)
+
+	verify := func(statuses map[string]string) model.BulkJob {
+		t.Helper()
+		job, err := verifyTestList(t, view.ID, ListVerifyOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, item := range claimTestItems(t, job.ID, "worker-a") {
+			res := EmailValidationResult{JobID: job.ID, Email: item.Payload, Status: EmailStatus(statuses[item.Payload]), CheckedAt: time.Now()}
+			if err := CompleteVerifyItem(item, res, false); err != nil {
+				t.Fatal(err)
+			}
+		}
+		job, _ = GetBulkJobByID(job.ID)
+		if job.Status != model.JobStatusDone {
+			t.Fatalf("job status = %s", job.Status)
+		}
+		return job
+	}
+	member := func(email string) model.ListMember {
+		var m model.ListMember
+		db.Where("list_id = ? AND email = ?", view.ID, email).First(&m)
+		return m
+	}
+
+	first := verify(map[string]string{"a@example.com": "valid", "b@example.com": "valid"})
+	if m := member("a@example.com"); m.Status != "valid" || m.LastJobID == nil || *m.LastJobID != first.ID || m.LastVerifiedAt == nil {
+		t.Fatalf("member after first run = %+v", m)
+	}
+	var changes []model.StatusChange
+	db.Where("job_id = ?", first.ID).Find(&changes)
+	if len(changes) != 0 {
+		t.Errorf("first verification recorded %d changes", len(changes))
+	}
+
+	second := verify(map[string]string{"a@example.com": "invalid", "b@example.com": "valid"})
+	if m := member("a@example.com"); m.Status != "invalid" || *m.LastJobID != second.ID {
+		t.Errorf("member after second run = %+v", m)
+	}
+	db.Where("job_id = ?", second.ID).Find(&changes)
+	if len(changes) != 1 || changes[0].Email != "a@example.com" || changes[0].FromStatus != "valid" || changes[0].ToStatus != "invalid" {
+		t.Errorf("changes = %+v", changes)
+	}
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"backend/internal/config"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrMailNotConfigured est retourné quand SMTP_HOST n'est pas défini
+var ErrMailNotConfigured = errors.New("smtp is not configured")
+
+// ErrInvalidNotifyEmail est retourné pour une adresse de notification invalide
+var ErrInvalidNotifyEmail = errors.New("invalid notification email")
+
+// ValidateNotifyEmail vérifie une adresse destinataire de notifications
+func ValidateNotifyEmail(addr string) error {
+	parsed, err := mail.ParseAddress(addr)
+	if err != nil || parsed.Address != addr {
+		return ErrInvalidNotifyEmail
+	}
+	return nil
+}
+
+// sendMail envoie un email texte via le serveur SMTP configuré
+func sendMail(to, subject, body string) error {
+	cfg := config.Load()
+	if cfg.SMTPHost == "" {
+		return ErrMailNotConfigured
+	}
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", cfg.SMTPFrom)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+
+	var auth smtp.Auth
+	if cfg.SMTPUser != "" {
+		auth = smtp.PlainAuth("", cfg.SMTPUser, cfg.SMTPPassword, cfg.SMTPHost)
+	}
+	addr := net.JoinHostPort(cfg.SMTPHost, strconv.Itoa(cfg.SMTPPort))
+	return smtp.SendMail(addr, auth, cfg.SMTPFrom, []string{to}, []byte(msg.String()))
+}
@@ -0,0 +1,307 @@
+package service
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Types de numéro déduits du plan de numérotation (sans requête réseau)
+const (
+	PhoneMobile   = "mobile"
+	PhoneLandline = "landline"
+	PhoneTollFree = "toll_free"
+	PhoneUnknown  = "unknown"
+)
+
+var (
+	ErrInvalidPhone   = errors.New("invalid phone number")
+	ErrUnknownCountry = errors.New("phone number without country code and unknown country")
+)
+
+// Phone est un numéro normalisé au format E.164
+type Phone struct {
+	Raw     string `json:"raw"`
+	E164    string `json:"e164"`
+	Country string `json:"country,omitempty"` // ISO 3166-1 alpha-2
+	Type    string `json:"type"`
+}
+
+// phoneCountry décrit le plan de numérotation d'un pays; les préfixes portent sur
+// le numéro national significatif (sans préfixe national ni indicatif)
+type phoneCountry struct {
+	code     string // indicatif international
+	trunk    string // préfixe national retiré avant l'indicatif ("0")
+	lengths  []int  // longueurs possibles du numéro national significatif
+	tollFree []string
+	mobile   []string
+	landline []string
+}
+
+// Amérique du Nord: mobiles et fixes ne se distinguent pas par le préfixe
+var nanpCountry = phoneCountry{
+	code: "1", trunk: "1", lengths: []int{10},
+	tollFree: []string{"800", "833", "844", "855", "866", "877", "888"},
+}
+
+var phoneCountries = map[string]phoneCountry{
+	"US": nanpCountry,
+	"CA": nanpCountry,
+	"FR": {code: "33", trunk: "0", lengths: []int{9},
+		tollFree: []string{"800", "801", "802", "803", "804", "805"},
+		mobile:   []string{"6", "7"},
+		landline: []string{"1", "2", "3", "4", "5", "9"}},
+	"MA": {code: "212", trunk: "0", lengths: []int{9},
+		tollFree: []string{"80"},
+		mobile:   []string{"6", "7"},
+		landline: []string{"5"}},
+	"DZ": {code: "213", trunk: "0", lengths: []int{8, 9},
+		mobile:   []string{"5", "6", "7"},
+		landline: []string{"2", "3", "4"}},
+	"TN": {code: "216", lengths: []int{8},
+		tollFree: []string{"80"},
+		mobile:   []string{"2", "4", "5", "9"},
+		landline: []string{"3", "7"}},
+	"BE": {code: "32", trunk: "0", lengths: []int{8, 9},
+		tollFree: []string{"800"},
+		mobile:   []string{"45", "46", "47", "48", "49"},
+		landline: []string{"1", "2", "3", "5", "6", "7", "8", "9"}},
+	"CH": {code: "41", trunk: "0", lengths: []int{9},
+		tollFree: []string{"800"},
+		mobile:   []string{"75", "76", "77", "78", "79"},
+		landline: []string{"2", "3", "4", "5", "6", "7", "8", "9"}},
+	"GB": {code: "44", trunk: "0", lengths: []int{9, 10},
+		tollFree: []string{"800", "808"},
+		mobile:   []string{"7"},
+		landline: []string{"1", "2"}},
+	"DE": {code: "49", trunk: "0", lengths: []int{6, 7, 8, 9, 10, 11},
+		tollFree: []string{"800"},
+		mobile:   []string{"15", "16", "17"},
+		landline: []string{"2", "3", "4", "5", "6", "7", "8", "9"}},
+	"ES": {code: "34", lengths: []int{9},
+		tollFree: []string{"800", "900"},
+		mobile:   []string{"6", "7"},
+		landline: []string{"8", "9"}},
+	"NL": {code: "31", trunk: "0", lengths: []int{9},
+		tollFree: []string{"800"},
+		mobile:   []string{"6"},
+		landline: []string{"1", "2", "3", "4", "5", "7"}},
+}
+
+// Pays déduit de l'extension du domaine ou de la langue de la page
+var (
+	tldCountries = map[string]string{
+		"us": "US", "ca": "CA", "fr": "FR", "ma": "MA", "dz": "DZ", "tn": "TN",
+		"be": "BE", "ch": "CH", "uk": "GB", "de": "DE", "es": "ES", "nl": "NL",
+	}
+	languageCountries = map[string]string{
+		"en": "US", "fr": "FR", "de": "DE", "es": "ES", "nl": "NL",
+	}
+)
+
+var (
+	// Candidat dans un texte: chiffres, espaces et séparateurs usuels, éventuellement précédés de +
+	phoneCandidateRegex = regexp.MustCompile(`(?:^|[^\w+])(\+?\(?\d[\d \t().\-]{5,20}\d)`)
+	// Groupes de chiffres séparés: (555) 123-4567, +1 555 123 4567, 01 23 45 67 89
+	phoneGroupsRegex = regexp.MustCompile(`^\+?\(?\d{1,4}\)?(?:[-.\s]{1,2}\(?\d{1,5}\)?){1,6}$`)
+	phoneDateRegex   = regexp.MustCompile(`^\d{4}[-/.]\d{1,2}[-/.]\d{1,2}$|^\d{1,2}[-/.]\d{1,2}[-/.]\d{2,4}$`)
+	nonDigitRegex    = regexp.MustCompile(`\D`)
+)
+
+// isLikelyPhone écarte les suites de chiffres qui ne sont pas des numéros de
+// téléphone: dates, identifiants (chiffres collés sans séparateur), répétitions
+func isLikelyPhone(raw string) bool {
+	raw = strings.TrimSpace(raw)
+	digits := nonDigitRegex.ReplaceAllString(raw, "")
+
+	// 7 à 15 chiffres pour un numéro international
+	if len(digits) < 7 || len(digits) > 15 {
+		return false
+	}
+	if phoneDateRegex.MatchString(raw) {
+		return false
+	}
+	// Séquences répétitives (1111111)
+	if strings.Count(digits, digits[:1]) == len(digits) {
+		return false
+	}
+
+	international := strings.HasPrefix(raw, "+")
+	// Sans + ni séparateur, une suite de chiffres est un identifiant
+	if !international && digits == raw {
+		return false
+	}
+	// Plus de 8 chiffres collés: identifiant, sauf numéro international (+33612345678)
+	if !international && longestDigitRun(raw) > 8 {
+		return false
+	}
+	if international && digits == raw[1:] {
+		return true
+	}
+	return phoneGroupsRegex.MatchString(strings.ReplaceAll(raw, "(0)", ""))
+}
+
+func longestDigitRun(s string) int {
+	longest, run := 0, 0
+	for _, c := range s {
+		if c >= '0' && c <= '9' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return longest
+}
+
+// ParsePhone normalise un numéro au format E.164. Un numéro sans indicatif
+// (+ ou 00) est lu dans le plan de numérotation de country.
+func ParsePhone(raw, country string) (Phone, error) {
+	p := Phone{Raw: strings.TrimSpace(raw)}
+	s := strings.ReplaceAll(p.Raw, "(0)", "")
+	international := strings.HasPrefix(s, "+")
+	digits := nonDigitRegex.ReplaceAllString(s, "")
+	if !international && strings.HasPrefix(digits, "00") {
+		international = true
+		digits = digits[2:]
+	}
+	if len(digits) < 7 || len(digits) > 15 {
+		return p, ErrInvalidPhone
+	}
+
+	if international {
+		// Indicatif connu: le pays déduit du site départage +1 (US/CA)
+		for n := 1; n <= 3 && n < len(digits); n++ {
+			isos := phoneCountryCodes(digits[:n], country)
+			for _, iso := range isos {
+				if nsn, ok := phoneCountries[iso].national(digits[n:]); ok {
+					return p.with(iso, nsn), nil
+				}
+			}
+			if len(isos) > 0 {
+				return p, ErrInvalidPhone
+			}
+		}
+		// Indicatif hors des pays connus: E.164 sans type
+		if digits[0] == '0' || len(digits) < 8 {
+			return p, ErrInvalidPhone
+		}
+		p.E164 = "+" + digits
+		p.Type = PhoneUnknown
+		return p, nil
+	}
+
+	meta, ok := phoneCountries[country]
+	if !ok {
+		return p, ErrUnknownCountry
+	}
+	nsn := digits
+	if meta.trunk != "" && strings.HasPrefix(nsn, meta.trunk) && meta.validLength(len(nsn)-len(meta.trunk)) {
+		nsn = nsn[len(meta.trunk):]
+	}
+	if nsn, ok := meta.national(nsn); ok {
+		return p.with(country, nsn), nil
+	}
+	return p, ErrInvalidPhone
+}
+
+// phoneCountryCodes retourne les pays d'un indicatif, le pays préféré d'abord
+func phoneCountryCodes(code, preferred string) []string {
+	var isos []string
+	if meta, ok := phoneCountries[preferred]; ok && meta.code == code {
+		isos = append(isos, preferred)
+	}
+	for _, iso := range []string{"US", "CA", "FR", "MA", "DZ", "TN", "BE", "CH", "GB", "DE", "ES", "NL"} {
+		if phoneCountries[iso].code == code && iso != preferred {
+			isos = append(isos, iso)
+		}
+	}
+	return isos
+}
+
+func (p Phone) with(country, nsn string) Phone {
+	meta := phoneCountries[country]
+	p.Country = country
+	p.E164 = "+" + meta.code + nsn
+	p.Type = meta.classify(nsn)
+	return p
+}
+
+func (m phoneCountry) validLength(n int) bool {
+	for _, l := range m.lengths {
+		if l == n {
+			return true
+		}
+	}
+	return false
+}
+
+// national valide un numéro national significatif
+func (m phoneCountry) national(nsn string) (string, bool) {
+	if !m.validLength(len(nsn)) || nsn[0] == '0' {
+		return "", false
+	}
+	// Amérique du Nord: indicatif régional et central commencent par 2 à 9
+	if m.code == "1" && (nsn[0] < '2' || nsn[3] < '2') {
+		return "", false
+	}
+	return nsn, true
+}
+
+func (m phoneCountry) classify(nsn string) string {
+	for _, group := range []struct {
+		kind     string
+		prefixes []string
+	}{{PhoneTollFree, m.tollFree}, {PhoneMobile, m.mobile}, {PhoneLandline, m.landline}} {
+		for _, prefix := range group.prefixes {
+			if strings.HasPrefix(nsn, prefix) {
+				return group.kind
+			}
+		}
+	}
+	return PhoneUnknown
+}
+
+// InferPhoneCountry déduit le pays des numéros nationaux d'un site: extension du
+// domaine, puis langue de la page (attribut lang, "fr-CA" ou "fr")
+func InferPhoneCountry(siteURL, lang string) string {
+	host := siteURL
+	if u, err := url.Parse(siteURL); err == nil && u.Hostname() != "" {
+		host = u.Hostname()
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if i := strings.LastIndex(host, "."); i != -1 {
+		if country, ok := tldCountries[host[i+1:]]; ok {
+			return country
+		}
+	}
+
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	primary, region, _ := strings.Cut(strings.ReplaceAll(lang, "_", "-"), "-")
+	if _, ok := phoneCountries[strings.ToUpper(region)]; ok {
+		return strings.ToUpper(region)
+	}
+	return languageCountries[primary]
+}
+
+// ExtractPhones retourne les numéros d'un texte qui passent le filtre des faux
+// positifs et se normalisent en E.164, sans doublon, dans l'ordre du texte
+func ExtractPhones(text, country string) []Phone {
+	var phones []Phone
+	seen := map[string]bool{}
+	text = strings.ReplaceAll(text, "\u00a0", " ") // espace insécable: 01\u00a023\u00a045...
+	for _, m := range phoneCandidateRegex.FindAllStringSubmatch(text, -1) {
+		raw := strings.TrimRight(strings.TrimSpace(m[1]), ".-/(")
+		if !isLikelyPhone(raw) {
+			continue
+		}
+		p, err := ParsePhone(raw, country)
+		if err != nil || seen[p.E164] {
+			continue
+		}
+		seen[p.E164] = true
+		phones = append(phones, p)
+	}
+	return phones
+}
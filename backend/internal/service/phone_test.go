@@ -0,0 +1,126 @@
+package service
+
+import (
+	"errors"
+	"testing"
+)
+
+// Cas repris de l'ancien test_phone.go
+func TestIsLikelyPhone(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want bool
+	}{
+		{"179268733", false},      // ID
+		{"1010044", false},        // ID
+		{"1747178032", false},     // ID
+		{"(555) 123-4567", true},  // téléphone valide
+		{"555-123-4567", true},    // téléphone valide
+		{"+1 555 123 4567", true}, // téléphone valide
+		{"2021-08-28", false},     // date
+		{"1234567890", false},     // 10 chiffres consécutifs
+		{"555-1234", true},        // téléphone court
+		{"01 23 45 67 89", true},  // format français
+		{"+33 (0)1 23 45 67 89", true},
+		{"+33612345678", true},  // E.164 collé
+		{"28/08/2021", false},   // date
+		{"111-111-1111", false}, // chiffres répétés
+		{"12-34", false},        // trop court
+		{"123456789012345678", false},
+	}
+	for _, tt := range tests {
+		if got := isLikelyPhone(tt.raw); got != tt.want {
+			t.Errorf("isLikelyPhone(%q) = %t, want %t", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestParsePhone(t *testing.T) {
+	tests := []struct {
+		raw, country string
+		e164         string
+		iso          string
+		typ          string
+	}{
+		{"(415) 555-2671", "US", "+14155552671", "US", PhoneUnknown},
+		{"1-800-555-0199", "US", "+18005550199", "US", PhoneTollFree},
+		{"+1 415 555 2671", "CA", "+14155552671", "CA", PhoneUnknown},
+		{"01 23 45 67 89", "FR", "+33123456789", "FR", PhoneLandline},
+		{"06 12 34 56 78", "FR", "+33612345678", "FR", PhoneMobile},
+		{"0 800 123 456", "FR", "+33800123456", "FR", PhoneTollFree},
+		{"+33 (0)6 12 34 56 78", "", "+33612345678", "FR", PhoneMobile},
+		{"0033 6 12 34 56 78", "", "+33612345678", "FR", PhoneMobile},
+		{"0661-234567", "MA", "+212661234567", "MA", PhoneMobile},
+		{"+212 5 22 12 34 56", "FR", "+212522123456", "MA", PhoneLandline},
+		{"020 7946 0958", "GB", "+442079460958", "GB", PhoneLandline},
+		{"07700 900123", "GB", "+447700900123", "GB", PhoneMobile},
+		{"030 1234567", "DE", "+49301234567", "DE", PhoneLandline},
+		{"612 34 56 78", "ES", "+34612345678", "ES", PhoneMobile},
+		{"+216 71 123 456", "", "+21671123456", "TN", PhoneLandline},
+		{"+81 3 1234 5678", "", "+81312345678", "", PhoneUnknown},
+	}
+	for _, tt := range tests {
+		p, err := ParsePhone(tt.raw, tt.country)
+		if err != nil {
+			t.Errorf("ParsePhone(%q, %q): %v", tt.raw, tt.country, err)
+			continue
+		}
+		if p.E164 != tt.e164 || p.Country != tt.iso || p.Type != tt.typ {
+			t.Errorf("ParsePhone(%q, %q) = %s %s %s, want %s %s %s", tt.raw, tt.country, p.E164, p.Country, p.Type, tt.e164, tt.iso, tt.typ)
+		}
+	}
+}
+
+func TestParsePhoneErrors(t *testing.T) {
+	tests := []struct {
+		raw, country string
+		err          error
+	}{
+		{"06 12 34 56 78", "", ErrUnknownCountry},
+		{"(555) 123-4567", "US", ErrInvalidPhone}, // central 123: commence par 1
+		{"+1 555 123 4567", "", ErrInvalidPhone},
+		{"01 23 45 67", "FR", ErrInvalidPhone}, // trop court
+		{"123", "FR", ErrInvalidPhone},
+	}
+	for _, tt := range tests {
+		if _, err := ParsePhone(tt.raw, tt.country); !errors.Is(err, tt.err) {
+			t.Errorf("ParsePhone(%q, %q) error = %v, want %v", tt.raw, tt.country, err, tt.err)
+		}
+	}
+}
+
+func TestInferPhoneCountry(t *testing.T) {
+	tests := []struct {
+		site, lang, want string
+	}{
+		{"https://www.example.fr/contact", "", "FR"},
+		{"https://shop.example.co.uk", "en", "GB"},
+		{"https://example.ma", "ar", "MA"},
+		{"https://example.com", "fr-CA", "CA"},
+		{"https://example.com", "fr", "FR"},
+		{"https://example.com", "en-GB", "GB"},
+		{"https://example.com", "en", "US"},
+		{"https://example.com", "ar", ""},
+		{"example.de", "", "DE"},
+	}
+	for _, tt := range tests {
+		if got := InferPhoneCountry(tt.site, tt.lang); got != tt.want {
+			t.Errorf("InferPhoneCountry(%q, %q) = %q, want %q", tt.site, tt.lang, got, tt.want)
+		}
+	}
+}
+
+func TestExtractPhones(t *testing.T) {
+	text := "Commande n° 179268733 du 2021-08-28. Appelez le 01 23 45 67 89 ou le " +
+		"+33 6 12 34 56 78 (mobile), numéro vert 0 800 123 456. Réf. 1234567890. Tél: 01.23.45.67.89"
+	got := ExtractPhones(text, "FR")
+	want := []string{"+33123456789", "+33612345678", "+33800123456"}
+	if len(got) != len(want) {
+		t.Fatalf("ExtractPhones = %+v, want %v", got, want)
+	}
+	for i, p := range got {
+		if p.E164 != want[i] {
+			t.Errorf("ExtractPhones[%d] = %s, want %s", i, p.E164, want[i])
+		}
+	}
+}
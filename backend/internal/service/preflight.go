@@ -0,0 +1,213 @@
+package service
+
+import (
+	"backend/internal/model"
+	"backend/internal/util"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Nombre de domaines détaillés dans le rapport
+const preflightTopDomains = 20
+
+// DomainCount est le nombre d'adresses uniques d'un domaine
+type DomainCount struct {
+	Domain string `json:"domain"`
+	Count  int    `json:"count"`
+}
+
+// PreflightReport résume une liste avant vérification: seules les adresses
+// uniques (si SkipDuplicates) et syntaxiquement valides sont mises en file
+type PreflightReport struct {
+	Rows                int           `json:"rows"`                // lignes avec une valeur
+	BlankRows           int           `json:"blankRows"`           // lignes sans valeur dans la colonne
+	Unique              int           `json:"unique"`              // adresses valides distinctes (forme canonique)
+	ExactDuplicates     int           `json:"exactDuplicates"`     // même adresse, casse ignorée
+	CanonicalDuplicates int           `json:"canonicalDuplicates"` // même boîte (+tag, points Gmail)
+	SyntaxErrors        int           `json:"syntaxErrors"`
+	Disposable          int           `json:"disposable"`
+	RoleBased           int           `json:"roleBased"`
+	Free                int           `json:"free"`
+	Domains             int           `json:"domains"`
+	TopDomains          []DomainCount `json:"topDomains"`
+	SkipDuplicates      bool          `json:"skipDuplicates"`
+	Queued              int           `json:"queued"` // adresses envoyées à la vérification
+}
+
+// preflightOutcome indique ce qu'il faut faire d'une ligne
+type preflightOutcome struct {
+	queue       bool
+	blank       bool // ligne sans adresse, recopiée telle quelle dans le fichier enrichi
+	syntaxError bool
+	duplicateOf string // première occurrence quand la ligne est un doublon
+}
+
+// preflightAnalyzer analyse les lignes au fil de la lecture du fichier
+type preflightAnalyzer struct {
+	report  PreflightReport
+	exact   map[string]struct{}
+	first   map[string]string // forme canonique -> première valeur rencontrée
+	domains map[string]int
+}
+
+func newPreflightAnalyzer(skipDuplicates bool) *preflightAnalyzer {
+	return &preflightAnalyzer{
+		report:  PreflightReport{SkipDuplicates: skipDuplicates},
+		exact:   map[string]struct{}{},
+		first:   map[string]string{},
+		domains: map[string]int{},
+	}
+}
+
+// add classe la valeur (déjà nettoyée des espaces) de la ligne suivante
+func (a *preflightAnalyzer) add(value string) preflightOutcome {
+	if value == "" {
+		a.report.BlankRows++
+		return preflightOutcome{blank: true}
+	}
+	a.report.Rows++
+	if !looksLikeEmail(value) {
+		a.report.SyntaxErrors++
+		return preflightOutcome{syntaxError: true}
+	}
+
+	lower := strings.ToLower(value)
+	canonical := canonicalEmail(value)
+	if original, seen := a.first[canonical]; seen {
+		if _, ok := a.exact[lower]; ok {
+			a.report.ExactDuplicates++
+		} else {
+			a.report.CanonicalDuplicates++
+			a.exact[lower] = struct{}{}
+		}
+		if a.report.SkipDuplicates {
+			return preflightOutcome{duplicateOf: original}
+		}
+		a.report.Queued++
+		return preflightOutcome{queue: true}
+	}
+
+	a.first[canonical] = value
+	a.exact[lower] = struct{}{}
+	a.report.Unique++
+	a.domains[emailDomain(value)]++
+	if isDisposable(value) {
+		a.report.Disposable++
+	}
+	if isRoleBased(value) {
+		a.report.RoleBased++
+	}
+	if isFreeEmail(value) {
+		a.report.Free++
+	}
+	a.report.Queued++
+	return preflightOutcome{queue: true}
+}
+
+// finish complète le rapport avec la répartition par domaine
+func (a *preflightAnalyzer) finish() PreflightReport {
+	report := a.report
+	report.Domains = len(a.domains)
+	report.TopDomains = make([]DomainCount, 0, len(a.domains))
+	for d, n := range a.domains {
+		report.TopDomains = append(report.TopDomains, DomainCount{Domain: d, Count: n})
+	}
+	sort.Slice(report.TopDomains, func(i, j int) bool {
+		if report.TopDomains[i].Count != report.TopDomains[j].Count {
+			return report.TopDomains[i].Count > report.TopDomains[j].Count
+		}
+		return report.TopDomains[i].Domain < report.TopDomains[j].Domain
+	})
+	if len(report.TopDomains) > preflightTopDomains {
+		report.TopDomains = report.TopDomains[:preflightTopDomains]
+	}
+	return report
+}
+
+// skippedResult est le résultat enregistré, sans vérification SMTP, pour une
+// ligne écartée par l'analyse (sans adresse, syntaxe invalide ou doublon)
+func skippedResult(jobId string, row SourceRow, outcome preflightOutcome) model.EmailResult {
+	source, _ := json.Marshal(row.Columns)
+	if outcome.blank {
+		return model.EmailResult{JobID: jobId, RowIndex: row.Index, SourceRow: string(source), CheckedAt: time.Now()}
+	}
+	res := model.EmailResult{
+		JobID:        jobId,
+		RowIndex:     row.Index,
+		SourceRow:    string(source),
+		Email:        row.Value,
+		CheckedAt:    time.Now(),
+		IsRoleBased:  isRoleBased(row.Value),
+		IsDisposable: isDisposable(row.Value),
+		IsFree:       isFreeEmail(row.Value),
+	}
+	if outcome.syntaxError {
+		res.Status = string(StatusInvalid)
+		res.Reason = "Invalid syntax"
+		res.Suggestion = suggestEmail(row.Value)
+	} else {
+		res.Status = string(StatusDuplicate)
+		res.Reason = fmt.Sprintf("Duplicate of %s", outcome.duplicateOf)
+	}
+	return res
+}
+
+// canonicalEmail ramène une adresse à sa boîte: minuscules, sans +tag, et sans
+// points pour Gmail (googlemail.com est un alias de gmail.com)
+func canonicalEmail(email string) string {
+	e := strings.ToLower(strings.TrimSpace(email))
+	at := strings.LastIndex(e, "@")
+	if at <= 0 {
+		return e
+	}
+	local, domain := e[:at], e[at+1:]
+	if domain == "googlemail.com" {
+		domain = "gmail.com"
+	}
+	if i := strings.IndexByte(local, '+'); i > 0 {
+		local = local[:i]
+	}
+	if domain == "gmail.com" {
+		local = strings.ReplaceAll(local, ".", "")
+	}
+	return local + "@" + domain
+}
+
+// AnalyzeSpooledList produit le rapport d'une liste sans créer de job
+func AnalyzeSpooledList(path, sheet string, column int, skipDuplicates bool) (PreflightReport, error) {
+	reader, err := openSpooledList(path, sheet)
+	if err != nil {
+		return PreflightReport{}, err
+	}
+	defer reader.Close()
+	analyzer := newPreflightAnalyzer(skipDuplicates)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return analyzer.finish(), nil
+		}
+		if err != nil && !util.IsRowError(err) {
+			return PreflightReport{}, err
+		}
+		if err != nil || column >= len(record) {
+			continue
+		}
+		analyzer.add(strings.TrimSpace(record[column]))
+	}
+}
+
+// GetPreflightReport retourne le rapport enregistré sur le job (nil s'il n'y en a pas)
+func GetPreflightReport(job model.BulkJob) *PreflightReport {
+	if job.Preflight == "" {
+		return nil
+	}
+	var report PreflightReport
+	if err := json.Unmarshal([]byte(job.Preflight), &report); err != nil {
+		return nil
+	}
+	return &report
+}
@@ -0,0 +1,388 @@
+package service
+
+import (
+	"backend/internal/infra"
+	"backend/internal/model"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Durée de réservation d'un élément; renouvelée par le heartbeat du worker,
+// elle expire si le worker meurt et l'élément est alors remis en file
+const workLeaseDuration = 2 * time.Minute
+
+// ErrLeaseLost est retourné quand un worker termine un élément dont il ne détient
+// plus la réservation; son résultat est abandonné (transaction annulée)
+var ErrLeaseLost = errors.New("work item lease lost")
+
+// Intervalle minimal entre deux événements "progress" d'un même job
+const progressEventInterval = time.Second
+
+// SourceRow est une ligne du fichier uploadé: sa position parmi les lignes de
+// données, la valeur à traiter (email ou site) et toutes ses colonnes d'origine
+type SourceRow struct {
+	Index   int
+	Value   string
+	Columns []string
+}
+
+// Crée un job bulk (verify ou extract) et met ses éléments en file dans une même
+// transaction, pour qu'aucun worker ne voie le job avant que tout soit inséré.
+// L'en-tête et les lignes d'origine sont conservés pour le fichier enrichi.
+func CreateQueuedBulkJob(job model.BulkJob, rows []SourceRow) (model.BulkJob, error) {
+	db := infra.GetDB()
+	job.UploadedAt = time.Now()
+	job.Status = model.JobStatusQueued
+	job.TotalEmails = len(rows)
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&job).Error; err != nil {
+			return err
+		}
+		return enqueueWorkItems(tx, job.ID, job.Kind, rows)
+	})
+	return job, err
+}
+
+// Ajoute à la file un élément par email ou site, dans l'ordre du fichier
+func enqueueWorkItems(db *gorm.DB, jobId, kind string, rows []SourceRow) error {
+	items := make([]model.WorkItem, len(rows))
+	for i, row := range rows {
+		source, _ := json.Marshal(row.Columns)
+		items[i] = model.WorkItem{
+			JobID:     jobId,
+			Kind:      kind,
+			RowIndex:  row.Index,
+			Payload:   row.Value,
+			SourceRow: string(source),
+			Status:    model.WorkItemPending,
+		}
+	}
+	return db.CreateInBatches(&items, 1000).Error
+}
+
+// Réserve jusqu'à limit éléments en attente du prochain job à servir (priorité puis
+// partage équitable, voir nextJobsToServe). SKIP LOCKED permet à plusieurs workers
+// de consommer la file en parallèle sans se bloquer mutuellement.
+// Les éléments des jobs suspendus ou annulés ne sont pas réservés.
+func ClaimWorkItems(workerID string, kinds []string, limit int) ([]model.WorkItem, error) {
+	db := infra.GetDB()
+	var items []model.WorkItem
+	err := db.Transaction(func(tx *gorm.DB) error {
+		candidates, err := nextJobsToServe(tx, kinds, schedulerCandidates)
+		if err != nil {
+			return err
+		}
+		for _, jobId := range candidates {
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+				Where("job_id = ? AND status = ? AND kind IN ?", jobId, model.WorkItemPending, kinds).
+				Order("id ASC").
+				Limit(limit).
+				Find(&items).Error
+			if err != nil {
+				return err
+			}
+			if len(items) > 0 {
+				break
+			}
+		}
+		if len(items) == 0 {
+			return nil
+		}
+
+		ids := make([]int64, len(items))
+		jobIDs := []string{}
+		seen := map[string]bool{}
+		for i, it := range items {
+			ids[i] = it.ID
+			if !seen[it.JobID] {
+				seen[it.JobID] = true
+				jobIDs = append(jobIDs, it.JobID)
+			}
+		}
+		now := time.Now()
+		leaseUntil := now.Add(workLeaseDuration)
+		err = tx.Model(&model.WorkItem{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+			"status":      model.WorkItemLeased,
+			"leased_by":   workerID,
+			"lease_until": &leaseUntil,
+			"attempts":    gorm.Expr("attempts + 1"),
+		}).Error
+		if err != nil {
+			return err
+		}
+		for i := range items {
+			items[i].Status = model.WorkItemLeased
+			items[i].LeasedBy = workerID
+			items[i].LeaseUntil = &leaseUntil
+			items[i].Attempts++
+		}
+		// Les éléments réservés appartiennent tous au même job
+		err = tx.Model(&model.BulkJob{}).Where("id = ?", items[0].JobID).
+			Update("leased_items", gorm.Expr("leased_items + ?", len(items))).Error
+		if err != nil {
+			return err
+		}
+
+		// Premier élément réservé: le job passe en cours de traitement
+		return tx.Model(&model.BulkJob{}).
+			Where("id IN ? AND status = ?", jobIDs, model.JobStatusQueued).
+			Updates(map[string]interface{}{
+				"status":     model.JobStatusProcessing,
+				"started_at": &now,
+			}).Error
+	})
+	return items, err
+}
+
+// Enregistre le résultat d'un élément et le marque terminé dans la même
+// transaction: un email déjà vérifié n'est jamais re-vérifié après un redémarrage.
+func CompleteVerifyItem(item model.WorkItem, res EmailValidationResult, failed bool) error {
+	db := infra.GetDB()
+	err := db.Transaction(func(tx *gorm.DB) error {
+		m := model.EmailResult{
+			JobID:        res.JobID,
+			RowIndex:     item.RowIndex,
+			SourceRow:    item.SourceRow,
+			Email:        res.Email,
+			IsValid:      res.IsValid,
+			Status:       string(res.Status),
+			Reason:       res.Reason,
+			BounceType:   res.BounceType,
+			CheckedAt:    res.CheckedAt,
+			Score:        res.Score,
+			IsRoleBased:  res.IsRoleBased,
+			IsDisposable: res.IsDisposable,
+			IsCatchAll:   res.IsCatchAll,
+			IsFree:       res.IsFree,
+			Suggestion:   res.Suggestion,
+		}
+		if err := tx.Create(&m).Error; err != nil {
+			return err
+		}
+		// Re-vérification ou liste: le nouveau résultat remplace le précédent
+		if !failed {
+			if err := mergeVerifiedResult(tx, item.JobID, m); err != nil {
+				return err
+			}
+		}
+		return markWorkItemDone(tx, item, failed)
+	})
+	if err != nil {
+		return err
+	}
+
+	PublishJobEvent(item.JobID, JobEventResult, res)
+	publishJobProgress(item.JobID)
+	return finalizeJobIfComplete(item.JobID)
+}
+
+// Enregistre les contacts extraits d'un site et marque l'élément terminé
+func CompleteExtractItem(item model.WorkItem, contacts SiteContacts, failed bool) error {
+	db := infra.GetDB()
+	rows := make([]model.ExtractResult, len(contacts.Emails))
+	for i, em := range contacts.Emails {
+		rows[i] = model.ExtractResult{
+			JobID:      item.JobID,
+			RowIndex:   item.RowIndex,
+			Site:       item.Payload,
+			Email:      em.Email,
+			Domain:     em.Domain,
+			Suppressed: em.Suppressed,
+			SourceURL:  em.Source,
+		}
+	}
+	var others []model.ExtractContact
+	for _, group := range []struct {
+		kind  string
+		found []SiteContact
+	}{{model.ContactPhone, contacts.Phones}, {model.ContactSocial, contacts.Socials}} {
+		for _, sc := range group.found {
+			others = append(others, model.ExtractContact{
+				JobID:     item.JobID,
+				RowIndex:  item.RowIndex,
+				Site:      item.Payload,
+				Kind:      group.kind,
+				Value:     sc.Value,
+				Country:   sc.Country,
+				PhoneType: sc.Type,
+				SourceURL: sc.Source,
+			})
+		}
+	}
+	orgs := make([]model.ExtractOrganization, len(contacts.Organizations))
+	for i, org := range contacts.Organizations {
+		data, _ := json.Marshal(org)
+		orgs[i] = model.ExtractOrganization{
+			JobID:     item.JobID,
+			RowIndex:  item.RowIndex,
+			Site:      item.Payload,
+			Type:      org.Type,
+			Name:      org.Name,
+			Format:    org.Format,
+			Data:      string(data),
+			SourceURL: org.Source,
+		}
+	}
+	skips := make([]model.RobotsSkip, len(contacts.RobotsSkipped))
+	for i, page := range contacts.RobotsSkipped {
+		skips[i] = model.RobotsSkip{JobID: item.JobID, RowIndex: item.RowIndex, Site: item.Payload, URL: page}
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if len(rows) > 0 {
+			if err := tx.Create(&rows).Error; err != nil {
+				return err
+			}
+		}
+		if len(others) > 0 {
+			if err := tx.Create(&others).Error; err != nil {
+				return err
+			}
+		}
+		if len(orgs) > 0 {
+			if err := tx.Create(&orgs).Error; err != nil {
+				return err
+			}
+		}
+		if len(skips) > 0 {
+			if err := tx.Create(&skips).Error; err != nil {
+				return err
+			}
+		}
+		return markWorkItemDone(tx, item, failed)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, r := range rows {
+		PublishJobEvent(item.JobID, JobEventResult, r)
+	}
+	for _, o := range others {
+		PublishJobEvent(item.JobID, JobEventContact, o)
+	}
+	for _, o := range orgs {
+		PublishJobEvent(item.JobID, JobEventOrganization, o)
+	}
+	publishJobProgress(item.JobID)
+	return finalizeJobIfComplete(item.JobID)
+}
+
+// markWorkItemDone clôt l'élément et incrémente la progression de son job. Seul le
+// worker qui détient encore la réservation peut le clore: après expiration,
+// l'élément a pu être remis en file et réservé par un autre worker (ou par une
+// autre goroutine du même processus, d'où la comparaison du nombre de tentatives).
+func markWorkItemDone(tx *gorm.DB, item model.WorkItem, failed bool) error {
+	errors := 0
+	if failed {
+		errors = 1
+	}
+	res := tx.Model(&model.WorkItem{}).
+		Where("id = ? AND leased_by = ? AND status = ? AND attempts = ?", item.ID, item.LeasedBy, model.WorkItemLeased, item.Attempts).
+		Updates(map[string]interface{}{
+			"status":      model.WorkItemDone,
+			"lease_until": nil,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrLeaseLost
+	}
+	return tx.Model(&model.BulkJob{}).Where("id = ?", item.JobID).Updates(map[string]interface{}{
+		"processed_emails": gorm.Expr("processed_emails + ?", 1),
+		"error_count":      gorm.Expr("error_count + ?", errors),
+		"leased_items":     gorm.Expr("GREATEST(leased_items - 1, 0)"),
+	}).Error
+}
+
+// Clôture le job lorsque son fichier est entièrement lu et que plus aucun
+// élément n'est en attente ou réservé
+func finalizeJobIfComplete(jobId string) error {
+	db := infra.GetDB()
+	var remaining int64
+	err := db.Model(&model.WorkItem{}).
+		Where("job_id = ? AND status IN ?", jobId, []string{model.WorkItemPending, model.WorkItemLeased}).
+		Count(&remaining).Error
+	if err != nil || remaining > 0 {
+		return err
+	}
+	now := time.Now()
+	// Un job dont des emails n'ont jamais été mis en file (traitement en mémoire interrompu) est en échec
+	status := gorm.Expr("CASE WHEN processed_emails < total_emails THEN ? ELSE ? END", model.JobStatusFailed, model.JobStatusDone)
+	res := db.Model(&model.BulkJob{}).
+		Where("id = ? AND ingesting = ? AND status IN ?", jobId, false, []string{model.JobStatusQueued, model.JobStatusProcessing}).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"finished_at": &now,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	// Un seul worker effectue la transition et diffuse "done"
+	if res.RowsAffected == 1 {
+		progressMu.Lock()
+		delete(lastProgressEvent, jobId)
+		progressMu.Unlock()
+		if progress, err := GetJobProgress(jobId); err == nil {
+			PublishJobEvent(jobId, JobEventDone, progress)
+		}
+		jobEnded(jobId)
+	}
+	return nil
+}
+
+// Remet en attente les éléments dont la réservation a expiré, reprend la lecture
+// des fichiers interrompue et clôture les jobs dont tout le travail est déjà
+// terminé. Appelé au démarrage du serveur API.
+func RecoverInterruptedWork() error {
+	if err := ReapExpiredLeases(); err != nil {
+		return err
+	}
+	if err := recountLeasedItems(); err != nil {
+		return err
+	}
+	if err := resumeIngestions(); err != nil {
+		return err
+	}
+	db := infra.GetDB()
+	var jobIDs []string
+	err := db.Model(&model.BulkJob{}).
+		Where("status IN ?", []string{model.JobStatusQueued, model.JobStatusProcessing}).
+		Pluck("id", &jobIDs).Error
+	if err != nil {
+		return err
+	}
+	for _, id := range jobIDs {
+		if err := finalizeJobIfComplete(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var (
+	progressMu        sync.Mutex
+	lastProgressEvent = map[string]time.Time{}
+)
+
+// publishJobProgress diffuse l'avancement du job, au plus une fois par progressEventInterval
+func publishJobProgress(jobId string) {
+	progressMu.Lock()
+	if time.Since(lastProgressEvent[jobId]) < progressEventInterval {
+		progressMu.Unlock()
+		return
+	}
+	lastProgressEvent[jobId] = time.Now()
+	progressMu.Unlock()
+
+	progress, err := GetJobProgress(jobId)
+	if err != nil {
+		return
+	}
+	PublishJobEvent(jobId, JobEventProgress, progress)
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"backend/internal/model"
+	"errors"
+	"testing"
+	"time"
+)
+
+// Un worker dont la réservation a expiré ne peut plus clore l'élément, remis en
+// file puis réservé par un autre worker: son résultat est abandonné
+func TestCompleteVerifyItemRejectsExpiredLease(t *testing.T) {
+	db := testDB(t)
+	job := createTestJob(t, model.BulkJob{FileName: "lease.csv"}, "a@example.com")
+
+	stale := claimTestItems(t, job.ID, "worker-a")[0]
+	// Réservation expirée: l'élément revient en file et un autre worker le prend
+	db.Model(&model.WorkItem{}).Where("id = ?", stale.ID).
+		Updates(map[string]interface{}{"status": model.WorkItemPending, "leased_by": "", "lease_until": nil})
+	fresh := claimTestItems(t, job.ID, "worker-b")[0]
+
+	res := EmailValidationResult{JobID: job.ID, Email: "a@example.com", Status: "valid", CheckedAt: time.Now()}
+	if err := CompleteVerifyItem(stale, res, false); !errors.Is(err, ErrLeaseLost) {
+		t.Fatalf("stale worker: err = %v, want ErrLeaseLost", err)
+	}
+	var results int64
+	db.Model(&model.EmailResult{}).Where("job_id = ?", job.ID).Count(&results)
+	got, _ := GetBulkJobByID(job.ID)
+	if results != 0 || got.ProcessedEmails != 0 {
+		t.Fatalf("stale worker wrote %d results, processed = %d", results, got.ProcessedEmails)
+	}
+
+	if err := CompleteVerifyItem(fresh, res, false); err != nil {
+		t.Fatalf("current worker: %v", err)
+	}
+	got, _ = GetBulkJobByID(job.ID)
+	if got.ProcessedEmails != 1 || got.Status != model.JobStatusDone {
+		t.Fatalf("processed = %d, status = %s", got.ProcessedEmails, got.Status)
+	}
+}
+
+// Même worker, réservation reprise par une autre goroutine: le nombre de
+// tentatives distingue les deux réservations
+func TestCompleteExtractItemRejectsSupersededLease(t *testing.T) {
+	db := testDB(t)
+	job := createTestJob(t, model.BulkJob{Kind: model.WorkKindExtract, FileName: "lease.csv"}, "example.com")
+
+	stale := claimTestItems(t, job.ID, "worker-a")[0]
+	db.Model(&model.WorkItem{}).Where("id = ?", stale.ID).
+		Updates(map[string]interface{}{"status": model.WorkItemPending, "leased_by": "", "lease_until": nil})
+	claimTestItems(t, job.ID, "worker-a")
+
+	contacts := SiteContacts{Emails: []ExtractedEmail{{Email: "info@example.com", Domain: "example.com"}}}
+	if err := CompleteExtractItem(stale, contacts, false); !errors.Is(err, ErrLeaseLost) {
+		t.Fatalf("err = %v, want ErrLeaseLost", err)
+	}
+	var rows int64
+	db.Model(&model.ExtractResult{}).Where("job_id = ?", job.ID).Count(&rows)
+	if rows != 0 {
+		t.Fatalf("superseded lease wrote %d rows", rows)
+	}
+}
+
+// Le compteur leased_items suit les réservations, clôtures et remises en file
+func TestLeasedItemsCounter(t *testing.T) {
+	db := testDB(t)
+	job := createTestJob(t, model.BulkJob{FileName: "leased.csv", Priority: model.JobPriorityUrgent}, "a@example.com", "b@example.com")
+	leased := func() int {
+		t.Helper()
+		got, err := GetBulkJobByID(job.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return got.LeasedItems
+	}
+
+	items, err := ClaimWorkItems("worker-a", []string{model.WorkKindVerify}, 2)
+	if err != nil || len(items) != 2 || items[0].JobID != job.ID {
+		t.Fatalf("claim: %d items, err %v", len(items), err)
+	}
+	if n := leased(); n != 2 {
+		t.Fatalf("after claim: leased_items = %d, want 2", n)
+	}
+
+	res := EmailValidationResult{JobID: job.ID, Email: items[0].Payload, Status: "valid", CheckedAt: time.Now()}
+	if err := CompleteVerifyItem(items[0], res, false); err != nil {
+		t.Fatal(err)
+	}
+	if n := leased(); n != 1 {
+		t.Fatalf("after completion: leased_items = %d, want 1", n)
+	}
+
+	db.Model(&model.WorkItem{}).Where("id = ?", items[1].ID).Update("lease_until", time.Now().Add(-time.Minute))
+	if err := ReapExpiredLeases(); err != nil {
+		t.Fatal(err)
+	}
+	if n := leased(); n != 0 {
+		t.Fatalf("after reap: leased_items = %d, want 0", n)
+	}
+
+	// Un compteur faussé est recalculé depuis work_items
+	claimTestItems(t, job.ID, "worker-b")
+	if err := recountLeasedItems(); err != nil {
+		t.Fatal(err)
+	}
+	if n := leased(); n != 1 {
+		t.Fatalf("after recount: leased_items = %d, want 1", n)
+	}
+}
+
+// Emails, contacts et organisations d'une extraction sont diffusés sous des
+// événements distincts, chacun avec une seule forme de données
+func TestCompleteExtractItemEventTypes(t *testing.T) {
+	job := createTestJob(t, model.BulkJob{Kind: model.WorkKindExtract, FileName: "events.csv"}, "example.com")
+	OpenJobStream(job.ID)
+	item := claimTestItems(t, job.ID, "worker-a")[0]
+	contacts := SiteContacts{
+		Emails:        []ExtractedEmail{{Email: "info@example.com", Domain: "example.com"}},
+		Phones:        []SiteContact{{Value: "+33123456789", Country: "FR"}},
+		Socials:       []SiteContact{{Value: "https://twitter.com/example"}},
+		Organizations: []SiteOrganization{{Type: "Organization", Name: "Example", Format: "jsonld"}},
+	}
+	if err := CompleteExtractItem(item, contacts, false); err != nil {
+		t.Fatal(err)
+	}
+
+	replay, _, _, cancel := SubscribeJobEvents(job.ID, "")
+	defer cancel()
+	got := map[string][]interface{}{}
+	for _, ev := range replay {
+		got[ev.Type] = append(got[ev.Type], ev.Data)
+	}
+	if len(got[JobEventResult]) != 1 || len(got[JobEventContact]) != 2 || len(got[JobEventOrganization]) != 1 {
+		t.Fatalf("events = %v", got)
+	}
+	if _, ok := got[JobEventResult][0].(model.ExtractResult); !ok {
+		t.Errorf("result data = %T, want model.ExtractResult", got[JobEventResult][0])
+	}
+	for _, d := range got[JobEventContact] {
+		if _, ok := d.(model.ExtractContact); !ok {
+			t.Errorf("contact data = %T, want model.ExtractContact", d)
+		}
+	}
+	if _, ok := got[JobEventOrganization][0].(model.ExtractOrganization); !ok {
+		t.Errorf("organization data = %T, want model.ExtractOrganization", got[JobEventOrganization][0])
+	}
+}
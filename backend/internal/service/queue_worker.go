@@ -0,0 +1,118 @@
+package service
+
+import (
+	"backend/internal/infra"
+	"backend/internal/model"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// Délai d'attente quand la file est vide
+const queuePollInterval = time.Second
+
+// NewWorkerID identifie le processus courant auprès de la file (host-pid)
+func NewWorkerID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
+// StartQueueWorkers lance n goroutines qui consomment la file jusqu'à l'annulation
+// du contexte; le WaitGroup permet d'attendre la fin des éléments en cours
+func StartQueueWorkers(ctx context.Context, workerID string, kinds []string, n int) *sync.WaitGroup {
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			runQueueWorker(ctx, workerID, kinds)
+		}()
+	}
+	return &wg
+}
+
+func runQueueWorker(ctx context.Context, workerID string, kinds []string) {
+	for ctx.Err() == nil {
+		items, err := ClaimWorkItems(workerID, kinds, 1)
+		if err != nil {
+			log.Printf("queue: claim failed: %v", err)
+		}
+		if len(items) == 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(queuePollInterval):
+			}
+			continue
+		}
+		for _, item := range items {
+			switch item.Kind {
+			case model.WorkKindVerify:
+				processVerifyItem(item)
+			case model.WorkKindExtract:
+				processExtractItem(item)
+			default:
+				log.Printf("queue: unknown work kind %q for item %d", item.Kind, item.ID)
+			}
+		}
+	}
+}
+
+// processVerifyItem vérifie un email de la file et persiste immédiatement son résultat
+func processVerifyItem(item model.WorkItem) {
+	start := time.Now()
+	// Aucune connexion SMTP pour une adresse de la liste de suppression; si la
+	// liste ne peut pas être chargée l'élément reste réservé plutôt que vérifié
+	suppressions, err := currentSuppressions()
+	if err != nil {
+		log.Printf("queue: cannot load suppression list for item %d of job %s: %v", item.ID, item.JobID, err)
+		return
+	}
+	if match := suppressions.match(item.Payload); match != nil {
+		if err := CompleteVerifyItem(item, suppressedResult(item, *match, start), false); err != nil {
+			log.Printf("queue: cannot complete item %d of job %s: %v", item.ID, item.JobID, err)
+		}
+		return
+	}
+	var res EmailValidationResult
+	for retry := 0; retry < 3; retry++ {
+		res, err = ValidateEmailSMTP(item.Payload)
+		if err == nil {
+			break
+		}
+		time.Sleep(10 * time.Second)
+	}
+	res.Email = item.Payload
+	res.JobID = item.JobID
+	res.RowIndex = item.RowIndex
+	res.CheckedAt = start
+	if err := CompleteVerifyItem(item, res, err != nil); err != nil {
+		// L'élément reste réservé et sera remis en file à l'expiration de la réservation
+		log.Printf("queue: cannot complete item %d of job %s: %v", item.ID, item.JobID, err)
+	}
+}
+
+// processExtractItem extrait les contacts d'un site de la file (selon le mode du job) et persiste le résultat
+func processExtractItem(item model.WorkItem) {
+	var job model.BulkJob
+	if err := infra.GetDB().Select("extract_mode", "ignore_robots").Where("id = ?", item.JobID).First(&job).Error; err != nil {
+		log.Printf("queue: cannot load job %s for item %d: %v", item.JobID, item.ID, err)
+		return
+	}
+	contacts, err := ExtractSite(item.Payload, job.ExtractMode, job.IgnoreRobots)
+	if err == nil {
+		if err := markSuppressed(contacts.Emails); err != nil {
+			log.Printf("queue: cannot load suppression list for item %d of job %s: %v", item.ID, item.JobID, err)
+			return
+		}
+	}
+	if err := CompleteExtractItem(item, contacts, err != nil); err != nil {
+		log.Printf("queue: cannot complete item %d of job %s: %v", item.ID, item.JobID, err)
+	}
+}
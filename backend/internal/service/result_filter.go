@@ -0,0 +1,208 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var ErrInvalidResultFilter = errors.New("invalid result filter")
+
+// Lignes du fichier sans adresse, conservées telles quelles (statut vide) pour
+// que le fichier enrichi ait toutes les lignes d'origine
+const resultNotBlank = "email <> ''"
+
+// Domaine d'un résultat (email_results n'a pas de colonne domaine)
+const resultDomainExpr = "LOWER(split_part(email, '@', 2))"
+
+// Codes de raison: motif LIKE des raisons enregistrées par la vérification
+var reasonCodes = map[string]string{
+	"invalid_syntax": "Invalid syntax",
+	"no_mx":          "No MX records",
+	"invalid_mx":     "Invalid MX host",
+	"smtp_rejected":  "SMTP rejected address",
+	"catch_all":      "Catch-all domain%",
+	"duplicate":      "Duplicate of %",
+	"suppressed":     "Suppressed (%",
+}
+
+// Colonnes de tri autorisées
+var resultSortColumns = map[string]string{
+	"rowIndex":   "row_index",
+	"email":      "email",
+	"domain":     resultDomainExpr,
+	"status":     "status",
+	"score":      "score",
+	"reason":     "reason",
+	"bounceType": "bounce_type",
+	"checkedAt":  "checked_at",
+	"roleBased":  "is_role_based",
+	"disposable": "is_disposable",
+	"catchAll":   "is_catch_all",
+	"free":       "is_free",
+	"suggestion": "suggestion",
+}
+
+// ResultFilter sélectionne et trie les résultats d'un job; il est partagé par la
+// liste paginée et tous les formats de téléchargement
+type ResultFilter struct {
+	Statuses       []string // unknown inclut les vérifications en échec
+	MinScore       *int
+	MaxScore       *int
+	Domains        []string
+	ExcludeDomains []string
+	RoleBased      *bool
+	Disposable     *bool
+	CatchAll       *bool
+	Free           *bool
+	BounceTypes    []string // hard, soft, none
+	ReasonCodes    []string // voir reasonCodes
+	Search         string   // sous-chaîne de l'adresse ou de la raison
+	Sort           string   // voir resultSortColumns, rowIndex par défaut
+	Desc           bool
+}
+
+// Validate vérifie les statuts, codes de raison, types de rejet et la colonne de tri
+func (f ResultFilter) Validate() error {
+	for _, s := range f.Statuses {
+		if !knownResultStatus(s) {
+			return fmt.Errorf("%w: unknown status %q", ErrInvalidResultFilter, s)
+		}
+	}
+	for _, code := range f.ReasonCodes {
+		if _, ok := reasonCodes[code]; !ok {
+			return fmt.Errorf("%w: unknown reason code %q, expected one of %s", ErrInvalidResultFilter, code, strings.Join(sortedKeys(reasonCodes), ", "))
+		}
+	}
+	for _, b := range f.BounceTypes {
+		if b != "hard" && b != "soft" && b != "none" {
+			return fmt.Errorf("%w: bounce type must be hard, soft or none", ErrInvalidResultFilter)
+		}
+	}
+	if _, ok := resultSortColumns[f.Sort]; f.Sort != "" && !ok {
+		return fmt.Errorf("%w: cannot sort by %q, expected one of %s", ErrInvalidResultFilter, f.Sort, strings.Join(sortedKeys(resultSortColumns), ", "))
+	}
+	if f.MinScore != nil && f.MaxScore != nil && *f.MinScore > *f.MaxScore {
+		return fmt.Errorf("%w: minScore is greater than maxScore", ErrInvalidResultFilter)
+	}
+	return nil
+}
+
+func knownResultStatus(s string) bool {
+	for _, known := range downloadStatuses {
+		if s == known {
+			return true
+		}
+	}
+	return false
+}
+
+// selects indique si le filtre restreint les résultats; seul un téléchargement
+// sans restriction contient les lignes sans adresse
+func (f ResultFilter) selects() bool {
+	return len(f.Statuses) > 0 || f.MinScore != nil || f.MaxScore != nil || len(f.Domains) > 0 ||
+		len(f.ExcludeDomains) > 0 || f.RoleBased != nil || f.Disposable != nil || f.CatchAll != nil ||
+		f.Free != nil || len(f.BounceTypes) > 0 || len(f.ReasonCodes) > 0 || strings.TrimSpace(f.Search) != ""
+}
+
+// apply ajoute les conditions du filtre à une requête sur email_results
+func (f ResultFilter) apply(q *gorm.DB) *gorm.DB {
+	if f.selects() {
+		q = q.Where(resultNotBlank)
+	}
+	if len(f.Statuses) > 0 {
+		statuses := append([]string{}, f.Statuses...)
+		for _, s := range f.Statuses {
+			if s == statusUnknown {
+				statuses = append(statuses, "")
+				break
+			}
+		}
+		q = q.Where("status IN ?", statuses)
+	}
+	if f.MinScore != nil {
+		q = q.Where("score >= ?", *f.MinScore)
+	}
+	if f.MaxScore != nil {
+		q = q.Where("score <= ?", *f.MaxScore)
+	}
+	if len(f.Domains) > 0 {
+		q = q.Where(resultDomainExpr+" IN ?", lowerAll(f.Domains))
+	}
+	if len(f.ExcludeDomains) > 0 {
+		q = q.Where(resultDomainExpr+" NOT IN ?", lowerAll(f.ExcludeDomains))
+	}
+	flags := []struct {
+		column string
+		value  *bool
+	}{
+		{"is_role_based", f.RoleBased},
+		{"is_disposable", f.Disposable},
+		{"is_catch_all", f.CatchAll},
+		{"is_free", f.Free},
+	}
+	for _, flag := range flags {
+		if flag.value != nil {
+			q = q.Where(flag.column+" = ?", *flag.value)
+		}
+	}
+	if len(f.BounceTypes) > 0 {
+		var types []string
+		none := false
+		for _, b := range f.BounceTypes {
+			if b == "none" {
+				none = true
+			} else {
+				types = append(types, b)
+			}
+		}
+		switch {
+		case none && len(types) > 0:
+			q = q.Where("bounce_type IS NULL OR bounce_type IN ?", types)
+		case none:
+			q = q.Where("bounce_type IS NULL")
+		default:
+			q = q.Where("bounce_type IN ?", types)
+		}
+	}
+	if len(f.ReasonCodes) > 0 {
+		conds := make([]string, len(f.ReasonCodes))
+		args := make([]interface{}, len(f.ReasonCodes))
+		for i, code := range f.ReasonCodes {
+			conds[i] = "reason LIKE ?"
+			args[i] = reasonCodes[code]
+		}
+		q = q.Where(strings.Join(conds, " OR "), args...)
+	}
+	if s := strings.TrimSpace(f.Search); s != "" {
+		q = q.Where("email ILIKE ? OR reason ILIKE ?", "%"+s+"%", "%"+s+"%")
+	}
+	return q
+}
+
+// order ajoute le tri; l'ordre du fichier départage les égalités
+func (f ResultFilter) order(q *gorm.DB) *gorm.DB {
+	if column, ok := resultSortColumns[f.Sort]; ok && f.Sort != "rowIndex" {
+		dir := " ASC"
+		if f.Desc {
+			dir = " DESC"
+		}
+		return q.Order(column + dir).Order("row_index ASC, checked_at ASC")
+	}
+	if f.Desc {
+		return q.Order("row_index DESC, checked_at DESC")
+	}
+	return q.Order("row_index ASC, checked_at ASC")
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
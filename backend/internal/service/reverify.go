@@ -0,0 +1,255 @@
+package service
+
+import (
+	"backend/internal/infra"
+	"backend/internal/model"
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrJobActive est retourné quand le job (ou une re-vérification) est encore en cours
+	ErrJobActive = errors.New("job is still running")
+	// ErrNothingToReverify est retourné quand aucun résultat ne correspond au filtre
+	ErrNothingToReverify = errors.New("no results match the filter")
+)
+
+// Statut sous lequel sont regroupés les résultats sans statut (vérification en échec)
+const statusUnknown = "unknown"
+
+// ReverifyFilter sélectionne les résultats du job parent à vérifier de nouveau
+type ReverifyFilter struct {
+	Statuses []string `json:"statuses"` // par défaut unknown et accept_all
+	Reason   string   `json:"reason"`   // sous-chaîne de la raison, insensible à la casse
+}
+
+// ReverifyBulkJob crée un job enfant qui vérifie de nouveau les lignes du job
+// parent correspondant au filtre; chaque nouveau résultat remplace celui du
+// parent (voir mergeIntoParentJob), le job enfant gardant l'historique. Re-vérifier
+// un job enfant re-vérifie le job d'origine, qui porte les derniers résultats. Le
+// job est retourné aussitôt, les lignes sont mises en file par lots en arrière-plan.
+func ReverifyBulkJob(parentId string, filter ReverifyFilter) (model.BulkJob, error) {
+	return reverifyBulkJob(parentId, filter, nil)
+}
+
+// reverifyBulkJob crée le job enfant, rattaché à scheduleId pour une re-vérification planifiée
+func reverifyBulkJob(parentId string, filter ReverifyFilter, scheduleId *string) (model.BulkJob, error) {
+	child, err := createReverifyJob(parentId, filter, scheduleId)
+	if err != nil {
+		return model.BulkJob{}, err
+	}
+	go runReverifyIngestion(child.ID, *child.ParentJobID, filter)
+	return child, nil
+}
+
+// createReverifyJob crée le job enfant en cours d'ingestion
+func createReverifyJob(jobId string, filter ReverifyFilter, scheduleId *string) (model.BulkJob, error) {
+	db := infra.GetDB()
+	parentId, err := rootJobID(db, jobId)
+	if err != nil {
+		return model.BulkJob{}, err
+	}
+	var child model.BulkJob
+	// Le parent reste verrouillé jusqu'à la création du job enfant: deux demandes
+	// simultanées ne créent pas deux re-vérifications
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var parent model.BulkJob
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", parentId).First(&parent).Error; err != nil {
+			return err
+		}
+		if parent.Kind != model.WorkKindVerify {
+			return ErrInvalidJobTransition
+		}
+		if IsJobActive(parent.Status) {
+			return ErrJobActive
+		}
+
+		var running int64
+		if err := tx.Model(&model.BulkJob{}).
+			Where("parent_job_id = ? AND status IN ?", parentId, []string{model.JobStatusQueued, model.JobStatusProcessing, model.JobStatusPaused}).
+			Count(&running).Error; err != nil {
+			return err
+		}
+		if running > 0 {
+			return ErrJobActive
+		}
+
+		var first []int64
+		if err := reverifyResultsQuery(tx, parentId, filter).Limit(1).Pluck("id", &first).Error; err != nil {
+			return err
+		}
+		if len(first) == 0 {
+			return ErrNothingToReverify
+		}
+
+		child = model.BulkJob{
+			Kind:         model.WorkKindVerify,
+			FileName:     parent.FileName,
+			DisplayName:  parent.DisplayName,
+			Headers:      parent.Headers,
+			SourceFormat: parent.SourceFormat,
+			SourceSheet:  parent.SourceSheet,
+			ParentJobID:  &parent.ID,
+			ScheduleID:   scheduleId,
+			UploadedAt:   time.Now(),
+			Status:       model.JobStatusQueued,
+			Ingesting:    true,
+			Priority:     parent.Priority,
+			Owner:        parent.Owner,
+		}
+		return tx.Create(&child).Error
+	})
+	if err != nil {
+		return model.BulkJob{}, err
+	}
+	return child, nil
+}
+
+// rootJobID retourne le job d'origine d'une chaîne de re-vérifications
+func rootJobID(db *gorm.DB, jobId string) (string, error) {
+	for {
+		var job model.BulkJob
+		if err := db.Select("id", "parent_job_id").Where("id = ?", jobId).First(&job).Error; err != nil {
+			return "", err
+		}
+		if job.ParentJobID == nil {
+			return job.ID, nil
+		}
+		jobId = *job.ParentJobID
+	}
+}
+
+// reverifyResultsQuery sélectionne les résultats du parent correspondant au filtre
+func reverifyResultsQuery(db *gorm.DB, parentId string, filter ReverifyFilter) *gorm.DB {
+	statuses := filter.Statuses
+	if len(statuses) == 0 {
+		statuses = []string{statusUnknown, string(StatusAcceptAll)}
+	}
+	for _, s := range statuses {
+		if s == statusUnknown {
+			statuses = append(statuses, "")
+			break
+		}
+	}
+	q := db.Model(&model.EmailResult{}).Where("job_id = ? AND status IN ?", parentId, statuses).Where(resultNotBlank)
+	if reason := strings.TrimSpace(filter.Reason); reason != "" {
+		q = q.Where("reason ILIKE ?", "%"+reason+"%")
+	}
+	return q
+}
+
+func runReverifyIngestion(jobId, parentId string, filter ReverifyFilter) {
+	if err := enqueueReverifyRows(jobId, parentId, filter); err != nil {
+		log.Printf("ingest job %s: %v", jobId, err)
+		failIngestion(jobId, err)
+	}
+}
+
+// enqueueReverifyRows met en file les lignes du parent correspondant au filtre,
+// lot par lot comme les membres d'une liste (voir enqueueListMembers)
+func enqueueReverifyRows(jobId, parentId string, filter ReverifyFilter) error {
+	job, err := GetBulkJobByID(jobId)
+	if err != nil {
+		return err
+	}
+	var batch []model.EmailResult
+	err = reverifyResultsQuery(infra.GetDB(), parentId, filter).Order("row_index ASC").FindInBatches(&batch, ingestBatchSize, func(_ *gorm.DB, _ int) error {
+		rows := make([]SourceRow, len(batch))
+		for i, r := range batch {
+			var columns []string
+			json.Unmarshal([]byte(r.SourceRow), &columns)
+			rows[i] = SourceRow{Index: r.RowIndex, Value: r.Email, Columns: columns}
+		}
+		return queueRowsBatch(job, rows)
+	}).Error
+	if err != nil {
+		return err
+	}
+	return finishQueueing(jobId)
+}
+
+// mergeVerifiedResult reporte le résultat d'un job de re-vérification sur le
+// job d'origine, ou celui d'un job de vérification de liste sur le membre
+func mergeVerifiedResult(tx *gorm.DB, jobId string, m model.EmailResult) error {
+	var job model.BulkJob
+	if err := tx.Select("parent_job_id", "list_id").Where("id = ?", jobId).First(&job).Error; err != nil {
+		return err
+	}
+	switch {
+	case job.ParentJobID != nil:
+		// Job enfant créé avant que la re-vérification remonte au job d'origine
+		rootId, err := rootJobID(tx, *job.ParentJobID)
+		if err != nil {
+			return err
+		}
+		return mergeIntoParentJob(tx, jobId, rootId, m)
+	case job.ListID != nil:
+		return mergeIntoList(tx, jobId, *job.ListID, m)
+	}
+	return nil
+}
+
+// mergeIntoParentJob remplace, dans le job parent, le résultat de la ligne
+// re-vérifiée par le plus récent: vue paginée, compteurs et téléchargements du
+// parent reflètent ainsi la dernière vérification. Un changement de statut est
+// enregistré pour le rapport de changements du job enfant.
+//
+// La ligne est retrouvée par sa position et son adresse: les jobs antérieurs à
+// la conservation des lignes d'origine ont toutes leurs lignes en position 0,
+// l'adresse les distingue alors (les doublons écartés gardent leur statut).
+// Une seule requête lit l'ancien statut et écrit le nouveau.
+func mergeIntoParentJob(tx *gorm.DB, jobId, parentId string, m model.EmailResult) error {
+	var previous []struct {
+		Status     string
+		IsCatchAll bool
+	}
+	err := tx.Raw(`UPDATE email_results AS e
+		SET is_valid = ?, status = ?, reason = ?, bounce_type = ?, checked_at = ?, score = ?,
+			is_role_based = ?, is_disposable = ?, is_catch_all = ?, is_free = ?, suggestion = ?
+		FROM (SELECT id, status, is_catch_all FROM email_results
+			WHERE job_id = ? AND row_index = ? AND email = ? AND status <> ? FOR UPDATE) AS prev
+		WHERE e.id = prev.id
+		RETURNING prev.status, prev.is_catch_all`,
+		m.IsValid, m.Status, m.Reason, m.BounceType, m.CheckedAt, m.Score,
+		m.IsRoleBased, m.IsDisposable, m.IsCatchAll, m.IsFree, m.Suggestion,
+		parentId, m.RowIndex, m.Email, string(StatusDuplicate)).
+		Scan(&previous).Error
+	if err != nil {
+		return err
+	}
+	var prevStatus string
+	var prevCatchAll bool
+	if len(previous) > 0 {
+		prevStatus, prevCatchAll = previous[0].Status, previous[0].IsCatchAll
+	}
+	change := model.StatusChange{JobID: jobId, ParentJobID: &parentId}
+	return recordStatusChange(tx, change, prevStatus, prevCatchAll, m)
+}
+
+// recordStatusChange enregistre change si le statut de l'adresse a changé ou si
+// son domaine est devenu catch-all
+func recordStatusChange(tx *gorm.DB, change model.StatusChange, prevStatus string, prevCatchAll bool, m model.EmailResult) error {
+	change.RowIndex = m.RowIndex
+	change.Email = m.Email
+	change.FromStatus = resultStatus(prevStatus)
+	change.ToStatus = resultStatus(m.Status)
+	change.NewlyCatchAll = m.IsCatchAll && !prevCatchAll
+	if change.FromStatus == change.ToStatus && !change.NewlyCatchAll {
+		return nil
+	}
+	return tx.Create(&change).Error
+}
+
+// resultStatus retourne le statut d'un résultat, unknown pour une vérification en échec
+func resultStatus(status string) string {
+	if status == "" {
+		return statusUnknown
+	}
+	return status
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"backend/internal/model"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// createTestParentJob crée un job terminé avec les résultats donnés
+func createTestParentJob(t *testing.T, results []model.EmailResult) model.BulkJob {
+	t.Helper()
+	db := testDB(t)
+	job := model.BulkJob{Kind: model.WorkKindVerify, FileName: "parent.csv", Status: model.JobStatusDone, UploadedAt: time.Now()}
+	if err := db.Create(&job).Error; err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { deleteTestJob(db, job.ID) })
+	for i := range results {
+		results[i].JobID = job.ID
+		results[i].CheckedAt = time.Now()
+	}
+	if err := db.Create(&results).Error; err != nil {
+		t.Fatal(err)
+	}
+	return job
+}
+
+// reverifyTestJob re-vérifie le parent et termine chaque élément avec le statut donné par adresse
+func reverifyTestJob(t *testing.T, parentId string, statuses map[string]EmailStatus) model.BulkJob {
+	t.Helper()
+	db := testDB(t)
+	// Mise en file entière avant de consommer (sans passer par la goroutine de ReverifyBulkJob)
+	filter := ReverifyFilter{Statuses: []string{statusUnknown}}
+	child, err := createReverifyJob(parentId, filter, nil)
+	if err != nil {
+		t.Fatalf("reverify: %v", err)
+	}
+	t.Cleanup(func() { deleteTestJob(db, child.ID) })
+	if err := enqueueReverifyRows(child.ID, *child.ParentJobID, filter); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	if child, _ = GetBulkJobByID(child.ID); child.Ingesting || child.TotalEmails == 0 {
+		t.Fatalf("child after enqueue: ingesting = %v, total = %d", child.Ingesting, child.TotalEmails)
+	}
+	for _, item := range claimTestItems(t, child.ID, "worker-a") {
+		res := EmailValidationResult{JobID: child.ID, Email: item.Payload, RowIndex: item.RowIndex, Status: statuses[item.Payload], CheckedAt: time.Now()}
+		if err := CompleteVerifyItem(item, res, false); err != nil {
+			t.Fatalf("complete %s: %v", item.Payload, err)
+		}
+	}
+	return child
+}
+
+func parentStatuses(t *testing.T, jobId string) map[string][]string {
+	t.Helper()
+	var results []model.EmailResult
+	testDB(t).Where("job_id = ?", jobId).Order("email, status").Find(&results)
+	got := map[string][]string{}
+	for _, r := range results {
+		got[r.Email] = append(got[r.Email], r.Status)
+	}
+	return got
+}
+
+func TestMergeIntoParentJobByRow(t *testing.T) {
+	parent := createTestParentJob(t, []model.EmailResult{
+		{RowIndex: 0, Email: "a@example.com", Status: statusUnknown},
+		{RowIndex: 1, Email: "b@example.com", Status: statusUnknown},
+		{RowIndex: 2, Email: "c@example.com", Status: string(StatusValid)},
+	})
+	child := reverifyTestJob(t, parent.ID, map[string]EmailStatus{"a@example.com": StatusValid, "b@example.com": StatusInvalid})
+
+	got := parentStatuses(t, parent.ID)
+	if got["a@example.com"][0] != "valid" || got["b@example.com"][0] != "invalid" || got["c@example.com"][0] != "valid" {
+		t.Fatalf("parent statuses = %v", got)
+	}
+	var changes []model.StatusChange
+	testDB(t).Where("job_id = ?", child.ID).Order("email").Find(&changes)
+	if len(changes) != 2 || changes[0].FromStatus != statusUnknown || changes[0].ToStatus != "valid" || changes[1].ToStatus != "invalid" {
+		t.Fatalf("changes = %+v", changes)
+	}
+}
+
+// Job antérieur aux lignes d'origine: toutes les lignes en position 0, la
+// fusion retrouve chaque ligne par son adresse
+func TestMergeIntoParentJobLegacyRows(t *testing.T) {
+	parent := createTestParentJob(t, []model.EmailResult{
+		{Email: "a@example.com", Status: statusUnknown},
+		{Email: "b@example.com", Status: statusUnknown},
+		{Email: "c@example.com", Status: string(StatusAcceptAll)},
+		{Email: "a@example.com", Status: string(StatusDuplicate)},
+	})
+	reverifyTestJob(t, parent.ID, map[string]EmailStatus{"a@example.com": StatusValid, "b@example.com": StatusInvalid})
+
+	got := parentStatuses(t, parent.ID)
+	want := map[string][]string{
+		"a@example.com": {"duplicate", "valid"},
+		"b@example.com": {"invalid"},
+		"c@example.com": {"accept_all"},
+	}
+	for email, statuses := range want {
+		if len(got[email]) != len(statuses) || got[email][0] != statuses[0] || got[email][len(statuses)-1] != statuses[len(statuses)-1] {
+			t.Errorf("%s: statuses = %v, want %v", email, got[email], statuses)
+		}
+	}
+}
+
+// Re-vérifier un job enfant re-vérifie le job d'origine: c'est lui qui reçoit
+// les nouveaux résultats
+func TestReverifyChildTargetsRoot(t *testing.T) {
+	db := testDB(t)
+	root := createTestParentJob(t, []model.EmailResult{
+		{RowIndex: 0, Email: "a@example.com", Status: statusUnknown},
+		{RowIndex: 1, Email: "b@example.com", Status: statusUnknown},
+	})
+	first := reverifyTestJob(t, root.ID, map[string]EmailStatus{"b@example.com": StatusValid})
+	second := reverifyTestJob(t, first.ID, map[string]EmailStatus{"a@example.com": StatusInvalid})
+	if second.ParentJobID == nil || *second.ParentJobID != root.ID {
+		t.Fatalf("second parent = %v, want %s", second.ParentJobID, root.ID)
+	}
+	got := parentStatuses(t, root.ID)
+	if got["a@example.com"][0] != "invalid" || got["b@example.com"][0] != "valid" {
+		t.Fatalf("root statuses = %v", got)
+	}
+
+	// Job enfant d'un enfant (antérieur à ce rattachement): fusion dans le job d'origine
+	legacy := model.BulkJob{Kind: model.WorkKindVerify, FileName: "legacy.csv", Status: model.JobStatusProcessing, ParentJobID: &first.ID, UploadedAt: time.Now()}
+	if err := db.Create(&legacy).Error; err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { deleteTestJob(db, legacy.ID) })
+	res := model.EmailResult{RowIndex: 1, Email: "b@example.com", Status: string(StatusInvalid), CheckedAt: time.Now()}
+	if err := mergeVerifiedResult(db, legacy.ID, res); err != nil {
+		t.Fatal(err)
+	}
+	if got := parentStatuses(t, root.ID); got["b@example.com"][0] != "invalid" {
+		t.Errorf("root statuses after legacy merge = %v", got)
+	}
+}
+
+// Des demandes simultanées ne créent qu'une re-vérification
+func TestReverifyConcurrentRequests(t *testing.T) {
+	db := testDB(t)
+	root := createTestParentJob(t, []model.EmailResult{{RowIndex: 0, Email: "a@example.com", Status: statusUnknown}})
+	t.Cleanup(func() {
+		var ids []string
+		db.Model(&model.BulkJob{}).Where("parent_job_id = ?", root.ID).Pluck("id", &ids)
+		for _, id := range ids {
+			deleteTestJob(db, id)
+		}
+	})
+
+	const requests = 5
+	errs := make([]error, requests)
+	var wg sync.WaitGroup
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = createReverifyJob(root.ID, ReverifyFilter{}, nil)
+		}(i)
+	}
+	wg.Wait()
+
+	created := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			created++
+		case !errors.Is(err, ErrJobActive):
+			t.Errorf("err = %v, want ErrJobActive", err)
+		}
+	}
+	var children int64
+	db.Model(&model.BulkJob{}).Where("parent_job_id = ?", root.ID).Count(&children)
+	if created != 1 || children != 1 {
+		t.Errorf("created = %d, children = %d, want 1", created, children)
+	}
+}
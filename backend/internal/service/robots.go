@@ -0,0 +1,148 @@
+package service
+
+import (
+	"compress/gzip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/temoto/robotstxt"
+)
+
+// Limites de lecture de robots.txt (hors budget de pages) et des sitemaps (comptés
+// dans le budget de pages, voir crawlSite)
+const (
+	maxRobotsBytes  = 512 << 10
+	maxSitemapBytes = 1 << 20          // par fichier, après décompression; les pages lues avant la limite sont gardées
+	maxSitemaps     = 3                // fichiers sitemap lus au plus (index compris)
+	maxCrawlDelay   = 10 * time.Second // Crawl-delay plus long ramené à cette valeur
+)
+
+// robotsPolicy applique les règles robots.txt d'un site pour l'agent du crawler;
+// un robots.txt absent (4xx) ou illisible autorise tout, une erreur serveur (5xx) interdit tout
+type robotsPolicy struct {
+	group       *robotstxt.Group
+	sitemaps    []string
+	disallowAll bool // FindGroup ne conserve pas le « tout interdit » d'une réponse 5xx
+}
+
+func fetchRobots(client *http.Client, base *url.URL, userAgent string) robotsPolicy {
+	robotsURL := base.Scheme + "://" + base.Host + "/robots.txt"
+	req, err := http.NewRequest(http.MethodGet, robotsURL, nil)
+	if err != nil {
+		return robotsPolicy{}
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := client.Do(req)
+	if err != nil {
+		return robotsPolicy{}
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRobotsBytes))
+	if err != nil {
+		return robotsPolicy{}
+	}
+	data, err := robotstxt.FromStatusAndBytes(resp.StatusCode, body)
+	if err != nil {
+		return robotsPolicy{}
+	}
+	if resp.StatusCode >= 500 {
+		return robotsPolicy{disallowAll: true}
+	}
+	return robotsPolicy{group: data.FindGroup(userAgent), sitemaps: data.Sitemaps}
+}
+
+// allowed indique si robots.txt autorise la page
+func (p robotsPolicy) allowed(u *url.URL) bool {
+	if p.disallowAll {
+		return false
+	}
+	if p.group == nil {
+		return true
+	}
+	return p.group.Test(u.RequestURI())
+}
+
+// crawlDelay retourne le Crawl-delay du site, borné à maxCrawlDelay
+func (p robotsPolicy) crawlDelay() time.Duration {
+	if p.group == nil {
+		return 0
+	}
+	return min(p.group.CrawlDelay, maxCrawlDelay)
+}
+
+// sitemapDoc lit un sitemap (urlset) ou un index de sitemaps (sitemapindex)
+type sitemapDoc struct {
+	URLs []struct {
+		Loc string `xml:"loc"`
+	} `xml:"url"`
+	Sitemaps []struct {
+		Loc string `xml:"loc"`
+	} `xml:"sitemap"`
+}
+
+// sitemapURLs retourne les pages listées par les sitemaps du site: ceux déclarés
+// dans robots.txt, sinon /sitemap.xml; les index sont suivis jusqu'à maxFiles
+// fichiers. Retourne aussi le nombre de fichiers demandés.
+func sitemapURLs(client *http.Client, base *url.URL, policy robotsPolicy, userAgent string, maxFiles int) ([]string, int) {
+	queue := policy.sitemaps
+	if len(queue) == 0 {
+		queue = []string{base.Scheme + "://" + base.Host + "/sitemap.xml"}
+	}
+	var pages []string
+	read := map[string]bool{}
+	for len(queue) > 0 && len(read) < maxFiles {
+		loc := strings.TrimSpace(queue[0])
+		queue = queue[1:]
+		u, err := url.Parse(loc)
+		if err != nil || read[loc] || !policy.allowed(u) {
+			continue
+		}
+		read[loc] = true
+		// Un fichier tronqué à maxSitemapBytes garde les entrées lues avant la coupure
+		doc, err := fetchSitemap(client, loc, userAgent)
+		if err != nil && len(doc.URLs) == 0 && len(doc.Sitemaps) == 0 {
+			continue
+		}
+		for _, s := range doc.Sitemaps {
+			queue = append(queue, s.Loc)
+		}
+		for _, page := range doc.URLs {
+			pages = append(pages, strings.TrimSpace(page.Loc))
+		}
+	}
+	return pages, len(read)
+}
+
+func fetchSitemap(client *http.Client, loc, userAgent string) (sitemapDoc, error) {
+	var doc sitemapDoc
+	req, err := http.NewRequest(http.MethodGet, loc, nil)
+	if err != nil {
+		return doc, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := client.Do(req)
+	if err != nil {
+		return doc, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return doc, fmt.Errorf("sitemap %s: %s", loc, resp.Status)
+	}
+	var body io.Reader = io.LimitReader(resp.Body, maxSitemapBytes)
+	// sitemap.xml.gz (le transport décompresse déjà Content-Encoding: gzip)
+	if strings.HasSuffix(strings.ToLower(req.URL.Path), ".gz") {
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			return doc, err
+		}
+		defer gz.Close()
+		body = io.LimitReader(gz, maxSitemapBytes)
+	}
+	err = xml.NewDecoder(body).Decode(&doc)
+	return doc, err
+}
@@ -0,0 +1,300 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+)
+
+// testSite sert des réponses fixes par chemin et note les chemins demandés
+type testSite struct {
+	*httptest.Server
+	mu     sync.Mutex
+	status map[string]int
+	bodies map[string][]byte
+	hits   []string
+}
+
+func newTestSite(t *testing.T, bodies map[string]string) *testSite {
+	t.Helper()
+	s := &testSite{status: map[string]int{}, bodies: map[string][]byte{}}
+	for p, b := range bodies {
+		s.bodies[p] = []byte(b)
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.hits = append(s.hits, r.URL.Path)
+		status := s.status[r.URL.Path]
+		body, ok := s.bodies[r.URL.Path]
+		s.mu.Unlock()
+		if status != 0 {
+			w.WriteHeader(status)
+			return
+		}
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, ".html") || r.URL.Path == "/" {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		}
+		w.Write(body)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *testSite) requested(path string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, h := range s.hits {
+		if h == path {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *testSite) base(t *testing.T) *url.URL {
+	t.Helper()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+func urlset(locs ...string) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?><urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`)
+	for _, loc := range locs {
+		fmt.Fprintf(&b, "<url><loc>%s</loc></url>", loc)
+	}
+	b.WriteString("</urlset>")
+	return b.String()
+}
+
+func sitemapIndex(locs ...string) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?><sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`)
+	for _, loc := range locs {
+		fmt.Fprintf(&b, "<sitemap><loc>%s</loc></sitemap>", loc)
+	}
+	b.WriteString("</sitemapindex>")
+	return b.String()
+}
+
+func TestFetchRobots(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		robots  string
+		allowed map[string]bool
+		delay   time.Duration
+	}{
+		{
+			name:    "Disallow pour tous les agents",
+			robots:  "User-agent: *\nDisallow: /private\n",
+			allowed: map[string]bool{"/": true, "/contact": true, "/private": false, "/private/team": false},
+		},
+		{
+			name:    "Allow plus précis que Disallow",
+			robots:  "User-agent: *\nDisallow: /\nAllow: /contact\n",
+			allowed: map[string]bool{"/": false, "/about": false, "/contact": true},
+		},
+		{
+			name:    "Crawl-delay",
+			robots:  "User-agent: *\nCrawl-delay: 2\n",
+			allowed: map[string]bool{"/": true},
+			delay:   2 * time.Second,
+		},
+		{
+			name:    "Crawl-delay borné",
+			robots:  "User-agent: *\nCrawl-delay: 120\n",
+			allowed: map[string]bool{"/": true},
+			delay:   maxCrawlDelay,
+		},
+		// robots.txt absent: tout est permis
+		{name: "404", status: http.StatusNotFound, allowed: map[string]bool{"/": true, "/contact": true}},
+		// Erreur serveur: rien n'est permis
+		{name: "503", status: http.StatusServiceUnavailable, allowed: map[string]bool{"/": false, "/contact": false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			site := newTestSite(t, map[string]string{"/robots.txt": tt.robots})
+			if tt.status != 0 {
+				site.status["/robots.txt"] = tt.status
+			}
+			policy := fetchRobots(site.Client(), site.base(t), "mailhound-test")
+			for p, want := range tt.allowed {
+				if got := policy.allowed(&url.URL{Path: p}); got != want {
+					t.Errorf("allowed(%q) = %v, want %v", p, got, want)
+				}
+			}
+			if got := policy.crawlDelay(); got != tt.delay {
+				t.Errorf("crawlDelay() = %v, want %v", got, tt.delay)
+			}
+		})
+	}
+}
+
+func TestSitemapURLs(t *testing.T) {
+	t.Run("sitemap déclaré dans robots.txt et index suivi", func(t *testing.T) {
+		site := newTestSite(t, nil)
+		site.bodies["/robots.txt"] = []byte("User-agent: *\nSitemap: " + site.URL + "/index.xml\n")
+		site.bodies["/index.xml"] = []byte(sitemapIndex(site.URL+"/pages.xml", site.URL+"/more.xml.gz"))
+		site.bodies["/pages.xml"] = []byte(urlset(site.URL+"/contact", site.URL+"/about"))
+		var gz bytes.Buffer
+		zw := gzip.NewWriter(&gz)
+		zw.Write([]byte(urlset(site.URL + "/team")))
+		zw.Close()
+		site.bodies["/more.xml.gz"] = gz.Bytes()
+
+		policy := fetchRobots(site.Client(), site.base(t), "mailhound-test")
+		pages, read := sitemapURLs(site.Client(), site.base(t), policy, "mailhound-test", maxSitemaps)
+		want := []string{site.URL + "/contact", site.URL + "/about", site.URL + "/team"}
+		if strings.Join(pages, " ") != strings.Join(want, " ") {
+			t.Errorf("pages = %v, want %v", pages, want)
+		}
+		if read != 3 {
+			t.Errorf("read = %d, want 3", read)
+		}
+		if site.requested("/sitemap.xml") {
+			t.Error("/sitemap.xml demandé alors que robots.txt déclare un sitemap")
+		}
+	})
+
+	t.Run("limite de fichiers", func(t *testing.T) {
+		site := newTestSite(t, nil)
+		site.bodies["/sitemap.xml"] = []byte(sitemapIndex(site.URL+"/a.xml", site.URL+"/b.xml", site.URL+"/c.xml"))
+		for _, name := range []string{"a", "b", "c"} {
+			site.bodies["/"+name+".xml"] = []byte(urlset(site.URL + "/" + name))
+		}
+		pages, read := sitemapURLs(site.Client(), site.base(t), robotsPolicy{}, "mailhound-test", 2)
+		if len(pages) != 1 || pages[0] != site.URL+"/a" {
+			t.Errorf("pages = %v, want [%s/a]", pages, site.URL)
+		}
+		if read != 2 {
+			t.Errorf("read = %d, want 2", read)
+		}
+		if site.requested("/b.xml") || site.requested("/c.xml") {
+			t.Error("sitemap lu au-delà de la limite")
+		}
+	})
+
+	t.Run("taille maximale", func(t *testing.T) {
+		site := newTestSite(t, nil)
+		// Un sitemap plus grand que maxSitemapBytes: les pages lues avant la coupure sont gardées
+		var b strings.Builder
+		b.WriteString(`<?xml version="1.0" encoding="UTF-8"?><urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`)
+		fmt.Fprintf(&b, "<url><loc>%s/contact</loc></url>", site.URL)
+		for b.Len() < maxSitemapBytes {
+			fmt.Fprintf(&b, "<url><loc>%s/filler</loc></url>", site.URL)
+		}
+		fmt.Fprintf(&b, "<url><loc>%s/beyond</loc></url></urlset>", site.URL)
+		site.bodies["/sitemap.xml"] = []byte(b.String())
+
+		pages, _ := sitemapURLs(site.Client(), site.base(t), robotsPolicy{}, "mailhound-test", maxSitemaps)
+		if len(pages) == 0 || pages[0] != site.URL+"/contact" {
+			t.Fatalf("première page = %v, want %s/contact", pages[:min(len(pages), 1)], site.URL)
+		}
+		for _, p := range pages {
+			if p == site.URL+"/beyond" {
+				t.Fatal("page lue au-delà de maxSitemapBytes")
+			}
+		}
+	})
+}
+
+func TestCrawlSiteRobotsAndSitemaps(t *testing.T) {
+	site := newTestSite(t, map[string]string{
+		"/":                     `<html><body><a href="/private/contact.html">Contact</a><a href="/about.html">About</a></body></html>`,
+		"/about.html":           `<html><body>about</body></html>`,
+		"/contact.html":         `<html><body>contact</body></html>`,
+		"/private/contact.html": `<html><body>private</body></html>`,
+		"/robots.txt":           "User-agent: *\nDisallow: /private\n",
+	})
+	site.bodies["/sitemap.xml"] = []byte(urlset(site.URL + "/contact.html"))
+
+	crawl := func(opts crawlOptions) ([]string, []string) {
+		t.Helper()
+		site.mu.Lock()
+		site.hits = nil
+		site.mu.Unlock()
+		var pages []string
+		skipped, err := crawlSite(site.URL+"/", opts, func(e *colly.HTMLElement) {
+			pages = append(pages, e.Request.URL.Path)
+		}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pages, skipped
+	}
+
+	t.Run("mode rapide sans sitemaps", func(t *testing.T) {
+		pages, skipped := crawl(crawlOptions{maxPages: 3, maxDepth: 1, onlyScored: true, timeout: 5 * time.Second})
+		if site.requested("/sitemap.xml") {
+			t.Error("sitemap lu en mode rapide")
+		}
+		if want := "/ /about.html"; strings.Join(pages, " ") != want {
+			t.Errorf("pages = %v, want %s", pages, want)
+		}
+		if len(skipped) != 1 || !strings.HasSuffix(skipped[0], "/private/contact.html") {
+			t.Errorf("skipped = %v, want [.../private/contact.html]", skipped)
+		}
+	})
+
+	t.Run("sitemaps dans le budget de pages", func(t *testing.T) {
+		// robots.txt hors budget, sitemap.xml + 2 pages
+		pages, _ := crawl(crawlOptions{maxPages: 3, maxDepth: 1, timeout: 5 * time.Second, sitemaps: true})
+		if !site.requested("/sitemap.xml") {
+			t.Error("sitemap non lu")
+		}
+		if want := "/ /contact.html"; strings.Join(pages, " ") != want {
+			t.Errorf("pages = %v, want %s", pages, want)
+		}
+	})
+
+	t.Run("budget d'une page", func(t *testing.T) {
+		pages, _ := crawl(crawlOptions{maxPages: 1, maxDepth: 1, timeout: 5 * time.Second, sitemaps: true})
+		if site.requested("/sitemap.xml") {
+			t.Error("sitemap lu alors que le budget ne laisse que la page de départ")
+		}
+		if want := "/"; strings.Join(pages, " ") != want {
+			t.Errorf("pages = %v, want %s", pages, want)
+		}
+	})
+
+	t.Run("ignoreRobots en mode rapide", func(t *testing.T) {
+		pages, skipped := crawl(crawlOptions{maxPages: 3, maxDepth: 1, timeout: 5 * time.Second, ignoreRobots: true})
+		if site.requested("/robots.txt") || site.requested("/sitemap.xml") {
+			t.Error("robots.txt ou sitemap lu avec ignoreRobots sans sitemaps")
+		}
+		if len(skipped) != 0 || len(pages) != 3 {
+			t.Errorf("pages = %v, skipped = %v, want 3 pages et aucune ignorée", pages, skipped)
+		}
+	})
+
+	t.Run("ignoreRobots garde les sitemaps", func(t *testing.T) {
+		// sitemap.xml + 3 pages; /private n'est plus interdit
+		pages, skipped := crawl(crawlOptions{maxPages: 4, maxDepth: 1, timeout: 5 * time.Second, sitemaps: true, ignoreRobots: true})
+		if !site.requested("/sitemap.xml") {
+			t.Error("sitemap non lu avec ignoreRobots")
+		}
+		if want := "/ /contact.html /private/contact.html"; strings.Join(pages, " ") != want {
+			t.Errorf("pages = %v, want %s", pages, want)
+		}
+		if len(skipped) != 0 {
+			t.Errorf("skipped = %v, want aucune page ignorée", skipped)
+		}
+	})
+}
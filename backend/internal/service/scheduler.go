@@ -0,0 +1,140 @@
+package service
+
+import (
+	"backend/internal/infra"
+	"backend/internal/model"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Nombre de jobs candidats essayés à chaque réservation (les éléments du premier
+// peuvent être tous réservés par d'autres workers entre-temps)
+const schedulerCandidates = 5
+
+// Fenêtre utilisée pour mesurer le débit de la file
+const throughputWindow = 5 * time.Minute
+
+var ErrInvalidPriority = errors.New("invalid priority, expected low, normal, high, urgent or -1..2")
+
+var priorityNames = map[string]int{
+	"low":    model.JobPriorityLow,
+	"normal": model.JobPriorityNormal,
+	"high":   model.JobPriorityHigh,
+	"urgent": model.JobPriorityUrgent,
+}
+
+// ParsePriority accepte un nom de niveau ou sa valeur; vide = normal
+func ParsePriority(v string) (int, error) {
+	v = strings.ToLower(strings.TrimSpace(v))
+	if v == "" {
+		return model.JobPriorityNormal, nil
+	}
+	if p, ok := priorityNames[v]; ok {
+		return p, nil
+	}
+	p, err := strconv.Atoi(v)
+	if err != nil || p < model.JobPriorityLow || p > model.JobPriorityUrgent {
+		return 0, ErrInvalidPriority
+	}
+	return p, nil
+}
+
+// nextJobsToServe retourne, dans l'ordre où ils doivent être servis, les jobs
+// actifs qui ont des éléments en attente:
+//  1. priorité la plus haute d'abord;
+//  2. à priorité égale, le propriétaire qui a le moins d'éléments en cours,
+//     pour qu'un gros upload n'occupe pas tous les workers;
+//  3. puis le job de ce propriétaire qui a le moins d'éléments en cours;
+//  4. puis le plus ancien.
+//
+// Un petit job obtient ainsi un worker dès qu'un élément se termine. Les éléments
+// en cours sont lus dans le compteur leased_items du job, tenu à jour à chaque
+// réservation, clôture et remise en file: aucun comptage de work_items par réservation.
+func nextJobsToServe(tx *gorm.DB, kinds []string, limit int) ([]string, error) {
+	var ids []string
+	err := tx.Raw(`
+		WITH active AS (
+			SELECT j.id, j.priority, COALESCE(j.owner, '') AS owner, j.uploaded_at, j.leased_items AS job_leased
+			FROM bulk_jobs j
+			WHERE j.status IN ?
+				AND EXISTS (SELECT 1 FROM work_items w WHERE w.job_id = j.id AND w.status = ? AND w.kind IN ?)
+		), owners AS (
+			SELECT owner, sum(job_leased) AS owner_leased FROM active GROUP BY owner
+		)
+		SELECT a.id FROM active a JOIN owners o ON o.owner = a.owner
+		ORDER BY a.priority DESC, o.owner_leased ASC, a.job_leased ASC, a.uploaded_at ASC
+		LIMIT ?`,
+		[]string{model.JobStatusQueued, model.JobStatusProcessing},
+		model.WorkItemPending, kinds,
+		limit,
+	).Scan(&ids).Error
+	return ids, err
+}
+
+// recountLeasedItems recalcule le compteur leased_items des jobs actifs depuis
+// work_items (démarrage, ou colonne ajoutée alors que des éléments étaient réservés)
+func recountLeasedItems() error {
+	return infra.GetDB().Exec(`
+		UPDATE bulk_jobs j SET leased_items =
+			(SELECT count(*) FROM work_items w WHERE w.job_id = j.id AND w.status = ?)
+		WHERE j.status IN ?`,
+		model.WorkItemLeased,
+		[]string{model.JobStatusQueued, model.JobStatusProcessing, model.JobStatusPaused},
+	).Error
+}
+
+// QueueEstimate situe un job en attente dans la file
+type QueueEstimate struct {
+	Position         int        `json:"position"`                   // 1 = servi au prochain worker libre
+	JobsAhead        int        `json:"jobsAhead"`                  // jobs de priorité supérieure encore en cours
+	ItemsAhead       int64      `json:"itemsAhead"`                 // éléments de ces jobs restant à traiter
+	EstimatedStartAt *time.Time `json:"estimatedStartAt,omitempty"` // absent sans débit mesuré
+}
+
+// GetQueueEstimate retourne la position d'un job qui n'a pas encore commencé
+// (nil sinon). Seuls les jobs de priorité supérieure passent devant: à priorité
+// égale, le partage équitable lui donne un worker au prochain élément terminé.
+func GetQueueEstimate(job model.BulkJob) (*QueueEstimate, error) {
+	if job.Status != model.JobStatusQueued {
+		return nil, nil
+	}
+	db := infra.GetDB()
+	var ahead []struct {
+		JobID string
+		Items int64
+	}
+	err := db.Model(&model.WorkItem{}).
+		Select("work_items.job_id, count(*) AS items").
+		Joins("JOIN bulk_jobs ON bulk_jobs.id = work_items.job_id").
+		Where("work_items.status IN ? AND work_items.kind = ?", []string{model.WorkItemPending, model.WorkItemLeased}, job.Kind).
+		Where("bulk_jobs.status IN ? AND bulk_jobs.priority > ?", []string{model.JobStatusQueued, model.JobStatusProcessing}, job.Priority).
+		Group("work_items.job_id").
+		Scan(&ahead).Error
+	if err != nil {
+		return nil, err
+	}
+
+	estimate := &QueueEstimate{JobsAhead: len(ahead), Position: len(ahead) + 1}
+	for _, a := range ahead {
+		estimate.ItemsAhead += a.Items
+	}
+
+	// Débit récent de la file pour ce type de travail
+	var done int64
+	err = db.Model(&model.WorkItem{}).
+		Where("status = ? AND kind = ? AND updated_at > ?", model.WorkItemDone, job.Kind, time.Now().Add(-throughputWindow)).
+		Count(&done).Error
+	if err != nil {
+		return nil, err
+	}
+	if done > 0 {
+		perSecond := float64(done) / throughputWindow.Seconds()
+		start := time.Now().Add(time.Duration(float64(estimate.ItemsAhead) / perSecond * float64(time.Second)))
+		estimate.EstimatedStartAt = &start
+	}
+	return estimate, nil
+}
@@ -0,0 +1,576 @@
+package service
+
+import (
+	"backend/internal/infra"
+	"backend/internal/model"
+	"backend/internal/util"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	schedulePollInterval = 30 * time.Second
+	// Nombre de changements inclus dans les notifications
+	diffSampleSize = 100
+)
+
+var (
+	ErrScheduleNotFound    = errors.New("schedule not found")
+	ErrInvalidTimezone     = errors.New("unknown timezone")
+	ErrScheduleNeverRuns   = errors.New("cron expression never matches")
+	ErrScheduleTarget      = errors.New("either jobId or listId is required")
+	ErrInvalidStatusFilter = errors.New("unknown status, expected valid, invalid, accept_all or unknown")
+)
+
+// Statuts re-vérifiés par défaut: les adresses qui peuvent encore changer
+var defaultScheduleStatuses = []string{string(StatusValid), string(StatusAcceptAll), statusUnknown}
+
+var scheduleStatuses = []string{string(StatusValid), string(StatusInvalid), string(StatusAcceptAll), statusUnknown}
+
+// ScheduleInput crée ou modifie une planification (nil = valeur par défaut ou inchangée)
+type ScheduleInput struct {
+	JobID         string    `json:"jobId"`  // job re-vérifié
+	ListID        string    `json:"listId"` // ou liste re-vérifiée
+	Cron          *string   `json:"cron"`
+	Timezone      *string   `json:"timezone"` // UTC par défaut
+	Statuses      *[]string `json:"statuses"`
+	Active        *bool     `json:"active"`
+	WebhookURL    *string   `json:"webhookUrl"`
+	WebhookSecret *string   `json:"webhookSecret"` // généré si webhookUrl est défini sans secret
+	NotifyEmail   *string   `json:"notifyEmail"`
+}
+
+// ScheduleView est une planification avec ses statuts décodés
+type ScheduleView struct {
+	model.Schedule
+	Statuses []string `json:"statuses"`
+}
+
+// DiffSummary résume les changements de statut d'une re-vérification
+type DiffSummary struct {
+	Checked       int            `json:"checked"`
+	Changed       int            `json:"changed"`
+	Transitions   map[string]int `json:"transitions"` // "valid->invalid": 12
+	NewlyCatchAll int            `json:"newlyCatchAll"`
+}
+
+// ScheduleRun est un lancement d'une planification
+type ScheduleRun struct {
+	JobID      string      `json:"jobId"`
+	Status     string      `json:"status"`
+	StartedAt  time.Time   `json:"startedAt"`
+	FinishedAt *time.Time  `json:"finishedAt,omitempty"`
+	Diff       DiffSummary `json:"diff"`
+}
+
+// DiffQuery pagine et filtre les changements d'une re-vérification
+type DiffQuery struct {
+	JobID    string
+	From     string // statut précédent
+	To       string // nouveau statut
+	Page     int
+	PageSize int
+}
+
+// DiffReport est le rapport de changements d'un job de re-vérification
+type DiffReport struct {
+	JobID       string               `json:"jobId"`
+	ParentJobID *string              `json:"parentJobId,omitempty"`
+	ListID      *string              `json:"listId,omitempty"`
+	ScheduleID  *string              `json:"scheduleId,omitempty"`
+	Summary     DiffSummary          `json:"summary"`
+	Changes     []model.StatusChange `json:"changes"`
+	Total       int64                `json:"total"`
+	Page        int                  `json:"page"`
+	PageSize    int                  `json:"pageSize"`
+}
+
+// ScheduleDiffPayload est le corps de l'événement schedule.diff
+type ScheduleDiffPayload struct {
+	Event        string               `json:"event"`
+	OccurredAt   time.Time            `json:"occurredAt"`
+	ScheduleID   string               `json:"scheduleId"`
+	JobID        string               `json:"jobId"`
+	ParentJobID  *string              `json:"parentJobId,omitempty"`
+	ListID       *string              `json:"listId,omitempty"`
+	FileName     string               `json:"fileName"`
+	DisplayName  string               `json:"displayName,omitempty"`
+	Summary      DiffSummary          `json:"summary"`
+	Changes      []model.StatusChange `json:"changes"` // les premiers changements
+	ChangesTotal int                  `json:"changesTotal"`
+}
+
+// CreateSchedule planifie la re-vérification d'un job ou d'une liste; le secret
+// du webhook n'est retourné qu'ici
+func CreateSchedule(in ScheduleInput) (ScheduleView, string, error) {
+	s := model.Schedule{Timezone: "UTC", Active: true}
+	switch {
+	case in.JobID != "" && in.ListID != "":
+		return ScheduleView{}, "", ErrScheduleTarget
+	case in.ListID != "":
+		list, err := getList(in.ListID)
+		if err != nil {
+			return ScheduleView{}, "", err
+		}
+		s.ListID = &list.ID
+	case in.JobID != "":
+		job, err := GetBulkJobByID(in.JobID)
+		if err != nil {
+			return ScheduleView{}, "", ErrJobNotFound
+		}
+		if job.Kind != model.WorkKindVerify || job.ParentJobID != nil || job.ListID != nil {
+			return ScheduleView{}, "", ErrInvalidJobTransition
+		}
+		s.JobID = &job.ID
+	default:
+		return ScheduleView{}, "", ErrScheduleTarget
+	}
+	if in.Cron == nil {
+		return ScheduleView{}, "", util.ErrInvalidCron
+	}
+	if in.Statuses == nil {
+		in.Statuses = &defaultScheduleStatuses
+	}
+	secret, err := applyScheduleInput(&s, in)
+	if err != nil {
+		return ScheduleView{}, "", err
+	}
+	if err := infra.GetDB().Create(&s).Error; err != nil {
+		return ScheduleView{}, "", err
+	}
+	return scheduleView(s), secret, nil
+}
+
+// UpdateSchedule modifie une planification; l'échéance suivante est recalculée
+func UpdateSchedule(id string, in ScheduleInput) (ScheduleView, string, error) {
+	s, err := getSchedule(id)
+	if err != nil {
+		return ScheduleView{}, "", err
+	}
+	secret, err := applyScheduleInput(&s, in)
+	if err != nil {
+		return ScheduleView{}, "", err
+	}
+	if err := infra.GetDB().Save(&s).Error; err != nil {
+		return ScheduleView{}, "", err
+	}
+	return scheduleView(s), secret, nil
+}
+
+// applyScheduleInput valide et applique les champs fournis, puis calcule la
+// prochaine échéance. Retourne le secret de webhook s'il vient d'être défini.
+func applyScheduleInput(s *model.Schedule, in ScheduleInput) (string, error) {
+	if in.Cron != nil {
+		if _, err := util.ParseCron(*in.Cron); err != nil {
+			return "", err
+		}
+		s.Cron = strings.TrimSpace(*in.Cron)
+	}
+	if in.Timezone != nil {
+		tz := strings.TrimSpace(*in.Timezone)
+		if tz == "" {
+			tz = "UTC"
+		}
+		if _, err := time.LoadLocation(tz); err != nil {
+			return "", ErrInvalidTimezone
+		}
+		s.Timezone = tz
+	}
+	if in.Statuses != nil {
+		statuses := []string{}
+		for _, st := range *in.Statuses {
+			if !slices.Contains(scheduleStatuses, st) {
+				return "", fmt.Errorf("%w: %s", ErrInvalidStatusFilter, st)
+			}
+			if !slices.Contains(statuses, st) {
+				statuses = append(statuses, st)
+			}
+		}
+		if len(statuses) == 0 {
+			statuses = defaultScheduleStatuses
+		}
+		encoded, _ := json.Marshal(statuses)
+		s.Statuses = string(encoded)
+	}
+	if in.NotifyEmail != nil {
+		if addr := strings.TrimSpace(*in.NotifyEmail); addr != "" {
+			if err := ValidateNotifyEmail(addr); err != nil {
+				return "", err
+			}
+		}
+		s.NotifyEmail = strings.TrimSpace(*in.NotifyEmail)
+	}
+	secret := ""
+	if in.WebhookURL != nil {
+		if *in.WebhookURL != "" {
+			if err := ValidateWebhookURL(*in.WebhookURL); err != nil {
+				return "", err
+			}
+		}
+		s.WebhookURL = *in.WebhookURL
+		if s.WebhookURL == "" {
+			s.WebhookSecret = ""
+		} else if (in.WebhookSecret == nil || *in.WebhookSecret == "") && s.WebhookSecret == "" {
+			s.WebhookSecret = NewWebhookSecret()
+			secret = s.WebhookSecret
+		}
+	}
+	if in.WebhookSecret != nil && *in.WebhookSecret != "" && s.WebhookURL != "" {
+		s.WebhookSecret = *in.WebhookSecret
+		secret = s.WebhookSecret
+	}
+	if in.Active != nil {
+		s.Active = *in.Active
+	}
+
+	s.NextRunAt = nil
+	if s.Active {
+		next, err := nextScheduleRun(*s, time.Now())
+		if err != nil {
+			return "", err
+		}
+		s.NextRunAt = &next
+	}
+	return secret, nil
+}
+
+// nextScheduleRun retourne la prochaine échéance après t, dans le fuseau de la planification
+func nextScheduleRun(s model.Schedule, t time.Time) (time.Time, error) {
+	cron, err := util.ParseCron(s.Cron)
+	if err != nil {
+		return time.Time{}, err
+	}
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.Time{}, ErrInvalidTimezone
+	}
+	next := cron.Next(t.In(loc))
+	if next.IsZero() {
+		return time.Time{}, ErrScheduleNeverRuns
+	}
+	return next.UTC(), nil
+}
+
+func scheduleView(s model.Schedule) ScheduleView {
+	statuses := []string{}
+	json.Unmarshal([]byte(s.Statuses), &statuses)
+	return ScheduleView{Schedule: s, Statuses: statuses}
+}
+
+func getSchedule(id string) (model.Schedule, error) {
+	var s model.Schedule
+	if err := infra.GetDB().Where("id = ?", id).First(&s).Error; err != nil {
+		return s, ErrScheduleNotFound
+	}
+	return s, nil
+}
+
+// ListSchedules retourne les planifications, éventuellement celles d'un job ou d'une liste
+func ListSchedules(jobId, listId string) ([]ScheduleView, error) {
+	q := infra.GetDB().Order("created_at ASC")
+	if jobId != "" {
+		q = q.Where("job_id = ?", jobId)
+	}
+	if listId != "" {
+		q = q.Where("list_id = ?", listId)
+	}
+	var schedules []model.Schedule
+	if err := q.Find(&schedules).Error; err != nil {
+		return nil, err
+	}
+	views := make([]ScheduleView, len(schedules))
+	for i, s := range schedules {
+		views[i] = scheduleView(s)
+	}
+	return views, nil
+}
+
+// GetSchedule retourne une planification et ses derniers lancements avec leur résumé
+func GetSchedule(id string, runs int) (ScheduleView, []ScheduleRun, error) {
+	s, err := getSchedule(id)
+	if err != nil {
+		return ScheduleView{}, nil, err
+	}
+	var jobs []model.BulkJob
+	err = infra.GetDB().Where("schedule_id = ?", id).Order("uploaded_at DESC").Limit(runs).Find(&jobs).Error
+	if err != nil {
+		return ScheduleView{}, nil, err
+	}
+	ids := make([]string, len(jobs))
+	for i, j := range jobs {
+		ids[i] = j.ID
+	}
+	summaries, err := diffSummaries(ids)
+	if err != nil {
+		return ScheduleView{}, nil, err
+	}
+	history := make([]ScheduleRun, len(jobs))
+	for i, j := range jobs {
+		diff := summaries[j.ID]
+		diff.Checked = j.ProcessedEmails - j.ErrorCount
+		history[i] = ScheduleRun{JobID: j.ID, Status: j.Status, StartedAt: j.UploadedAt, FinishedAt: j.FinishedAt, Diff: diff}
+	}
+	return scheduleView(s), history, nil
+}
+
+// DeleteSchedule supprime une planification; ses lancements passés sont conservés
+func DeleteSchedule(id string) error {
+	res := infra.GetDB().Where("id = ?", id).Delete(&model.Schedule{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrScheduleNotFound
+	}
+	return nil
+}
+
+// diffSummaries compte en une requête les changements de chaque job de re-vérification
+func diffSummaries(jobIDs []string) (map[string]DiffSummary, error) {
+	summaries := map[string]DiffSummary{}
+	for _, id := range jobIDs {
+		summaries[id] = DiffSummary{Transitions: map[string]int{}}
+	}
+	if len(jobIDs) == 0 {
+		return summaries, nil
+	}
+	var rows []struct {
+		JobID         string
+		FromStatus    string
+		ToStatus      string
+		Count         int
+		NewlyCatchAll int
+	}
+	err := infra.GetDB().Model(&model.StatusChange{}).
+		Select("job_id, from_status, to_status, count(*) AS count, count(*) FILTER (WHERE newly_catch_all) AS newly_catch_all").
+		Where("job_id IN ?", jobIDs).
+		Group("job_id, from_status, to_status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		s := summaries[r.JobID]
+		s.Changed += r.Count
+		s.NewlyCatchAll += r.NewlyCatchAll
+		if r.FromStatus != r.ToStatus {
+			s.Transitions[r.FromStatus+"->"+r.ToStatus] += r.Count
+		}
+		summaries[r.JobID] = s
+	}
+	return summaries, nil
+}
+
+// GetJobDiff retourne les changements de statut constatés par un job de
+// re-vérification ou de vérification d'une liste
+func GetJobDiff(query DiffQuery) (DiffReport, error) {
+	job, err := GetBulkJobByID(query.JobID)
+	if err != nil {
+		return DiffReport{}, ErrJobNotFound
+	}
+	if job.ParentJobID == nil && job.ListID == nil {
+		return DiffReport{}, ErrInvalidJobTransition
+	}
+	summaries, err := diffSummaries([]string{job.ID})
+	if err != nil {
+		return DiffReport{}, err
+	}
+	report := DiffReport{
+		JobID:       job.ID,
+		ParentJobID: job.ParentJobID,
+		ListID:      job.ListID,
+		ScheduleID:  job.ScheduleID,
+		Summary:     summaries[job.ID],
+		Page:        query.Page,
+		PageSize:    query.PageSize,
+	}
+	report.Summary.Checked = job.ProcessedEmails - job.ErrorCount
+
+	q := infra.GetDB().Model(&model.StatusChange{}).Where("job_id = ?", job.ID)
+	if query.From != "" {
+		q = q.Where("from_status = ?", query.From)
+	}
+	if query.To != "" {
+		q = q.Where("to_status = ?", query.To)
+	}
+	if err := q.Count(&report.Total).Error; err != nil {
+		return DiffReport{}, err
+	}
+	err = q.Order("row_index ASC").Limit(query.PageSize).Offset((query.Page - 1) * query.PageSize).Find(&report.Changes).Error
+	return report, err
+}
+
+// RunScheduledReverifications lance les re-vérifications dues jusqu'à l'annulation
+// du contexte. Plusieurs processus peuvent l'exécuter: chaque échéance est réservée
+// (SKIP LOCKED) et repoussée avant le lancement.
+func RunScheduledReverifications(ctx context.Context) {
+	for ctx.Err() == nil {
+		schedules, err := claimDueSchedules(10)
+		if err != nil {
+			log.Printf("schedules: claim failed: %v", err)
+		}
+		for _, s := range schedules {
+			runSchedule(s)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(schedulePollInterval):
+		}
+	}
+}
+
+func claimDueSchedules(limit int) ([]model.Schedule, error) {
+	var schedules []model.Schedule
+	err := infra.GetDB().Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("active = ? AND next_run_at <= ?", true, time.Now()).
+			Order("next_run_at ASC").
+			Limit(limit).
+			Find(&schedules).Error
+		if err != nil {
+			return err
+		}
+		due := schedules[:0]
+		for _, s := range schedules {
+			updates := map[string]interface{}{}
+			next, err := nextScheduleRun(s, time.Now())
+			if err != nil {
+				// Échéance incalculable (fuseau inconnu de ce système, expression qui ne
+				// correspond plus): la planification est désactivée et n'est pas lancée
+				log.Printf("schedules: cannot compute next run of %s: %v", s.ID, err)
+				updates["next_run_at"] = nil
+				updates["active"] = false
+				updates["last_error"] = "failed: cannot compute next run: " + err.Error()
+			} else {
+				updates["next_run_at"] = &next
+				due = append(due, s)
+			}
+			if err := tx.Model(&model.Schedule{}).Where("id = ?", s.ID).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+		schedules = due
+		return nil
+	})
+	return schedules, err
+}
+
+// RunScheduleNow lance immédiatement une planification, sans changer son échéance
+func RunScheduleNow(id string) (model.BulkJob, error) {
+	s, err := getSchedule(id)
+	if err != nil {
+		return model.BulkJob{}, err
+	}
+	return runSchedule(s)
+}
+
+// runSchedule crée le job de re-vérification (job ou liste) d'une planification et enregistre
+// le résultat du lancement (un lancement est manqué si le précédent n'est pas terminé)
+func runSchedule(s model.Schedule) (model.BulkJob, error) {
+	id := s.ID
+	statuses := scheduleView(s).Statuses
+	var job model.BulkJob
+	var err error
+	if s.ListID != nil {
+		job, err = VerifyList(*s.ListID, ListVerifyOptions{Filter: MemberFilter{Statuses: statuses}, ScheduleID: &id})
+	} else {
+		job, err = reverifyBulkJob(*s.JobID, ReverifyFilter{Statuses: statuses}, &id)
+	}
+	now := time.Now()
+	updates := map[string]interface{}{"last_run_at": &now, "last_error": ""}
+	switch {
+	case errors.Is(err, ErrJobActive):
+		updates["last_error"] = "skipped: job or previous run still running"
+	case errors.Is(err, ErrNothingToReverify):
+		updates["last_error"] = "skipped: no results match the statuses"
+	case err != nil:
+		updates["last_error"] = err.Error()
+		log.Printf("schedules: run of %s failed: %v", s.ID, err)
+	default:
+		updates["last_run_job_id"] = job.ID
+	}
+	if err := infra.GetDB().Model(&model.Schedule{}).Where("id = ?", s.ID).Updates(updates).Error; err != nil {
+		log.Printf("schedules: cannot update %s: %v", s.ID, err)
+	}
+	return job, err
+}
+
+// notifyScheduledRun envoie le rapport de changements d'une re-vérification
+// planifiée terminée: événement schedule.diff et email de notification
+func notifyScheduledRun(jobId string) {
+	job, err := GetBulkJobByID(jobId)
+	if err != nil || job.ScheduleID == nil || job.Status != model.JobStatusDone {
+		return
+	}
+	s, err := getSchedule(*job.ScheduleID)
+	if err != nil {
+		return
+	}
+	report, err := GetJobDiff(DiffQuery{JobID: jobId, Page: 1, PageSize: diffSampleSize})
+	if err != nil {
+		log.Printf("schedules: cannot build diff of job %s: %v", jobId, err)
+		return
+	}
+	payload, err := json.Marshal(ScheduleDiffPayload{
+		Event:        model.WebhookEventScheduleDiff,
+		OccurredAt:   time.Now(),
+		ScheduleID:   s.ID,
+		JobID:        job.ID,
+		ParentJobID:  report.ParentJobID,
+		ListID:       report.ListID,
+		FileName:     job.FileName,
+		DisplayName:  job.DisplayName,
+		Summary:      report.Summary,
+		Changes:      report.Changes,
+		ChangesTotal: int(report.Total),
+	})
+	if err != nil {
+		return
+	}
+	if err := queueDeliveries(job.ID, model.WebhookEventScheduleDiff, payload, s.WebhookURL, s.WebhookSecret); err != nil {
+		log.Printf("schedules: cannot enqueue diff of job %s: %v", jobId, err)
+	}
+	if s.NotifyEmail != "" {
+		go func() {
+			subject := fmt.Sprintf("MailHound: %d status changes in %s", report.Summary.Changed, jobDisplayName(job))
+			if err := sendMail(s.NotifyEmail, subject, diffEmailBody(job, report)); err != nil {
+				log.Printf("schedules: cannot email diff of job %s: %v", jobId, err)
+			}
+		}()
+	}
+}
+
+func diffEmailBody(job model.BulkJob, report DiffReport) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Scheduled re-verification of %s finished.\n\n", jobDisplayName(job))
+	fmt.Fprintf(&b, "Checked: %d\nChanged: %d\nNewly catch-all: %d\n\n", report.Summary.Checked, report.Summary.Changed, report.Summary.NewlyCatchAll)
+	transitions := make([]string, 0, len(report.Summary.Transitions))
+	for t := range report.Summary.Transitions {
+		transitions = append(transitions, t)
+	}
+	slices.Sort(transitions)
+	for _, t := range transitions {
+		fmt.Fprintf(&b, "  %s: %d\n", t, report.Summary.Transitions[t])
+	}
+	if len(report.Changes) > 0 {
+		b.WriteString("\nChanges:\n")
+		for _, c := range report.Changes {
+			fmt.Fprintf(&b, "  %s: %s -> %s\n", c.Email, c.FromStatus, c.ToStatus)
+		}
+		if int(report.Total) > len(report.Changes) {
+			fmt.Fprintf(&b, "  ... and %d more\n", int(report.Total)-len(report.Changes))
+		}
+	}
+	fmt.Fprintf(&b, "\nFull report: GET /api/bulk-verify/%s/diff\n", job.ID)
+	return b.String()
+}
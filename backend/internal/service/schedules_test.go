@@ -0,0 +1,141 @@
+package service
+
+import (
+	"backend/internal/model"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestNextScheduleRun(t *testing.T) {
+	from := time.Date(2026, 10, 19, 10, 7, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		cron, tz string
+		want     time.Time
+		err      error
+	}{
+		{"utc", "0 3 * * *", "UTC", time.Date(2026, 10, 20, 3, 0, 0, 0, time.UTC), nil},
+		{"local time", "0 3 * * *", "Europe/Paris", time.Date(2026, 10, 20, 1, 0, 0, 0, time.UTC), nil},
+		{"local day", "0 1 * * tue", "Asia/Tokyo", time.Date(2026, 10, 19, 16, 0, 0, 0, time.UTC), nil},
+		{"unknown timezone", "0 3 * * *", "Mars/Olympus", time.Time{}, ErrInvalidTimezone},
+		{"never runs", "0 0 30 2 *", "UTC", time.Time{}, ErrScheduleNeverRuns},
+	}
+	for _, tt := range tests {
+		got, err := nextScheduleRun(model.Schedule{Cron: tt.cron, Timezone: tt.tz}, from)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.err)
+			continue
+		}
+		if !got.Equal(tt.want) || (err == nil && got.Location() != time.UTC) {
+			t.Errorf("%s: next = %v, want %v UTC", tt.name, got, tt.want)
+		}
+	}
+}
+
+// Une échéance due est réservée et repoussée; une planification dont l'échéance ne
+// peut plus être calculée est désactivée avec son erreur, sans être lancée
+func TestClaimDueSchedules(t *testing.T) {
+	db := testDB(t)
+	past := time.Now().Add(-time.Minute)
+	due := model.Schedule{Cron: "0 3 * * *", Timezone: "UTC", Active: true, NextRunAt: &past}
+	broken := model.Schedule{Cron: "0 3 * * *", Timezone: "Mars/Olympus", Active: true, NextRunAt: &past}
+	future := time.Now().Add(time.Hour)
+	later := model.Schedule{Cron: "0 3 * * *", Timezone: "UTC", Active: true, NextRunAt: &future}
+	for _, s := range []*model.Schedule{&due, &broken, &later} {
+		if err := db.Create(s).Error; err != nil {
+			t.Fatal(err)
+		}
+		id := s.ID
+		t.Cleanup(func() { db.Where("id = ?", id).Delete(&model.Schedule{}) })
+	}
+
+	claimed, err := claimDueSchedules(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]bool{}
+	for _, s := range claimed {
+		got[s.ID] = true
+	}
+	if !got[due.ID] || got[broken.ID] || got[later.ID] {
+		t.Fatalf("claimed due=%v broken=%v later=%v, want only due", got[due.ID], got[broken.ID], got[later.ID])
+	}
+
+	reload := func(id string) model.Schedule {
+		var s model.Schedule
+		if err := db.Where("id = ?", id).First(&s).Error; err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	if s := reload(due.ID); !s.Active || s.NextRunAt == nil || !s.NextRunAt.After(time.Now()) {
+		t.Errorf("due schedule: active=%v next=%v, want active and pushed back", s.Active, s.NextRunAt)
+	}
+	if s := reload(broken.ID); s.Active || s.NextRunAt != nil || !strings.HasPrefix(s.LastError, "failed:") {
+		t.Errorf("broken schedule: active=%v next=%v lastError=%q, want disabled with error", s.Active, s.NextRunAt, s.LastError)
+	}
+
+	// Déjà repoussée: plus rien à réserver
+	claimed, err = claimDueSchedules(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range claimed {
+		if s.ID == due.ID {
+			t.Error("due schedule claimed twice")
+		}
+	}
+}
+
+func TestDiffSummariesAndJobDiff(t *testing.T) {
+	db := testDB(t)
+	parent := createTestJob(t, model.BulkJob{FileName: "parent.csv"})
+	job := createTestJob(t, model.BulkJob{FileName: "parent.csv", ParentJobID: &parent.ID})
+	other := createTestJob(t, model.BulkJob{FileName: "other.csv"})
+	changes := []model.StatusChange{
+		{JobID: job.ID, RowIndex: 0, Email: "a@x.com", FromStatus: "valid", ToStatus: "invalid"},
+		{JobID: job.ID, RowIndex: 1, Email: "b@x.com", FromStatus: "valid", ToStatus: "invalid"},
+		{JobID: job.ID, RowIndex: 2, Email: "c@x.com", FromStatus: "valid", ToStatus: "accept_all", NewlyCatchAll: true},
+		// Statut inchangé mais domaine devenu catch-all
+		{JobID: job.ID, RowIndex: 3, Email: "d@x.com", FromStatus: "accept_all", ToStatus: "accept_all", NewlyCatchAll: true},
+		{JobID: other.ID, RowIndex: 0, Email: "e@x.com", FromStatus: "unknown", ToStatus: "valid"},
+	}
+	if err := db.Create(&changes).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	summaries, err := diffSummaries([]string{job.ID, other.ID, parent.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := summaries[job.ID]
+	if s.Changed != 4 || s.NewlyCatchAll != 2 || len(s.Transitions) != 2 ||
+		s.Transitions["valid->invalid"] != 2 || s.Transitions["valid->accept_all"] != 1 {
+		t.Errorf("job summary = %+v", s)
+	}
+	if o := summaries[other.ID]; o.Changed != 1 || o.Transitions["unknown->valid"] != 1 {
+		t.Errorf("other summary = %+v", o)
+	}
+	if p := summaries[parent.ID]; p.Changed != 0 || p.Transitions == nil {
+		t.Errorf("job without changes = %+v, want empty summary", p)
+	}
+
+	report, err := GetJobDiff(DiffQuery{JobID: job.ID, From: "valid", To: "invalid", Page: 1, PageSize: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Total != 2 || len(report.Changes) != 1 || report.Changes[0].Email != "a@x.com" || report.Summary.Changed != 4 {
+		t.Errorf("diff page 1 = total %d, changes %+v, summary %+v", report.Total, report.Changes, report.Summary)
+	}
+	report, _ = GetJobDiff(DiffQuery{JobID: job.ID, From: "valid", To: "invalid", Page: 2, PageSize: 1})
+	if len(report.Changes) != 1 || report.Changes[0].Email != "b@x.com" {
+		t.Errorf("diff page 2 = %+v", report.Changes)
+	}
+
+	if _, err := GetJobDiff(DiffQuery{JobID: parent.ID, Page: 1, PageSize: 10}); !errors.Is(err, ErrInvalidJobTransition) {
+		t.Errorf("diff of a job that is not a re-verification: err = %v", err)
+	}
+}
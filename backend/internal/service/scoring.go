@@ -0,0 +1,156 @@
+package service
+
+import (
+	"strings"
+)
+
+// Fournisseurs de messagerie gratuits les plus courants
+var freeDomains = map[string]bool{
+	"gmail.com":      true,
+	"googlemail.com": true,
+	"yahoo.com":      true,
+	"yahoo.fr":       true,
+	"hotmail.com":    true,
+	"hotmail.fr":     true,
+	"outlook.com":    true,
+	"outlook.fr":     true,
+	"live.com":       true,
+	"live.fr":        true,
+	"msn.com":        true,
+	"icloud.com":     true,
+	"aol.com":        true,
+	"gmx.com":        true,
+	"gmx.fr":         true,
+	"protonmail.com": true,
+	"proton.me":      true,
+	"orange.fr":      true,
+	"wanadoo.fr":     true,
+	"free.fr":        true,
+	"sfr.fr":         true,
+	"laposte.net":    true,
+	"yandex.com":     true,
+	"mail.ru":        true,
+}
+
+// Domaines proposés en correction de faute de frappe (gmial.com -> gmail.com)
+var suggestionDomains = []string{
+	"gmail.com", "yahoo.com", "yahoo.fr", "hotmail.com", "hotmail.fr", "outlook.com",
+	"live.com", "icloud.com", "aol.com", "orange.fr", "wanadoo.fr", "free.fr", "sfr.fr",
+	"laposte.net", "gmx.com", "protonmail.com", "msn.com",
+}
+
+func emailDomain(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at == -1 {
+		return ""
+	}
+	return strings.ToLower(email[at+1:])
+}
+
+func isFreeEmail(email string) bool {
+	return freeDomains[emailDomain(email)]
+}
+
+// Fautes de frappe courantes sur l'extension
+var tldTypos = map[string]string{
+	"con": "com", "co": "com", "cmo": "com", "ocm": "com", "cm": "com", "om": "com", "comm": "com", "vom": "com", "xom": "com",
+	"fe": "fr", "ft": "fr", "rf": "fr",
+	"nte": "net", "ner": "net", "bet": "net",
+}
+
+// suggestEmail propose une adresse corrigée quand le domaine ressemble à un
+// fournisseur connu (même extension, nom à 1 ou 2 caractères près), sinon une chaîne vide
+func suggestEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at == -1 {
+		return ""
+	}
+	domain := strings.ToLower(email[at+1:])
+	dot := strings.LastIndex(domain, ".")
+	if dot <= 0 || freeDomains[domain] {
+		return ""
+	}
+	name, tld := domain[:dot], domain[dot+1:]
+	if fixed, ok := tldTypos[tld]; ok {
+		tld = fixed
+	}
+
+	best, bestDist := "", 3
+	for _, d := range suggestionDomains {
+		dDot := strings.LastIndex(d, ".")
+		if d[dDot+1:] != tld {
+			continue
+		}
+		dist := editDistance(name, d[:dDot])
+		maxDist := 2
+		if len(name) <= 5 {
+			maxDist = 1
+		}
+		if dist <= maxDist && dist < bestDist {
+			best, bestDist = d, dist
+		}
+	}
+	if best == "" || best == domain {
+		return ""
+	}
+	return email[:at+1] + best
+}
+
+// scoreResult attribue un score de délivrabilité de 0 à 100
+func scoreResult(res EmailValidationResult) int {
+	score := 0
+	switch res.Status {
+	case StatusValid:
+		score = 95
+	case StatusAcceptAll:
+		score = 50
+	case StatusInvalid:
+		if res.BounceType != nil && *res.BounceType == "soft" {
+			score = 20
+		}
+	default:
+		score = 30
+	}
+	if score == 0 {
+		return 0
+	}
+	if res.IsRoleBased {
+		score -= 15
+	}
+	if res.IsDisposable {
+		score -= 40
+	}
+	if res.Suggestion != "" {
+		score -= 20
+	}
+	if score < 0 {
+		score = 0
+	}
+	return score
+}
+
+// editDistance calcule la distance de Damerau-Levenshtein (variante OSA):
+// une transposition de deux lettres voisines (gmial/gmail) compte pour 1
+func editDistance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
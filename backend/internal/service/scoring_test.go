@@ -0,0 +1,64 @@
+package service
+
+import "testing"
+
+func TestScoreResult(t *testing.T) {
+	soft, hard := "soft", "hard"
+	tests := []struct {
+		name string
+		res  EmailValidationResult
+		want int
+	}{
+		{"valid", EmailValidationResult{Status: StatusValid}, 95},
+		{"valid role", EmailValidationResult{Status: StatusValid, IsRoleBased: true}, 80},
+		{"valid disposable", EmailValidationResult{Status: StatusValid, IsDisposable: true}, 55},
+		{"valid role disposable", EmailValidationResult{Status: StatusValid, IsRoleBased: true, IsDisposable: true}, 40},
+		{"valid typo", EmailValidationResult{Status: StatusValid, Suggestion: "a@gmail.com"}, 75},
+		{"accept all", EmailValidationResult{Status: StatusAcceptAll}, 50},
+		{"accept all disposable", EmailValidationResult{Status: StatusAcceptAll, IsDisposable: true}, 10},
+		{"unknown", EmailValidationResult{Status: statusUnknown}, 30},
+		{"empty status", EmailValidationResult{}, 30},
+		{"unknown floored", EmailValidationResult{Status: statusUnknown, IsRoleBased: true, IsDisposable: true, Suggestion: "a@gmail.com"}, 0},
+		{"invalid", EmailValidationResult{Status: StatusInvalid}, 0},
+		{"invalid hard", EmailValidationResult{Status: StatusInvalid, BounceType: &hard}, 0},
+		{"invalid hard role", EmailValidationResult{Status: StatusInvalid, BounceType: &hard, IsRoleBased: true}, 0},
+		{"invalid soft", EmailValidationResult{Status: StatusInvalid, BounceType: &soft}, 20},
+		{"invalid soft role", EmailValidationResult{Status: StatusInvalid, BounceType: &soft, IsRoleBased: true}, 5},
+		{"invalid soft disposable", EmailValidationResult{Status: StatusInvalid, BounceType: &soft, IsDisposable: true}, 0},
+	}
+	for _, tt := range tests {
+		if got := scoreResult(tt.res); got != tt.want {
+			t.Errorf("%s: scoreResult = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSuggestEmail(t *testing.T) {
+	tests := []struct {
+		email, want string
+	}{
+		{"john@gmial.com", "john@gmail.com"},  // transposition
+		{"john@gmai.com", "john@gmail.com"},   // lettre manquante
+		{"john@gmaill.com", "john@gmail.com"}, // lettre en trop
+		{"john@gnail.com", "john@gmail.com"},  // substitution
+		{"john@gmail.con", "john@gmail.com"},  // extension seule
+		{"john@gnail.co", "john@gmail.com"},   // extension et nom
+		{"john@yahooo.com", "john@yahoo.com"},
+		{"john@outlok.com", "john@outlook.com"},
+		{"john@hotmial.fr", "john@hotmail.fr"},
+		{"john@laposte.nte", "john@laposte.net"},
+		{"John@GMIAL.com", "John@gmail.com"}, // partie locale conservée telle quelle
+		{"john@gmail.com", ""},               // fournisseur connu
+		{"john@yahoo.fr", ""},
+		{"john@example.com", ""}, // domaine sans ressemblance
+		{"john@gmxxxx.com", ""},  // trop loin pour un nom court
+		{"john@aol.fr", ""},      // pas de fournisseur proche avec cette extension
+		{"john@localhost", ""},   // sans extension
+		{"not-an-email", ""},
+	}
+	for _, tt := range tests {
+		if got := suggestEmail(tt.email); got != tt.want {
+			t.Errorf("suggestEmail(%q) = %q, want %q", tt.email, got, tt.want)
+		}
+	}
+}
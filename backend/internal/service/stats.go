@@ -20,10 +20,10 @@ func GetDashboardStats() (DashboardStats, error) {
 
 	// Total emails verified today
 	today := time.Now().Truncate(24 * time.Hour)
-	db.Model(&model.EmailResult{}).Where("checked_at >= ?", today).Count(&stats.TotalToday)
+	db.Model(&model.EmailResult{}).Where(resultNotBlank).Where("checked_at >= ?", today).Count(&stats.TotalToday)
 
 	// Total emails verified (all time)
-	db.Model(&model.EmailResult{}).Count(&stats.TotalAll)
+	db.Model(&model.EmailResult{}).Where(resultNotBlank).Count(&stats.TotalAll)
 
 	// Total good emails
 	db.Model(&model.EmailResult{}).Where("status = ?", "valid").Count(&stats.Good)
@@ -0,0 +1,540 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Formats des données structurées d'une page
+const (
+	FormatJSONLD    = "jsonld"
+	FormatMicrodata = "microdata"
+	FormatHCard     = "hcard"
+	FormatVCard     = "vcard"
+)
+
+// PostalAddress est l'adresse d'une organisation; Text garde une adresse non découpée
+type PostalAddress struct {
+	Street     string `json:"street,omitempty"`
+	Locality   string `json:"locality,omitempty"`
+	Region     string `json:"region,omitempty"`
+	PostalCode string `json:"postalCode,omitempty"`
+	Country    string `json:"country,omitempty"`
+	Text       string `json:"text,omitempty"`
+}
+
+// SiteOrganization est une organisation ou une personne décrite par les données
+// structurées d'un site (schema.org en JSON-LD ou microdata, hCard, fichier vCard)
+type SiteOrganization struct {
+	Type    string          `json:"type"` // type schema.org: Organization, LocalBusiness, Person...
+	Name    string          `json:"name,omitempty"`
+	URL     string          `json:"url,omitempty"`
+	Emails  []string        `json:"emails,omitempty"`
+	Phones  []string        `json:"phones,omitempty"` // E.164, sinon tel que publié
+	Address []PostalAddress `json:"address,omitempty"`
+	SameAs  []string        `json:"sameAs,omitempty"` // profils sociaux et autres pages de l'organisation
+	Format  string          `json:"format"`           // jsonld, microdata, hcard ou vcard
+	Source  string          `json:"source"`           // page ou fichier où elle a été trouvée
+}
+
+// Types schema.org retenus; les sous-types de LocalBusiness sont reconnus à leur suffixe
+var organizationTypes = map[string]bool{
+	"Organization": true, "Corporation": true, "LocalBusiness": true, "Person": true,
+	"NGO": true, "EducationalOrganization": true, "GovernmentOrganization": true,
+	"MedicalOrganization": true, "Restaurant": true, "Hotel": true, "Dentist": true,
+	"Physician": true, "Attorney": true, "RealEstateAgent": true, "Pharmacy": true,
+}
+
+func isOrganizationType(t string) bool {
+	t = strings.TrimPrefix(strings.TrimPrefix(t, "http://schema.org/"), "https://schema.org/")
+	if organizationTypes[t] {
+		return true
+	}
+	for _, suffix := range []string{"Business", "Organization", "Store", "Service"} {
+		if strings.HasSuffix(t, suffix) && t != "WebService" {
+			return true
+		}
+	}
+	return false
+}
+
+func schemaType(t string) string {
+	if i := strings.LastIndexAny(t, "/#"); i != -1 {
+		return t[i+1:]
+	}
+	return t
+}
+
+// mailtoAddresses retourne les adresses d'un lien mailto: destinataires, to, cc
+// et bcc; subject, body et autres paramètres sont ignorés
+func mailtoAddresses(href string) []string {
+	if len(href) < 7 || !strings.EqualFold(href[:7], "mailto:") {
+		return nil
+	}
+	to, query, _ := strings.Cut(href[7:], "?")
+	var addresses []string
+	if to, err := url.PathUnescape(to); err == nil {
+		addresses = append(addresses, strings.Split(to, ",")...)
+	}
+	if values, err := url.ParseQuery(query); err == nil {
+		for key, vals := range values {
+			switch strings.ToLower(key) {
+			case "to", "cc", "bcc":
+				for _, v := range vals {
+					addresses = append(addresses, strings.Split(v, ",")...)
+				}
+			}
+		}
+	}
+	var emails []string
+	for _, a := range addresses {
+		if email := normalizeStructuredEmail(a); email != "" {
+			emails = append(emails, email)
+		}
+	}
+	return emails
+}
+
+// telNumber retourne le numéro d'un lien tel: sans paramètres (;ext=, ?...)
+func telNumber(href string) string {
+	if len(href) < 4 || !strings.EqualFold(href[:4], "tel:") {
+		return ""
+	}
+	number, _ := url.PathUnescape(href[4:])
+	if i := strings.IndexAny(number, ";?"); i != -1 {
+		number = number[:i]
+	}
+	return strings.TrimSpace(number)
+}
+
+func normalizeStructuredEmail(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 7 && strings.EqualFold(s[:7], "mailto:") {
+		s, _, _ = strings.Cut(s[7:], "?")
+	}
+	s = strings.ToLower(strings.TrimSpace(s))
+	if !emailRegex.MatchString(s) || !isValidEmail(s) {
+		return ""
+	}
+	return s
+}
+
+// pageLinks retourne les emails des liens mailto: et les numéros des liens tel: d'une page
+func pageLinks(dom *goquery.Selection) (emails, phones []string) {
+	dom.Find("a[href]").Each(func(_ int, a *goquery.Selection) {
+		href := strings.TrimSpace(a.AttrOr("href", ""))
+		emails = append(emails, mailtoAddresses(href)...)
+		if number := telNumber(href); number != "" {
+			phones = append(phones, number)
+		}
+	})
+	return uniqueStrings(emails), uniqueStrings(phones)
+}
+
+// pageOrganizations lit le JSON-LD, le microdata et les hCard d'une page; les
+// numéros nationaux sont normalisés dans le pays country
+func pageOrganizations(dom *goquery.Selection, page, country string) []SiteOrganization {
+	var orgs []SiteOrganization
+	dom.Find(`script[type="application/ld+json"]`).Each(func(_ int, s *goquery.Selection) {
+		var data interface{}
+		if err := json.Unmarshal([]byte(s.Text()), &data); err != nil {
+			return
+		}
+		orgs = append(orgs, jsonLDOrganizations(data)...)
+	})
+	orgs = append(orgs, microdataOrganizations(dom)...)
+	orgs = append(orgs, hCardOrganizations(dom)...)
+	for i := range orgs {
+		orgs[i].Source = page
+		orgs[i].normalize(country)
+	}
+	return orgs
+}
+
+// jsonLDOrganizations parcourt un document JSON-LD (tableaux, @graph, objets
+// imbriqués) et retourne ses organisations et personnes
+func jsonLDOrganizations(data interface{}) []SiteOrganization {
+	var orgs []SiteOrganization
+	switch v := data.(type) {
+	case []interface{}:
+		for _, item := range v {
+			orgs = append(orgs, jsonLDOrganizations(item)...)
+		}
+	case map[string]interface{}:
+		for _, t := range jsonStrings(v["@type"]) {
+			if isOrganizationType(t) {
+				orgs = append(orgs, jsonLDOrganization(v, schemaType(t)))
+				break
+			}
+		}
+		// Objets imbriqués (@graph, publisher, founder...) dans un ordre stable
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			if key != "address" && key != "contactPoint" {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			orgs = append(orgs, jsonLDOrganizations(v[key])...)
+		}
+	}
+	return orgs
+}
+
+func jsonLDOrganization(v map[string]interface{}, typ string) SiteOrganization {
+	org := SiteOrganization{Type: typ, Format: FormatJSONLD}
+	org.Name = firstString(jsonStrings(v["name"]))
+	org.URL = firstString(jsonStrings(v["url"]))
+	org.Emails = jsonStrings(v["email"])
+	org.Phones = jsonStrings(v["telephone"])
+	org.SameAs = jsonStrings(v["sameAs"])
+	// Points de contact: service client, commercial...
+	for _, cp := range jsonObjects(v["contactPoint"]) {
+		org.Emails = append(org.Emails, jsonStrings(cp["email"])...)
+		org.Phones = append(org.Phones, jsonStrings(cp["telephone"])...)
+	}
+	switch addr := v["address"].(type) {
+	case string:
+		org.Address = append(org.Address, PostalAddress{Text: addr})
+	default:
+		for _, a := range jsonObjects(addr) {
+			org.Address = append(org.Address, PostalAddress{
+				Street:     firstString(jsonStrings(a["streetAddress"])),
+				Locality:   firstString(jsonStrings(a["addressLocality"])),
+				Region:     firstString(jsonStrings(a["addressRegion"])),
+				PostalCode: firstString(jsonStrings(a["postalCode"])),
+				Country:    jsonCountry(a["addressCountry"]),
+			})
+		}
+	}
+	return org
+}
+
+// jsonStrings lit une valeur JSON-LD: chaîne, tableau de chaînes ou objet {"@id"/"url"/"name"}
+func jsonStrings(v interface{}) []string {
+	switch t := v.(type) {
+	case string:
+		if s := strings.TrimSpace(t); s != "" {
+			return []string{s}
+		}
+	case float64: // "telephone": 33123456789
+		return []string{strconv.FormatFloat(t, 'f', -1, 64)}
+	case []interface{}:
+		var out []string
+		for _, item := range t {
+			out = append(out, jsonStrings(item)...)
+		}
+		return out
+	case map[string]interface{}:
+		for _, key := range []string{"@id", "url", "name"} {
+			if s := jsonStrings(t[key]); len(s) > 0 {
+				return s
+			}
+		}
+	}
+	return nil
+}
+
+func jsonObjects(v interface{}) []map[string]interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		return []map[string]interface{}{t}
+	case []interface{}:
+		var out []map[string]interface{}
+		for _, item := range t {
+			out = append(out, jsonObjects(item)...)
+		}
+		return out
+	}
+	return nil
+}
+
+// jsonCountry lit addressCountry: "FR" ou {"@type": "Country", "name": "FR"}
+func jsonCountry(v interface{}) string {
+	if m, ok := v.(map[string]interface{}); ok {
+		return firstString(jsonStrings(m["name"]))
+	}
+	return firstString(jsonStrings(v))
+}
+
+func firstString(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// microdataOrganizations lit les éléments itemscope de type Organization, Person...
+func microdataOrganizations(dom *goquery.Selection) []SiteOrganization {
+	var orgs []SiteOrganization
+	dom.Find("[itemscope][itemtype]").Each(func(_ int, item *goquery.Selection) {
+		// itemtype peut lister plusieurs types séparés par des espaces
+		typ := ""
+		for _, t := range strings.Fields(item.AttrOr("itemtype", "")) {
+			if isOrganizationType(schemaType(t)) {
+				typ = schemaType(t)
+				break
+			}
+		}
+		if typ == "" {
+			return
+		}
+		props := microdataProps(item)
+		org := SiteOrganization{Type: typ, Format: FormatMicrodata}
+		org.Name = firstString(props["name"])
+		org.URL = firstString(props["url"])
+		org.Emails = props["email"]
+		org.Phones = props["telephone"]
+		org.SameAs = props["sameAs"]
+		item.Find(`[itemprop~="address"]`).Each(func(_ int, a *goquery.Selection) {
+			if !ownedBy(a, item) {
+				return
+			}
+			if _, nested := a.Attr("itemscope"); !nested {
+				org.Address = append(org.Address, PostalAddress{Text: microdataValue(a)})
+				return
+			}
+			ap := microdataProps(a)
+			org.Address = append(org.Address, PostalAddress{
+				Street:     firstString(ap["streetAddress"]),
+				Locality:   firstString(ap["addressLocality"]),
+				Region:     firstString(ap["addressRegion"]),
+				PostalCode: firstString(ap["postalCode"]),
+				Country:    firstString(ap["addressCountry"]),
+			})
+		})
+		orgs = append(orgs, org)
+	})
+	return orgs
+}
+
+// microdataProps retourne les propriétés propres à un élément itemscope (hors objets imbriqués)
+func microdataProps(item *goquery.Selection) map[string][]string {
+	props := map[string][]string{}
+	item.Find("[itemprop]").Each(func(_ int, p *goquery.Selection) {
+		if !ownedBy(p, item) {
+			return
+		}
+		if _, nested := p.Attr("itemscope"); nested {
+			return
+		}
+		value := microdataValue(p)
+		if value == "" {
+			return
+		}
+		for _, name := range strings.Fields(p.AttrOr("itemprop", "")) {
+			props[name] = append(props[name], value)
+		}
+	})
+	return props
+}
+
+// ownedBy indique si la propriété appartient à item et non à un objet imbriqué
+func ownedBy(prop, item *goquery.Selection) bool {
+	owner := prop.Parent().Closest("[itemscope]")
+	return owner.Length() > 0 && owner.Get(0) == item.Get(0)
+}
+
+func microdataValue(p *goquery.Selection) string {
+	if v, ok := p.Attr("content"); ok {
+		return strings.TrimSpace(v)
+	}
+	switch goquery.NodeName(p) {
+	case "a", "link", "area":
+		return strings.TrimSpace(p.AttrOr("href", ""))
+	case "meta":
+		return strings.TrimSpace(p.AttrOr("content", ""))
+	}
+	return strings.Join(strings.Fields(p.Text()), " ")
+}
+
+// hCardOrganizations lit les microformats hCard (classes vcard ou h-card)
+func hCardOrganizations(dom *goquery.Selection) []SiteOrganization {
+	var orgs []SiteOrganization
+	dom.Find(".vcard, .h-card").Each(func(_ int, card *goquery.Selection) {
+		text := func(selector string) []string {
+			var out []string
+			card.Find(selector).Each(func(_ int, s *goquery.Selection) {
+				v := microdataValue(s)
+				if v == "" {
+					v = s.AttrOr("title", "")
+				}
+				if v != "" {
+					out = append(out, v)
+				}
+			})
+			return out
+		}
+		org := SiteOrganization{Type: "Person", Format: FormatHCard}
+		org.Name = firstString(text(".fn, .p-name"))
+		if company := firstString(text(".org, .p-org")); company != "" {
+			// Carte d'une organisation (fn = org) ou d'une personne de l'organisation
+			if org.Name == "" || strings.EqualFold(org.Name, company) || card.Find(".fn.org").Length() > 0 {
+				org.Type = "Organization"
+				org.Name = company
+			}
+		}
+		org.URL = firstString(text(".url, .u-url"))
+		org.Emails = text(".email, .u-email")
+		org.Phones = text(".tel, .p-tel")
+		card.Find(".adr, .p-adr, .h-adr").Each(func(_ int, a *goquery.Selection) {
+			field := func(selector string) string {
+				return strings.Join(strings.Fields(a.Find(selector).First().Text()), " ")
+			}
+			addr := PostalAddress{
+				Street:     field(".street-address, .p-street-address"),
+				Locality:   field(".locality, .p-locality"),
+				Region:     field(".region, .p-region"),
+				PostalCode: field(".postal-code, .p-postal-code"),
+				Country:    field(".country-name, .p-country-name"),
+			}
+			if addr == (PostalAddress{}) {
+				addr.Text = strings.Join(strings.Fields(a.Text()), " ")
+			}
+			org.Address = append(org.Address, addr)
+		})
+		orgs = append(orgs, org)
+	})
+	return orgs
+}
+
+// parseVCards lit un fichier vCard (.vcf), une organisation ou personne par carte
+func parseVCards(data []byte, source, country string) []SiteOrganization {
+	var orgs []SiteOrganization
+	var card *SiteOrganization
+	var fn, company, kind string
+	for _, line := range unfoldVCard(data) {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		params := strings.Split(name, ";")
+		prop := strings.ToUpper(params[0])
+		if i := strings.LastIndex(prop, "."); i != -1 { // item1.EMAIL
+			prop = prop[i+1:]
+		}
+		value = vCardUnescape(strings.TrimSpace(value))
+		switch prop {
+		case "BEGIN":
+			card = &SiteOrganization{Format: FormatVCard, Source: source}
+			fn, company, kind = "", "", ""
+		case "END":
+			if card == nil {
+				continue
+			}
+			card.Type, card.Name = "Person", fn
+			if strings.EqualFold(kind, "org") || (company != "" && (fn == "" || strings.EqualFold(fn, company))) {
+				card.Type, card.Name = "Organization", company
+			}
+			card.normalize(country)
+			orgs = append(orgs, *card)
+			card = nil
+		}
+		if card == nil || value == "" {
+			continue
+		}
+		switch prop {
+		case "FN":
+			fn = value
+		case "ORG":
+			company = strings.TrimRight(strings.ReplaceAll(value, ";", ", "), ", ")
+		case "KIND", "X-ADDRESSBOOKSERVER-KIND":
+			kind = value
+		case "EMAIL":
+			card.Emails = append(card.Emails, value)
+		case "TEL":
+			card.Phones = append(card.Phones, value)
+		case "URL":
+			if card.URL == "" {
+				card.URL = value
+			} else {
+				card.SameAs = append(card.SameAs, value)
+			}
+		case "X-SOCIALPROFILE":
+			card.SameAs = append(card.SameAs, value)
+		case "ADR":
+			// boîte postale;complément;rue;ville;région;code postal;pays
+			parts := strings.Split(value, ";")
+			for len(parts) < 7 {
+				parts = append(parts, "")
+			}
+			street := strings.TrimSpace(strings.Join(strings.Fields(parts[1]+" "+parts[2]), " "))
+			card.Address = append(card.Address, PostalAddress{
+				Street: street, Locality: parts[3], Region: parts[4], PostalCode: parts[5], Country: parts[6],
+			})
+		}
+	}
+	return orgs
+}
+
+// unfoldVCard rassemble les lignes repliées (continuation commençant par un espace)
+func unfoldVCard(data []byte) []string {
+	var lines []string
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 64<<10), 1<<20)
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func vCardUnescape(s string) string {
+	return strings.NewReplacer(`\,`, ",", `\;`, ";", `\n`, " ", `\N`, " ", `\\`, `\`).Replace(s)
+}
+
+// normalize valide les emails, normalise les téléphones et retire les doublons
+func (o *SiteOrganization) normalize(country string) {
+	var emails []string
+	for _, e := range o.Emails {
+		if email := normalizeStructuredEmail(e); email != "" {
+			emails = append(emails, email)
+		}
+	}
+	o.Emails = uniqueStrings(emails)
+
+	// Pays de l'adresse s'il est donné en code ISO, sinon pays du site
+	if len(o.Address) > 0 {
+		if c := strings.ToUpper(o.Address[0].Country); len(c) == 2 {
+			if _, ok := phoneCountries[c]; ok {
+				country = c
+			}
+		}
+	}
+	var phones []string
+	for _, raw := range o.Phones {
+		raw = strings.TrimSpace(strings.TrimPrefix(raw, "tel:"))
+		if raw == "" {
+			continue
+		}
+		if p, err := ParsePhone(raw, country); err == nil {
+			raw = p.E164
+		}
+		phones = append(phones, raw)
+	}
+	o.Phones = uniqueStrings(phones)
+	o.SameAs = uniqueStrings(o.SameAs)
+	o.Name = strings.Join(strings.Fields(o.Name), " ")
+}
+
+// key identifie une organisation répétée sur plusieurs pages (en-tête ou pied de page commun)
+func (o SiteOrganization) key() string {
+	return strings.Join([]string{o.Format, o.Type, strings.ToLower(o.Name), strings.Join(o.Emails, ","), strings.Join(o.Phones, ",")}, "|")
+}
+
+// isSocialProfile indique si une URL sameAs est un profil social reconnu
+func isSocialProfile(u string) bool {
+	return socialRegex.MatchString(u + " ")
+}
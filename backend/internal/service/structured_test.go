@@ -0,0 +1,123 @@
+package service
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestMailtoAddresses(t *testing.T) {
+	tests := []struct {
+		href string
+		want []string
+	}{
+		{"mailto:info@example.com", []string{"info@example.com"}},
+		{"MAILTO:Info@Example.com?subject=Devis%20info@spam.example", []string{"info@example.com"}},
+		{"mailto:a%40example.com,b@example.com?cc=c@example.com&body=x", []string{"a@example.com", "b@example.com", "c@example.com"}},
+		{"mailto:?to=d@example.com", []string{"d@example.com"}},
+		{"mailto:not-an-email", nil},
+		{"https://example.com", nil},
+	}
+	for _, tt := range tests {
+		if got := mailtoAddresses(tt.href); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("mailtoAddresses(%q) = %q, want %q", tt.href, got, tt.want)
+		}
+	}
+}
+
+func TestTelNumber(t *testing.T) {
+	tests := []struct{ href, want string }{
+		{"tel:+33123456789", "+33123456789"},
+		{"TEL:+33-4-78-99-88-77;ext=12", "+33-4-78-99-88-77"},
+		{"tel:01%2023%2045%2067%2089", "01 23 45 67 89"},
+		{"tel:", ""},
+		{"mailto:info@example.com", ""},
+	}
+	for _, tt := range tests {
+		if got := telNumber(tt.href); got != tt.want {
+			t.Errorf("telNumber(%q) = %q, want %q", tt.href, got, tt.want)
+		}
+	}
+}
+
+// organizationSummary garde les champs comparés par les tests
+type organizationSummary struct {
+	Type, Name, Format string
+	Emails, Phones     []string
+}
+
+func summarize(orgs []SiteOrganization) []organizationSummary {
+	out := make([]organizationSummary, len(orgs))
+	for i, o := range orgs {
+		out[i] = organizationSummary{Type: o.Type, Name: o.Name, Format: o.Format, Emails: o.Emails, Phones: o.Phones}
+	}
+	return out
+}
+
+func TestPageOrganizations(t *testing.T) {
+	page, err := os.ReadFile("testdata/structured/company.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(string(page)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	orgs := pageOrganizations(doc.Find("html"), "https://www.atelier-dupont.fr/contact", "FR")
+	want := []organizationSummary{
+		{"Organization", "Atelier Dupont", FormatJSONLD, []string{"contact@atelier-dupont.fr", "ventes@atelier-dupont.fr"}, []string{"+33123456789", "+33612345678"}},
+		{"Person", "Jeanne Dupont", FormatJSONLD, []string{"jeanne@atelier-dupont.fr"}, nil},
+		{"Restaurant", "Le Bistrot Dupont", FormatMicrodata, []string{"bistrot@atelier-dupont.fr"}, []string{"+33478000000"}},
+		{"Person", "Paul Martin", FormatMicrodata, nil, nil},
+		{"Organization", "Dupont Formation", FormatHCard, []string{"formation@atelier-dupont.fr"}, []string{"+33472112233"}},
+	}
+	if got := summarize(orgs); !reflect.DeepEqual(got, want) {
+		t.Fatalf("pageOrganizations =\n%+v\nwant\n%+v", got, want)
+	}
+
+	org := orgs[0]
+	wantAddr := PostalAddress{Street: "12 rue des Artisans", Locality: "Lyon", PostalCode: "69002", Country: "FR"}
+	if len(org.Address) != 1 || org.Address[0] != wantAddr {
+		t.Errorf("address = %+v, want %+v", org.Address, wantAddr)
+	}
+	if len(org.SameAs) != 2 || !isSocialProfile(org.SameAs[0]) || isSocialProfile(org.SameAs[1]) {
+		t.Errorf("sameAs = %v", org.SameAs)
+	}
+	if addr := orgs[2].Address; len(addr) != 1 || addr[0].Street != "3 place Bellecour" {
+		t.Errorf("microdata address = %+v", addr)
+	}
+
+	emails, phones := pageLinks(doc.Find("html"))
+	wantEmails := []string{"bistrot@atelier-dupont.fr", "formation@atelier-dupont.fr", "presse@atelier-dupont.fr", "rh@atelier-dupont.fr", "direction@atelier-dupont.fr"}
+	if !reflect.DeepEqual(emails, wantEmails) {
+		t.Errorf("pageLinks emails = %v, want %v", emails, wantEmails)
+	}
+	if want := []string{"+33478000000", "+33-4-78-99-88-77"}; !reflect.DeepEqual(phones, want) {
+		t.Errorf("pageLinks phones = %v, want %v", phones, want)
+	}
+}
+
+func TestParseVCards(t *testing.T) {
+	data, err := os.ReadFile("testdata/structured/company.vcf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	orgs := parseVCards(data, "https://www.atelier-dupont.fr/contact/jeanne-dupont.vcf", "FR")
+	want := []organizationSummary{
+		{"Person", "Jeanne Dupont", FormatVCard, []string{"jeanne@atelier-dupont.fr"}, []string{"+33698765432"}},
+		{"Organization", "Atelier Dupont", FormatVCard, []string{"contact@atelier-dupont.fr"}, []string{"+33123456789"}},
+	}
+	if got := summarize(orgs); !reflect.DeepEqual(got, want) {
+		t.Fatalf("parseVCards =\n%+v\nwant\n%+v", got, want)
+	}
+	// Ligne repliée et adresse ADR découpée
+	if want := []string{"https://www.linkedin.com/company/atelier-dupont"}; !reflect.DeepEqual(orgs[0].SameAs, want) {
+		t.Errorf("sameAs = %v, want %v", orgs[0].SameAs, want)
+	}
+	if addr := orgs[0].Address; len(addr) != 1 || addr[0].Locality != "Lyon" || addr[0].Country != "FR" {
+		t.Errorf("address = %+v", addr)
+	}
+}
@@ -0,0 +1,435 @@
+package service
+
+import (
+	"backend/internal/infra"
+	"backend/internal/model"
+	"backend/internal/util"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Durée pendant laquelle un processus réutilise la liste de suppression chargée
+// (les modifications faites par un autre processus sont vues au plus tard après ce délai)
+const suppressionCacheTTL = 30 * time.Second
+
+var (
+	ErrInvalidSuppression  = errors.New("invalid suppression entry")
+	ErrSuppressionNotFound = errors.New("suppression not found")
+)
+
+var suppressionKinds = []string{model.SuppressionEmail, model.SuppressionCanonical, model.SuppressionDomain, model.SuppressionPattern}
+
+// SuppressionInput est une entrée à ajouter; Kind est déduit de Value s'il est vide
+type SuppressionInput struct {
+	Value  string `json:"value"`
+	Kind   string `json:"kind"`
+	Reason string `json:"reason"`
+	Source string `json:"source"`
+}
+
+// SuppressionReport résume l'ajout d'entrées
+type SuppressionReport struct {
+	Added    int      `json:"added"`
+	Existing int      `json:"existing"` // déjà présentes (raison inchangée)
+	Invalid  int      `json:"invalid"`
+	Errors   []string `json:"errors,omitempty"` // premières valeurs rejetées
+}
+
+// SuppressionMatch est l'entrée qui supprime une adresse
+type SuppressionMatch struct {
+	Kind   string `json:"kind"`
+	Value  string `json:"value"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// SuppressionQuery pagine et filtre la liste de suppression
+type SuppressionQuery struct {
+	Kind     string
+	Search   string
+	Page     int
+	PageSize int
+}
+
+// normalizeSuppression valide une entrée et la ramène à sa forme enregistrée
+func normalizeSuppression(in SuppressionInput) (model.Suppression, error) {
+	v := strings.ToLower(strings.TrimSpace(in.Value))
+	kind := strings.ToLower(strings.TrimSpace(in.Kind))
+	if kind == "" {
+		switch {
+		case strings.ContainsAny(v, "*?"):
+			kind = model.SuppressionPattern
+		case !strings.Contains(v, "@") || strings.HasPrefix(v, "@"):
+			kind = model.SuppressionDomain
+		default:
+			kind = model.SuppressionEmail
+		}
+	}
+
+	switch kind {
+	case model.SuppressionEmail:
+		if !looksLikeEmail(v) {
+			return model.Suppression{}, fmt.Errorf("%w: %q is not an email", ErrInvalidSuppression, in.Value)
+		}
+	case model.SuppressionCanonical:
+		if !looksLikeEmail(v) {
+			return model.Suppression{}, fmt.Errorf("%w: %q is not an email", ErrInvalidSuppression, in.Value)
+		}
+		v = canonicalEmail(v)
+	case model.SuppressionDomain:
+		v = strings.TrimPrefix(v, "@")
+		if !strings.Contains(v, ".") || strings.ContainsAny(v, "@ /") {
+			return model.Suppression{}, fmt.Errorf("%w: %q is not a domain", ErrInvalidSuppression, in.Value)
+		}
+	case model.SuppressionPattern:
+		if v == "" || strings.Trim(v, "*?@.") == "" {
+			return model.Suppression{}, fmt.Errorf("%w: pattern %q matches everything", ErrInvalidSuppression, in.Value)
+		}
+	default:
+		return model.Suppression{}, fmt.Errorf("%w: kind must be one of %s", ErrInvalidSuppression, strings.Join(suppressionKinds, ", "))
+	}
+
+	source := strings.TrimSpace(in.Source)
+	if source == "" {
+		source = "manual"
+	}
+	return model.Suppression{Kind: kind, Value: v, Reason: strings.TrimSpace(in.Reason), Source: source}, nil
+}
+
+// AddSuppressions ajoute des entrées; une entrée déjà présente est ignorée
+func AddSuppressions(entries []SuppressionInput) (SuppressionReport, error) {
+	var report SuppressionReport
+	err := infra.GetDB().Transaction(func(tx *gorm.DB) error {
+		return addSuppressions(tx, entries, &report)
+	})
+	invalidateSuppressions()
+	return report, err
+}
+
+func addSuppressions(tx *gorm.DB, entries []SuppressionInput, report *SuppressionReport) error {
+	rows := make([]model.Suppression, 0, len(entries))
+	for _, e := range entries {
+		if strings.TrimSpace(e.Value) == "" {
+			continue
+		}
+		s, err := normalizeSuppression(e)
+		if err != nil {
+			report.Invalid++
+			if len(report.Errors) < 20 {
+				report.Errors = append(report.Errors, err.Error())
+			}
+			continue
+		}
+		rows = append(rows, s)
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	res := tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&rows, 1000)
+	report.Added += int(res.RowsAffected)
+	report.Existing += len(rows) - int(res.RowsAffected)
+	return res.Error
+}
+
+// ImportSuppressions ajoute les entrées d'un fichier spoolé. Les colonnes kind,
+// reason et source sont lues si elles existent (fichier produit par l'export);
+// reason et source servent sinon de valeurs par défaut.
+func ImportSuppressions(path, sheet, column, reason, source string) (SuppressionReport, error) {
+	reader, err := openSpooledList(path, sheet)
+	if err != nil {
+		return SuppressionReport{}, err
+	}
+	defer reader.Close()
+
+	headers := reader.Header()
+	valueCol := -1
+	for _, name := range []string{column, "value", "email", "domain"} {
+		if name != "" {
+			if valueCol = FindColumn(headers, name); valueCol != -1 {
+				break
+			}
+		}
+	}
+	if valueCol == -1 {
+		if column != "" {
+			return SuppressionReport{}, ErrColumnNotFound
+		}
+		valueCol = 0
+	}
+	kindCol, reasonCol, sourceCol := FindColumn(headers, "kind"), FindColumn(headers, "reason"), FindColumn(headers, "source")
+	cell := func(record []string, i int, def string) string {
+		if i >= 0 && i < len(record) && strings.TrimSpace(record[i]) != "" {
+			return record[i]
+		}
+		return def
+	}
+	if source == "" {
+		source = "import"
+	}
+
+	var report SuppressionReport
+	err = infra.GetDB().Transaction(func(tx *gorm.DB) error {
+		batch := make([]SuppressionInput, 0, 1000)
+		for {
+			record, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil && !util.IsRowError(err) {
+				return err
+			}
+			if err != nil || valueCol >= len(record) {
+				continue
+			}
+			batch = append(batch, SuppressionInput{
+				Value:  record[valueCol],
+				Kind:   cell(record, kindCol, ""),
+				Reason: cell(record, reasonCol, reason),
+				Source: cell(record, sourceCol, source),
+			})
+			if len(batch) == cap(batch) {
+				if err := addSuppressions(tx, batch, &report); err != nil {
+					return err
+				}
+				batch = batch[:0]
+			}
+		}
+		return addSuppressions(tx, batch, &report)
+	})
+	invalidateSuppressions()
+	return report, err
+}
+
+// ExportSuppressions écrit la liste de suppression en CSV (kind, value, reason,
+// source, createdAt), réimportable tel quel
+func ExportSuppressions(out io.Writer) error {
+	w := csv.NewWriter(out)
+	w.Write([]string{"kind", "value", "reason", "source", "createdAt"})
+	var batch []model.Suppression
+	err := infra.GetDB().Model(&model.Suppression{}).Order("id ASC").
+		FindInBatches(&batch, 1000, func(_ *gorm.DB, _ int) error {
+			for _, s := range batch {
+				w.Write([]string{s.Kind, s.Value, s.Reason, s.Source, s.CreatedAt.Format(time.RFC3339)})
+			}
+			w.Flush()
+			return w.Error()
+		}).Error
+	if err != nil {
+		return err
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// ListSuppressions retourne une page de la liste de suppression, les plus récentes d'abord
+func ListSuppressions(query SuppressionQuery) ([]model.Suppression, int64, error) {
+	q := infra.GetDB().Model(&model.Suppression{})
+	if query.Kind != "" {
+		q = q.Where("kind = ?", query.Kind)
+	}
+	if s := strings.TrimSpace(query.Search); s != "" {
+		q = q.Where("value ILIKE ? OR reason ILIKE ?", "%"+s+"%", "%"+s+"%")
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var entries []model.Suppression
+	err := q.Order("id DESC").Limit(query.PageSize).Offset((query.Page - 1) * query.PageSize).Find(&entries).Error
+	return entries, total, err
+}
+
+// DeleteSuppression retire une entrée de la liste de suppression
+func DeleteSuppression(id int64) error {
+	res := infra.GetDB().Where("id = ?", id).Delete(&model.Suppression{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrSuppressionNotFound
+	}
+	invalidateSuppressions()
+	return nil
+}
+
+// suppressionMatcher teste les adresses contre la liste de suppression chargée en mémoire
+type suppressionMatcher struct {
+	emails    map[string]model.Suppression
+	canonical map[string]model.Suppression
+	domains   map[string]model.Suppression
+	patterns  []compiledPattern
+}
+
+type compiledPattern struct {
+	re    *regexp.Regexp
+	entry model.Suppression
+}
+
+// patternRegexp convertit un motif (* = plusieurs caractères, ? = un caractère)
+// en expression régulière ancrée
+func patternRegexp(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+func newSuppressionMatcher() *suppressionMatcher {
+	return &suppressionMatcher{
+		emails:    map[string]model.Suppression{},
+		canonical: map[string]model.Suppression{},
+		domains:   map[string]model.Suppression{},
+	}
+}
+
+// add ajoute une entrée enregistrée (déjà normalisée) au matcher
+func (m *suppressionMatcher) add(s model.Suppression) {
+	switch s.Kind {
+	case model.SuppressionEmail:
+		m.emails[s.Value] = s
+	case model.SuppressionCanonical:
+		m.canonical[s.Value] = s
+	case model.SuppressionDomain:
+		m.domains[s.Value] = s
+	case model.SuppressionPattern:
+		if re, err := patternRegexp(s.Value); err == nil {
+			m.patterns = append(m.patterns, compiledPattern{re: re, entry: s})
+		}
+	}
+}
+
+func loadSuppressionMatcher() (*suppressionMatcher, error) {
+	m := newSuppressionMatcher()
+	var batch []model.Suppression
+	err := infra.GetDB().Model(&model.Suppression{}).Order("id ASC").
+		FindInBatches(&batch, 5000, func(_ *gorm.DB, _ int) error {
+			for _, s := range batch {
+				m.add(s)
+			}
+			return nil
+		}).Error
+	return m, err
+}
+
+// match retourne l'entrée qui supprime l'adresse, ou nil
+func (m *suppressionMatcher) match(email string) *SuppressionMatch {
+	e := strings.ToLower(strings.TrimSpace(email))
+	found := func(s model.Suppression) *SuppressionMatch {
+		return &SuppressionMatch{Kind: s.Kind, Value: s.Value, Reason: s.Reason}
+	}
+	if s, ok := m.emails[e]; ok {
+		return found(s)
+	}
+	if s, ok := m.canonical[canonicalEmail(e)]; ok {
+		return found(s)
+	}
+	// Domaine et domaines parents: example.com supprime aussi mail.example.com
+	for d := emailDomain(e); d != ""; {
+		if s, ok := m.domains[d]; ok {
+			return found(s)
+		}
+		i := strings.IndexByte(d, '.')
+		if i < 0 {
+			break
+		}
+		d = d[i+1:]
+	}
+	for _, p := range m.patterns {
+		if p.re.MatchString(e) {
+			return found(p.entry)
+		}
+	}
+	return nil
+}
+
+var suppressionCache struct {
+	sync.Mutex
+	matcher  *suppressionMatcher
+	loadedAt time.Time
+}
+
+// currentSuppressions retourne la liste de suppression, rechargée après suppressionCacheTTL
+func currentSuppressions() (*suppressionMatcher, error) {
+	suppressionCache.Lock()
+	defer suppressionCache.Unlock()
+	if suppressionCache.matcher != nil && time.Since(suppressionCache.loadedAt) < suppressionCacheTTL {
+		return suppressionCache.matcher, nil
+	}
+	m, err := loadSuppressionMatcher()
+	if err != nil {
+		return nil, err
+	}
+	suppressionCache.matcher = m
+	suppressionCache.loadedAt = time.Now()
+	return m, nil
+}
+
+// invalidateSuppressions force le rechargement après une modification dans ce processus
+func invalidateSuppressions() {
+	suppressionCache.Lock()
+	suppressionCache.matcher = nil
+	suppressionCache.Unlock()
+}
+
+// CheckSuppressed retourne l'entrée qui supprime chaque adresse (nil si aucune)
+func CheckSuppressed(emails []string) (map[string]*SuppressionMatch, error) {
+	m, err := currentSuppressions()
+	if err != nil {
+		return nil, err
+	}
+	matches := make(map[string]*SuppressionMatch, len(emails))
+	for _, e := range emails {
+		matches[e] = m.match(e)
+	}
+	return matches, nil
+}
+
+// suppressedResult est le résultat enregistré, sans connexion SMTP, pour une
+// adresse de la liste de suppression
+func suppressedResult(item model.WorkItem, match SuppressionMatch, checkedAt time.Time) EmailValidationResult {
+	reason := fmt.Sprintf("Suppressed (%s %s)", match.Kind, match.Value)
+	if match.Reason != "" {
+		reason += ": " + match.Reason
+	}
+	return EmailValidationResult{
+		Email:        item.Payload,
+		JobID:        item.JobID,
+		RowIndex:     item.RowIndex,
+		Status:       StatusSuppressed,
+		Reason:       reason,
+		CheckedAt:    checkedAt,
+		IsRoleBased:  isRoleBased(item.Payload),
+		IsDisposable: isDisposable(item.Payload),
+		IsFree:       isFreeEmail(item.Payload),
+	}
+}
+
+// markSuppressed signale les emails extraits qui sont dans la liste de suppression
+func markSuppressed(emails []ExtractedEmail) error {
+	m, err := currentSuppressions()
+	if err != nil {
+		return err
+	}
+	for i := range emails {
+		emails[i].Suppressed = m.match(emails[i].Email) != nil
+	}
+	return nil
+}
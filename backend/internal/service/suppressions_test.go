@@ -0,0 +1,118 @@
+package service
+
+import (
+	"backend/internal/model"
+	"errors"
+	"testing"
+)
+
+// testMatcher construit un matcher à partir d'entrées saisies comme par l'API
+func testMatcher(t *testing.T, entries ...SuppressionInput) *suppressionMatcher {
+	t.Helper()
+	m := newSuppressionMatcher()
+	for _, in := range entries {
+		s, err := normalizeSuppression(in)
+		if err != nil {
+			t.Fatalf("normalize %+v: %v", in, err)
+		}
+		m.add(s)
+	}
+	return m
+}
+
+func TestSuppressionMatcherMatch(t *testing.T) {
+	m := testMatcher(t,
+		SuppressionInput{Value: "Blocked@Example.org"},
+		SuppressionInput{Value: "John.Doe+news@gmail.com", Kind: model.SuppressionCanonical},
+		SuppressionInput{Value: "team+promo@acme.io", Kind: model.SuppressionCanonical},
+		SuppressionInput{Value: "competitor.com"},
+		SuppressionInput{Value: "@spam.net"},
+		SuppressionInput{Value: "*.example.com"},
+		SuppressionInput{Value: "info@*"},
+		SuppressionInput{Value: "a.b+c@dots.io*"},
+		SuppressionInput{Value: "(x)?@regex.io"},
+		SuppressionInput{Value: "user[1]@*.test"},
+	)
+	tests := []struct {
+		email string
+		kind  string // vide = non supprimée
+		value string
+	}{
+		// Adresse exacte, insensible à la casse et aux espaces
+		{"blocked@example.org", model.SuppressionEmail, "blocked@example.org"},
+		{"  BLOCKED@EXAMPLE.ORG ", model.SuppressionEmail, "blocked@example.org"},
+		{"blocked+x@example.org", "", ""},
+		// Même boîte: +tag, points et googlemail pour Gmail
+		{"johndoe@gmail.com", model.SuppressionCanonical, "johndoe@gmail.com"},
+		{"john.doe+other@gmail.com", model.SuppressionCanonical, "johndoe@gmail.com"},
+		{"J.O.H.N.D.O.E@googlemail.com", model.SuppressionCanonical, "johndoe@gmail.com"},
+		{"team@acme.io", model.SuppressionCanonical, "team@acme.io"},
+		{"team+x@acme.io", model.SuppressionCanonical, "team@acme.io"},
+		{"te.am@acme.io", "", ""}, // points significatifs hors Gmail
+		// Domaine et sous-domaines, pas les domaines qui finissent pareil
+		{"ceo@competitor.com", model.SuppressionDomain, "competitor.com"},
+		{"ceo@mail.eu.competitor.com", model.SuppressionDomain, "competitor.com"},
+		{"ceo@notcompetitor.com", "", ""},
+		{"ceo@competitor.com.evil.io", "", ""},
+		{"x@SPAM.net", model.SuppressionDomain, "spam.net"},
+		// *.example.com: sous-domaines seulement, le domaine lui-même n'est pas visé
+		{"a@mail.example.com", model.SuppressionPattern, "*.example.com"},
+		{"a@example.com", "", ""},
+		{"a@example.com.au", "", ""},
+		{"INFO@anything.fr", model.SuppressionPattern, "info@*"},
+		{"xinfo@anything.fr", "", ""},
+		// Métacaractères d'expression régulière pris littéralement
+		{"a.b+c@dots.io", model.SuppressionPattern, "a.b+c@dots.io*"},
+		{"axb+c@dots.io", "", ""},
+		{"a.bbc@dots.io", "", ""},
+		{"(x)1@regex.io", model.SuppressionPattern, "(x)?@regex.io"},
+		{"x1@regex.io", "", ""},
+		{"user[1]@a.test", model.SuppressionPattern, "user[1]@*.test"},
+		{"user1@a.test", "", ""},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		got := m.match(tt.email)
+		switch {
+		case tt.kind == "" && got != nil:
+			t.Errorf("%q: suppressed by %s %q, want not suppressed", tt.email, got.Kind, got.Value)
+		case tt.kind != "" && got == nil:
+			t.Errorf("%q: not suppressed, want %s %q", tt.email, tt.kind, tt.value)
+		case tt.kind != "" && (got.Kind != tt.kind || got.Value != tt.value):
+			t.Errorf("%q: suppressed by %s %q, want %s %q", tt.email, got.Kind, got.Value, tt.kind, tt.value)
+		}
+	}
+}
+
+func TestNormalizeSuppression(t *testing.T) {
+	tests := []struct {
+		in    SuppressionInput
+		kind  string
+		value string
+	}{
+		{SuppressionInput{Value: " Foo@Bar.com "}, model.SuppressionEmail, "foo@bar.com"},
+		{SuppressionInput{Value: "@Bar.com"}, model.SuppressionDomain, "bar.com"},
+		{SuppressionInput{Value: "bar.com"}, model.SuppressionDomain, "bar.com"},
+		{SuppressionInput{Value: "*@*.GOV"}, model.SuppressionPattern, "*@*.gov"},
+		{SuppressionInput{Value: "J.Doe+x@GMail.com", Kind: "canonical"}, model.SuppressionCanonical, "jdoe@gmail.com"},
+	}
+	for _, tt := range tests {
+		s, err := normalizeSuppression(tt.in)
+		if err != nil || s.Kind != tt.kind || s.Value != tt.value {
+			t.Errorf("%+v: got %s %q (err %v), want %s %q", tt.in, s.Kind, s.Value, err, tt.kind, tt.value)
+		}
+	}
+	for _, in := range []SuppressionInput{
+		{Value: "localhost"},
+		{Value: "*"},
+		{Value: "*@*"},
+		{Value: "?.*"},
+		{Value: "not an email", Kind: "email"},
+		{Value: "x", Kind: "canonical"},
+		{Value: "a@b.com", Kind: "regex"},
+	} {
+		if _, err := normalizeSuppression(in); !errors.Is(err, ErrInvalidSuppression) {
+			t.Errorf("%+v: err = %v, want ErrInvalidSuppression", in, err)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"backend/internal/infra"
+	"backend/internal/model"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var migrateOnce sync.Once
+
+// testDB ouvre la base Postgres de test (TEST_DATABASE_URL) comme base du service;
+// les tests qui en ont besoin sont ignorés sans elle
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	os.Setenv("DATABASE_URL", dsn)
+	db := infra.GetDB()
+	var err error
+	migrateOnce.Do(func() {
+		err = db.AutoMigrate(&model.BulkJob{}, &model.EmailResult{}, &model.WorkItem{}, &model.Worker{}, &model.ExtractResult{}, &model.ExtractContact{}, &model.RobotsSkip{}, &model.ExtractOrganization{}, &model.Upload{}, &model.Webhook{}, &model.WebhookDelivery{}, &model.Schedule{}, &model.StatusChange{}, &model.List{}, &model.ListMember{}, &model.Suppression{})
+	})
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+// createTestJob crée un job en file avec une ligne par valeur; le job et ses
+// lignes sont supprimés à la fin du test
+func createTestJob(t *testing.T, job model.BulkJob, values ...string) model.BulkJob {
+	t.Helper()
+	db := testDB(t)
+	if job.Kind == "" {
+		job.Kind = model.WorkKindVerify
+	}
+	rows := make([]SourceRow, len(values))
+	for i, v := range values {
+		rows[i] = SourceRow{Index: i, Value: v, Columns: []string{v}}
+	}
+	job, err := CreateQueuedBulkJob(job, rows)
+	if err != nil {
+		t.Fatalf("create job: %v", err)
+	}
+	t.Cleanup(func() { deleteTestJob(db, job.ID) })
+	return job
+}
+
+func deleteTestJob(db *gorm.DB, jobId string) {
+	for _, m := range []interface{}{&model.EmailResult{}, &model.ExtractResult{}, &model.ExtractContact{}, &model.RobotsSkip{}, &model.ExtractOrganization{}, &model.WorkItem{}, &model.StatusChange{}} {
+		db.Where("job_id = ?", jobId).Delete(m)
+	}
+	db.Where("id = ?", jobId).Delete(&model.BulkJob{})
+}
+
+// claimTestItems réserve les éléments en attente du job pour workerID, sans
+// passer par l'ordonnanceur (d'autres jobs peuvent exister dans la base de test)
+func claimTestItems(t *testing.T, jobId, workerID string) []model.WorkItem {
+	t.Helper()
+	db := testDB(t)
+	var items []model.WorkItem
+	if err := db.Where("job_id = ? AND status = ?", jobId, model.WorkItemPending).Order("id").Find(&items).Error; err != nil {
+		t.Fatal(err)
+	}
+	leaseUntil := time.Now().Add(workLeaseDuration)
+	for i := range items {
+		items[i].Status = model.WorkItemLeased
+		items[i].LeasedBy = workerID
+		items[i].LeaseUntil = &leaseUntil
+		items[i].Attempts++
+		if err := db.Save(&items[i]).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	now := time.Now()
+	db.Model(&model.BulkJob{}).Where("id = ? AND status = ?", jobId, model.JobStatusQueued).
+		Updates(map[string]interface{}{"status": model.JobStatusProcessing, "started_at": &now})
+	return items
+}
@@ -0,0 +1,255 @@
+package service
+
+import (
+	"backend/internal/config"
+	"backend/internal/infra"
+	"backend/internal/model"
+	"backend/internal/util"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"mime/multipart"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Nombre de lignes lues pour deviner la colonne email/site
+const columnSampleSize = 200
+
+// Nombre maximal de lignes retournées par la prévisualisation
+const MaxPreviewRows = 100
+
+var (
+	ErrUploadNotFound = errors.New("upload not found")
+	ErrColumnNotFound = errors.New("column not found")
+)
+
+var websiteRegex = regexp.MustCompile(`^(?i)(https?://)?(www\.)?([a-z0-9\-]+\.)+[a-z]{2,}(:\d+)?(/\S*)?$`)
+
+// Noms de colonnes usuels, comparés après normalisation (minuscules, sans séparateurs)
+var (
+	emailHeaderHints   = []string{"email", "emails", "mail", "emailaddress", "adresseemail", "courriel", "البريد", "البريدالإلكتروني"}
+	websiteHeaderHints = []string{"website", "site", "siteweb", "url", "domain", "domaine", "web", "homepage", "الموقع", "موقع"}
+)
+
+// ColumnGuess est la colonne devinée à partir de l'en-tête et des valeurs échantillonnées
+type ColumnGuess struct {
+	Index      int     `json:"index"`
+	Name       string  `json:"name"`
+	Confidence float64 `json:"confidence"` // part des valeurs au bon format (0-1)
+}
+
+// UploadPreview est la réponse de /api/uploads/preview
+type UploadPreview struct {
+	UploadID      string        `json:"uploadId"`
+	FileName      string        `json:"fileName"`
+	Source        util.ListInfo `json:"source"`
+	Headers       []string      `json:"headers"`
+	Rows          [][]string    `json:"rows"`
+	EmailColumn   *ColumnGuess  `json:"emailColumn"`
+	WebsiteColumn *ColumnGuess  `json:"websiteColumn"`
+}
+
+// PreviewUpload spoole le fichier, lit ses premières lignes et devine les colonnes.
+// Le fichier est conservé (table uploads) pour qu'un job le référence par uploadId.
+func PreviewUpload(fileHeader *multipart.FileHeader, sheet string, rows int) (UploadPreview, error) {
+	spoolPath, err := SpoolUpload(fileHeader)
+	if err != nil {
+		return UploadPreview{}, err
+	}
+	headers, info, sample, err := sampleSpooledList(spoolPath, sheet, max(rows, columnSampleSize))
+	if err != nil {
+		os.Remove(spoolPath)
+		return UploadPreview{}, err
+	}
+
+	upload := model.Upload{
+		FileName:  fileHeader.Filename,
+		SpoolPath: spoolPath,
+		Size:      fileHeader.Size,
+		Format:    info.Format,
+		Sheet:     info.Sheet,
+		CreatedAt: time.Now(),
+	}
+	if err := infra.GetDB().Create(&upload).Error; err != nil {
+		os.Remove(spoolPath)
+		return UploadPreview{}, err
+	}
+
+	preview := UploadPreview{
+		UploadID: upload.ID,
+		FileName: upload.FileName,
+		Source:   info,
+		Headers:  headers,
+		Rows:     sample[:min(rows, len(sample))],
+	}
+	if guess, ok := GuessColumn(headers, sample, model.WorkKindVerify); ok {
+		preview.EmailColumn = &guess
+	}
+	if guess, ok := GuessColumn(headers, sample, model.WorkKindExtract); ok {
+		preview.WebsiteColumn = &guess
+	}
+	return preview, nil
+}
+
+// sampleSpooledList lit l'en-tête et jusqu'à n lignes de données
+func sampleSpooledList(path, sheet string, n int) ([]string, util.ListInfo, [][]string, error) {
+	reader, err := openSpooledList(path, sheet)
+	if err != nil {
+		return nil, util.ListInfo{}, nil, err
+	}
+	defer reader.Close()
+	rows := [][]string{}
+	for len(rows) < n {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if util.IsRowError(err) {
+				continue
+			}
+			return nil, util.ListInfo{}, nil, err
+		}
+		rows = append(rows, record)
+	}
+	return reader.Header(), reader.Info(), rows, nil
+}
+
+// ResolveColumn retourne l'index de la colonne nommée name, ou la colonne devinée
+// (emails pour verify, sites pour extract) quand name est vide. Une liste à une
+// seule colonne a souvent un en-tête généré (fichier texte, tableau JSON de
+// chaînes): le nom demandé n'y figure pas, la colonne est alors retenue si ses
+// valeurs ont la forme attendue.
+func ResolveColumn(path, sheet string, headers []string, name, kind string) (int, error) {
+	if strings.TrimSpace(name) != "" {
+		if i := FindColumn(headers, name); i != -1 {
+			return i, nil
+		}
+		if len(headers) != 1 {
+			return -1, ErrColumnNotFound
+		}
+	}
+	headers, _, sample, err := sampleSpooledList(path, sheet, columnSampleSize)
+	if err != nil {
+		return -1, err
+	}
+	guess, ok := GuessColumn(headers, sample, kind)
+	if !ok {
+		return -1, ErrColumnNotFound
+	}
+	return guess.Index, nil
+}
+
+// GuessColumn choisit la colonne dont la majorité des valeurs ressemblent à des
+// emails (verify) ou à des sites (extract); un nom de colonne usuel départage
+func GuessColumn(headers []string, sample [][]string, kind string) (ColumnGuess, bool) {
+	matches, hints := looksLikeEmail, emailHeaderHints
+	if kind == model.WorkKindExtract {
+		matches, hints = looksLikeWebsite, websiteHeaderHints
+	}
+
+	best, bestScore := ColumnGuess{Index: -1}, 0.0
+	for i, h := range headers {
+		filled, matched := 0, 0
+		for _, row := range sample {
+			if i >= len(row) || strings.TrimSpace(row[i]) == "" {
+				continue
+			}
+			filled++
+			if matches(strings.TrimSpace(row[i])) {
+				matched++
+			}
+		}
+		confidence := 0.0
+		if filled > 0 {
+			confidence = float64(matched) / float64(filled)
+		}
+		score := confidence
+		if headerMatches(h, hints) {
+			score += 0.25
+		}
+		if score > bestScore {
+			best, bestScore = ColumnGuess{Index: i, Name: h, Confidence: confidence}, score
+		}
+	}
+	// Sans valeurs échantillonnées, un nom de colonne usuel suffit
+	return best, best.Index != -1 && (bestScore >= 0.5 || len(sample) == 0 && bestScore > 0)
+}
+
+func headerMatches(header string, hints []string) bool {
+	h := strings.ToLower(header)
+	h = strings.NewReplacer(" ", "", "-", "", "_", "", ".", "").Replace(strings.TrimSpace(h))
+	for _, hint := range hints {
+		if h == hint {
+			return true
+		}
+	}
+	return false
+}
+
+func looksLikeEmail(v string) bool {
+	return strings.Count(v, "@") == 1 && emailRegex.FindString(v) == v && isValidEmail(v)
+}
+
+func looksLikeWebsite(v string) bool {
+	return !strings.Contains(v, "@") && websiteRegex.MatchString(v)
+}
+
+// GetUpload retourne un fichier prévisualisé non encore utilisé
+func GetUpload(id string) (model.Upload, error) {
+	var upload model.Upload
+	if err := infra.GetDB().Where("id = ?", id).First(&upload).Error; err != nil {
+		return upload, ErrUploadNotFound
+	}
+	return upload, nil
+}
+
+// ClaimUpload retire l'upload de la table: son fichier appartient désormais au job
+// (supprimé en fin d'ingestion). Échoue si un autre job l'a déjà utilisé.
+func ClaimUpload(id string) error {
+	res := infra.GetDB().Where("id = ?", id).Delete(&model.Upload{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrUploadNotFound
+	}
+	return nil
+}
+
+// PurgeExpiredUploads supprime les fichiers prévisualisés jamais utilisés par un job
+func PurgeExpiredUploads(ttl time.Duration) error {
+	db := infra.GetDB()
+	var expired []model.Upload
+	if err := db.Where("created_at < ?", time.Now().Add(-ttl)).Find(&expired).Error; err != nil {
+		return err
+	}
+	for _, upload := range expired {
+		if err := ClaimUpload(upload.ID); err != nil {
+			continue
+		}
+		os.Remove(upload.SpoolPath)
+	}
+	return nil
+}
+
+// RunUploadJanitor purge régulièrement les uploads expirés jusqu'à l'annulation du contexte
+func RunUploadJanitor(ctx context.Context) {
+	ttl := config.Load().UploadTTL
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		if err := PurgeExpiredUploads(ttl); err != nil {
+			log.Printf("uploads: purge failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -38,6 +38,10 @@ const (
 	StatusValid     EmailStatus = "valid"
 	StatusInvalid   EmailStatus = "invalid"
 	StatusAcceptAll EmailStatus = "accept_all"
+	// Doublon écarté avant vérification
+	StatusDuplicate EmailStatus = "duplicate"
+	// Adresse de la liste de suppression, jamais vérifiée
+	StatusSuppressed EmailStatus = "suppressed"
 )
 
 type EmailValidationResult struct {
@@ -51,7 +55,11 @@ type EmailValidationResult struct {
 	Reason        string       `json:"reason"`
 	CheckedAt     time.Time    `json:"checkedAt"`
 	JobID         string       `json:"jobId"`
+	RowIndex      int          `json:"rowIndex"`
 	Status        EmailStatus  `json:"status"`
+	IsFree        bool         `json:"isFree"`
+	Score         int          `json:"score"`
+	Suggestion    string       `json:"suggestion,omitempty"`
 }
 
 func VerifyEmailHandler(c *gin.Context) {
@@ -113,7 +121,17 @@ func isRoleBased(email string) bool {
 	return roleBasedPrefixes[local]
 }
 
+// ValidateEmailSMTP vérifie l'email puis complète le résultat avec le score et
+// une éventuelle suggestion de correction
 func ValidateEmailSMTP(email string) (EmailValidationResult, error) {
+	result, err := validateEmailSMTP(email)
+	result.IsFree = isFreeEmail(email)
+	result.Suggestion = suggestEmail(email)
+	result.Score = scoreResult(result)
+	return result, err
+}
+
+func validateEmailSMTP(email string) (EmailValidationResult, error) {
 	result := EmailValidationResult{Email: email}
 	result.IsRoleBased = isRoleBased(email)
 	result.IsDisposable = isDisposable(email)
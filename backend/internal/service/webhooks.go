@@ -0,0 +1,409 @@
+package service
+
+import (
+	"backend/internal/config"
+	"backend/internal/infra"
+	"backend/internal/model"
+	"backend/internal/util"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"syscall"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	webhookMaxAttempts  = 8
+	webhookBaseDelay    = 30 * time.Second // 30s, 1m, 2m, 4m... entre deux essais
+	webhookMaxDelay     = time.Hour
+	webhookTimeout      = 10 * time.Second
+	webhookPollInterval = 5 * time.Second
+)
+
+var (
+	ErrInvalidWebhookURL   = errors.New("webhook url must be an absolute http(s) url")
+	ErrInvalidWebhookEvent = errors.New("unknown webhook event")
+	ErrWebhookNotFound     = errors.New("webhook not found")
+)
+
+var webhookEvents = []string{model.WebhookEventJobFinished, model.WebhookEventJobFailed, model.WebhookEventJobCancelled, model.WebhookEventScheduleDiff}
+
+// Événements souscrits par défaut par un webhook du compte
+var defaultWebhookEvents = []string{model.WebhookEventJobFinished, model.WebhookEventJobFailed}
+
+// Le client des webhooks contrôle l'adresse réellement contactée à chaque connexion
+// (redirections et DNS rebinding compris); pas de proxy pour que ce soit celle du destinataire
+var webhookClient = &http.Client{
+	Timeout: webhookTimeout,
+	Transport: &http.Transport{
+		DialContext:         (&net.Dialer{Timeout: webhookTimeout, Control: webhookDialControl}).DialContext,
+		TLSHandshakeTimeout: webhookTimeout,
+		MaxIdleConns:        10,
+		IdleConnTimeout:     90 * time.Second,
+	},
+}
+
+// Réseau partagé des opérateurs (RFC 6598), où se trouvent aussi certains services de métadonnées
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// WebhookPayload est le corps JSON envoyé aux webhooks
+type WebhookPayload struct {
+	Event      string     `json:"event"`
+	OccurredAt time.Time  `json:"occurredAt"`
+	Job        JobSummary `json:"job"`
+}
+
+// WebhookView est un webhook du compte avec ses événements décodés
+type WebhookView struct {
+	model.Webhook
+	Events []string `json:"events"`
+}
+
+// ValidateWebhookURL vérifie qu'une URL de webhook est utilisable
+func ValidateWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return ErrInvalidWebhookURL
+	}
+	if config.Load().WebhookAllowPrivate {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil || len(addrs) == 0 {
+		return fmt.Errorf("%w: cannot resolve %s", ErrInvalidWebhookURL, u.Hostname())
+	}
+	for _, a := range addrs {
+		if forbiddenWebhookIP(a.IP) {
+			return fmt.Errorf("%w: %s resolves to a non-public address", ErrInvalidWebhookURL, u.Hostname())
+		}
+	}
+	return nil
+}
+
+// forbiddenWebhookIP indique si une adresse n'est pas publique: bouclage, réseaux
+// privés, lien local (dont les métadonnées cloud 169.254.169.254), non spécifiée,
+// multicast ou réseau partagé des opérateurs
+func forbiddenWebhookIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsUnspecified() || ip.IsMulticast() || sharedAddressSpace.Contains(ip)
+}
+
+// webhookDialControl refuse, au moment de l'envoi, une connexion vers une adresse non publique
+func webhookDialControl(network, address string, _ syscall.RawConn) error {
+	if config.Load().WebhookAllowPrivate {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || forbiddenWebhookIP(ip) {
+		return fmt.Errorf("webhook target %s is not a public address", host)
+	}
+	return nil
+}
+
+// NewWebhookSecret génère un secret de signature
+func NewWebhookSecret() string {
+	b := make([]byte, 24)
+	rand.Read(b)
+	return "whsec_" + hex.EncodeToString(b)
+}
+
+// CreateWebhook enregistre un webhook du compte; le secret n'est retourné qu'ici
+func CreateWebhook(rawURL string, events []string, secret string) (WebhookView, string, error) {
+	if err := ValidateWebhookURL(rawURL); err != nil {
+		return WebhookView{}, "", err
+	}
+	if len(events) == 0 {
+		events = defaultWebhookEvents
+	}
+	for _, e := range events {
+		if !slices.Contains(webhookEvents, e) {
+			return WebhookView{}, "", fmt.Errorf("%w: %s", ErrInvalidWebhookEvent, e)
+		}
+	}
+	if secret == "" {
+		secret = NewWebhookSecret()
+	}
+	encoded, _ := json.Marshal(events)
+	hook := model.Webhook{URL: rawURL, Secret: secret, Events: string(encoded), Active: true}
+	if err := infra.GetDB().Create(&hook).Error; err != nil {
+		return WebhookView{}, "", err
+	}
+	return WebhookView{Webhook: hook, Events: events}, secret, nil
+}
+
+// ListWebhooks retourne les webhooks du compte
+func ListWebhooks() ([]WebhookView, error) {
+	var hooks []model.Webhook
+	if err := infra.GetDB().Order("created_at ASC").Find(&hooks).Error; err != nil {
+		return nil, err
+	}
+	views := make([]WebhookView, len(hooks))
+	for i, h := range hooks {
+		views[i] = webhookView(h)
+	}
+	return views, nil
+}
+
+func webhookView(h model.Webhook) WebhookView {
+	events := []string{}
+	json.Unmarshal([]byte(h.Events), &events)
+	return WebhookView{Webhook: h, Events: events}
+}
+
+// DeleteWebhook supprime un webhook du compte; son historique de livraisons est conservé
+func DeleteWebhook(id string) error {
+	res := infra.GetDB().Where("id = ?", id).Delete(&model.Webhook{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+// webhookEventForStatus associe le statut final d'un job à l'événement envoyé
+func webhookEventForStatus(status string) string {
+	switch status {
+	case model.JobStatusDone:
+		return model.WebhookEventJobFinished
+	case model.JobStatusFailed:
+		return model.WebhookEventJobFailed
+	case model.JobStatusCancelled:
+		return model.WebhookEventJobCancelled
+	}
+	return ""
+}
+
+// enqueueJobWebhooks crée les livraisons de fin de job: webhook du job (tous les
+// événements) et webhooks du compte abonnés à l'événement. Appelé une seule fois,
+// par le processus qui effectue la transition vers l'état final.
+func enqueueJobWebhooks(jobId string) {
+	if err := createJobDeliveries(jobId); err != nil {
+		log.Printf("webhooks: cannot enqueue deliveries for job %s: %v", jobId, err)
+	}
+}
+
+func createJobDeliveries(jobId string) error {
+	job, err := GetBulkJobByID(jobId)
+	if err != nil {
+		return err
+	}
+	event := webhookEventForStatus(job.Status)
+	if event == "" {
+		return nil
+	}
+	counts, err := jobCounts([]model.BulkJob{job})
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(WebhookPayload{Event: event, OccurredAt: time.Now(), Job: summarizeJob(job, counts[job.ID])})
+	if err != nil {
+		return err
+	}
+	return queueDeliveries(job.ID, event, payload, job.WebhookURL, job.WebhookSecret)
+}
+
+// queueDeliveries crée les livraisons d'un événement: webhook propre (url, tous
+// les événements) et webhooks du compte abonnés à l'événement
+func queueDeliveries(jobId, event string, payload []byte, url, secret string) error {
+	db := infra.GetDB()
+	now := time.Now()
+	var deliveries []model.WebhookDelivery
+	if url != "" {
+		deliveries = append(deliveries, model.WebhookDelivery{
+			JobID: jobId, URL: url, Secret: secret,
+			Event: event, Payload: string(payload), Status: model.DeliveryPending, NextAttemptAt: now,
+		})
+	}
+	var hooks []model.Webhook
+	if err := db.Where("active = ?", true).Find(&hooks).Error; err != nil {
+		return err
+	}
+	for _, h := range hooks {
+		if !slices.Contains(webhookView(h).Events, event) {
+			continue
+		}
+		id := h.ID
+		deliveries = append(deliveries, model.WebhookDelivery{
+			WebhookID: &id, JobID: jobId, URL: h.URL, Secret: h.Secret,
+			Event: event, Payload: string(payload), Status: model.DeliveryPending, NextAttemptAt: now,
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return db.Create(&deliveries).Error
+}
+
+// RunWebhookDispatcher envoie les livraisons dues jusqu'à l'annulation du contexte.
+// Plusieurs processus peuvent l'exécuter: chaque livraison est réservée (SKIP LOCKED).
+func RunWebhookDispatcher(ctx context.Context) {
+	for ctx.Err() == nil {
+		deliveries, err := claimDueDeliveries(1)
+		if err != nil {
+			log.Printf("webhooks: claim failed: %v", err)
+		}
+		for _, d := range deliveries {
+			deliverWebhook(d)
+		}
+		if len(deliveries) > 0 {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(webhookPollInterval):
+		}
+	}
+}
+
+// claimDueDeliveries réserve les livraisons dues en repoussant leur échéance le
+// temps de l'envoi: si le processus s'arrête, elles seront reprises ensuite
+func claimDueDeliveries(limit int) ([]model.WebhookDelivery, error) {
+	var deliveries []model.WebhookDelivery
+	err := infra.GetDB().Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", model.DeliveryPending, time.Now()).
+			Order("next_attempt_at ASC").
+			Limit(limit).
+			Find(&deliveries).Error
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+		ids := make([]int64, len(deliveries))
+		for i, d := range deliveries {
+			ids[i] = d.ID
+		}
+		return tx.Model(&model.WebhookDelivery{}).Where("id IN ?", ids).
+			Update("next_attempt_at", time.Now().Add(2*webhookTimeout)).Error
+	})
+	return deliveries, err
+}
+
+// deliverWebhook envoie une livraison signée; un code 2xx la termine, sinon elle
+// est reprogrammée avec un délai exponentiel jusqu'au dernier essai
+func deliverWebhook(d model.WebhookDelivery) {
+	body := []byte(d.Payload)
+	statusCode, err := postWebhook(d, body)
+
+	d.Attempts++
+	updates := map[string]interface{}{
+		"attempts":         d.Attempts,
+		"last_status_code": statusCode,
+		"last_error":       "",
+	}
+	switch {
+	case err == nil:
+		now := time.Now()
+		updates["status"] = model.DeliveryDelivered
+		updates["delivered_at"] = &now
+	case d.Attempts >= webhookMaxAttempts:
+		updates["status"] = model.DeliveryFailed
+		updates["last_error"] = err.Error()
+	default:
+		updates["next_attempt_at"] = time.Now().Add(webhookBackoff(d.Attempts))
+		updates["last_error"] = err.Error()
+	}
+	if err := infra.GetDB().Model(&model.WebhookDelivery{}).Where("id = ?", d.ID).Updates(updates).Error; err != nil {
+		log.Printf("webhooks: cannot update delivery %d: %v", d.ID, err)
+	}
+}
+
+func postWebhook(d model.WebhookDelivery, body []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, d.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "MailHound-Webhook/1.0")
+	req.Header.Set("X-MailHound-Event", d.Event)
+	req.Header.Set("X-MailHound-Delivery", strconv.FormatInt(d.ID, 10))
+	if d.Secret != "" {
+		req.Header.Set(util.SignatureHeader, util.SignPayload(d.Secret, body, time.Now()))
+	}
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// webhookBackoff retourne le délai avant l'essai suivant (attempts essais déjà faits)
+func webhookBackoff(attempts int) time.Duration {
+	delay := webhookBaseDelay
+	for i := 1; i < attempts && delay < webhookMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, webhookMaxDelay)
+}
+
+// DeliveryQuery filtre le journal des livraisons
+type DeliveryQuery struct {
+	JobID     string
+	WebhookID string
+	Status    string
+	Page      int
+	PageSize  int
+}
+
+// ListWebhookDeliveries retourne le journal des livraisons, les plus récentes d'abord
+func ListWebhookDeliveries(query DeliveryQuery) ([]model.WebhookDelivery, int64, error) {
+	q := infra.GetDB().Model(&model.WebhookDelivery{})
+	if query.JobID != "" {
+		q = q.Where("job_id = ?", query.JobID)
+	}
+	if query.WebhookID != "" {
+		q = q.Where("webhook_id = ?", query.WebhookID)
+	}
+	if query.Status != "" {
+		q = q.Where("status = ?", query.Status)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var deliveries []model.WebhookDelivery
+	err := q.Order("id DESC").Limit(query.PageSize).Offset((query.Page - 1) * query.PageSize).Find(&deliveries).Error
+	return deliveries, total, err
+}
+
+// RedeliverWebhook reprogramme immédiatement une livraison (nouvelle série d'essais)
+func RedeliverWebhook(id int64) error {
+	res := infra.GetDB().Model(&model.WebhookDelivery{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":          model.DeliveryPending,
+		"attempts":        0,
+		"next_attempt_at": time.Now(),
+	})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
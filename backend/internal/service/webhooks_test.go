@@ -0,0 +1,95 @@
+package service
+
+import (
+	"backend/internal/model"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestForbiddenWebhookIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"127.0.0.1", true},
+		{"::1", true},
+		{"10.1.2.3", true},
+		{"172.16.0.1", true},
+		{"192.168.1.10", true},
+		{"169.254.169.254", true}, // métadonnées cloud
+		{"fe80::1", true},
+		{"fd00:ec2::254", true},
+		{"100.100.100.200", true},
+		{"0.0.0.0", true},
+		{"::", true},
+		{"224.0.0.1", true},
+		{"::ffff:127.0.0.1", true},
+		{"8.8.8.8", false},
+		{"172.32.0.1", false},
+		{"100.128.0.1", false},
+		{"2606:4700:4700::1111", false},
+	}
+	for _, tt := range tests {
+		if got := forbiddenWebhookIP(net.ParseIP(tt.ip)); got != tt.want {
+			t.Errorf("forbiddenWebhookIP(%s) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestValidateWebhookURL(t *testing.T) {
+	t.Setenv("WEBHOOK_ALLOW_PRIVATE", "")
+	tests := []struct {
+		url string
+		ok  bool
+	}{
+		{"https://8.8.8.8/hook", true},
+		{"http://[2606:4700:4700::1111]:8080/hook", true},
+		{"ftp://8.8.8.8/hook", false},
+		{"/relative", false},
+		{"http://", false},
+		{"http://localhost:4000/hook", false},
+		{"http://127.0.0.1/hook", false},
+		{"http://10.0.0.5/hook", false},
+		{"http://169.254.169.254/latest/meta-data", false},
+		{"http://[::1]/hook", false},
+	}
+	for _, tt := range tests {
+		err := ValidateWebhookURL(tt.url)
+		if tt.ok && err != nil {
+			t.Errorf("%s: unexpected error %v", tt.url, err)
+		}
+		if !tt.ok && !errors.Is(err, ErrInvalidWebhookURL) {
+			t.Errorf("%s: err = %v, want ErrInvalidWebhookURL", tt.url, err)
+		}
+	}
+}
+
+func TestValidateWebhookURLAllowPrivate(t *testing.T) {
+	t.Setenv("WEBHOOK_ALLOW_PRIVATE", "true")
+	if err := ValidateWebhookURL("http://127.0.0.1:4000/hook"); err != nil {
+		t.Errorf("private url rejected with WEBHOOK_ALLOW_PRIVATE: %v", err)
+	}
+}
+
+// L'envoi vérifie l'adresse contactée, même pour une URL enregistrée avant le contrôle
+func TestPostWebhookRejectsPrivateTarget(t *testing.T) {
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+	}))
+	defer srv.Close()
+	d := model.WebhookDelivery{URL: srv.URL, Event: model.WebhookEventJobFinished}
+
+	t.Setenv("WEBHOOK_ALLOW_PRIVATE", "")
+	if _, err := postWebhook(d, []byte(`{}`)); err == nil || hits != 0 {
+		t.Fatalf("post to %s: err = %v, hits = %d, want refused", srv.URL, err, hits)
+	}
+
+	t.Setenv("WEBHOOK_ALLOW_PRIVATE", "true")
+	if status, err := postWebhook(d, []byte(`{}`)); err != nil || status != http.StatusOK || hits != 1 {
+		t.Fatalf("post with WEBHOOK_ALLOW_PRIVATE: status %d, err %v, hits %d", status, err, hits)
+	}
+}
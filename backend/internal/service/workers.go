@@ -0,0 +1,148 @@
+package service
+
+import (
+	"backend/internal/infra"
+	"backend/internal/model"
+	"context"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// Intervalle des heartbeats et du renouvellement des réservations
+const workerHeartbeatInterval = 10 * time.Second
+
+// Un worker sans heartbeat depuis ce délai est considéré comme mort
+const workerDeadAfter = 3 * workerHeartbeatInterval
+
+// Intervalle de remise en file des réservations expirées
+const leaseReapInterval = 30 * time.Second
+
+// RegisterWorker enregistre (ou ré-enregistre) le processus courant
+func RegisterWorker(workerID string, kinds []string, concurrency int) error {
+	db := infra.GetDB()
+	host, _ := os.Hostname()
+	now := time.Now()
+	w := model.Worker{
+		ID:            workerID,
+		Hostname:      host,
+		PID:           os.Getpid(),
+		Kinds:         strings.Join(kinds, ","),
+		Concurrency:   concurrency,
+		StartedAt:     now,
+		LastHeartbeat: now,
+	}
+	return db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&w).Error
+}
+
+// Heartbeat signale que le worker est vivant et prolonge ses réservations en cours
+func Heartbeat(workerID string) error {
+	db := infra.GetDB()
+	now := time.Now()
+	err := db.Model(&model.Worker{}).Where("id = ?", workerID).Update("last_heartbeat", now).Error
+	if err != nil {
+		return err
+	}
+	leaseUntil := now.Add(workLeaseDuration)
+	return db.Model(&model.WorkItem{}).
+		Where("leased_by = ? AND status = ?", workerID, model.WorkItemLeased).
+		Update("lease_until", &leaseUntil).Error
+}
+
+// DeregisterWorker retire le worker après un arrêt propre
+func DeregisterWorker(workerID string) error {
+	db := infra.GetDB()
+	return db.Where("id = ?", workerID).Delete(&model.Worker{}).Error
+}
+
+// ReapExpiredLeases remet en attente les éléments dont la réservation a expiré
+// ou dont le worker ne donne plus de heartbeat, et supprime les workers morts
+func ReapExpiredLeases() error {
+	db := infra.GetDB()
+	now := time.Now()
+	var dead []string
+	err := db.Model(&model.Worker{}).Where("last_heartbeat < ?", now.Add(-workerDeadAfter)).Pluck("id", &dead).Error
+	if err != nil {
+		return err
+	}
+
+	// Les éléments remis en file sont décomptés du compteur leased_items de leur job
+	// dans la même requête
+	var reaped []struct {
+		JobID string
+		N     int64
+	}
+	err = db.Raw(`
+		WITH reaped AS (
+			UPDATE work_items SET status = ?, leased_by = '', lease_until = NULL, updated_at = ?
+			WHERE status = ? AND (lease_until < ? OR leased_by IN ?)
+			RETURNING job_id
+		), counts AS (
+			SELECT job_id, count(*) AS n FROM reaped GROUP BY job_id
+		)
+		UPDATE bulk_jobs j SET leased_items = GREATEST(j.leased_items - c.n, 0)
+		FROM counts c WHERE j.id = c.job_id
+		RETURNING c.job_id, c.n`,
+		model.WorkItemPending, now, model.WorkItemLeased, now, dead,
+	).Scan(&reaped).Error
+	if err != nil {
+		return err
+	}
+	var total int64
+	for _, r := range reaped {
+		total += r.N
+	}
+	if total > 0 {
+		log.Printf("queue: %d work items with expired lease re-queued", total)
+	}
+	if len(dead) > 0 {
+		log.Printf("queue: removing dead workers %v", dead)
+		return db.Where("id IN ?", dead).Delete(&model.Worker{}).Error
+	}
+	return nil
+}
+
+// RunWorkerNode enregistre le worker, lance concurrency goroutines de traitement
+// ainsi que les boucles de heartbeat et de remise en file. La fonction retournée
+// attend la fin des éléments en cours après l'annulation du contexte.
+func RunWorkerNode(ctx context.Context, workerID string, kinds []string, concurrency int) (wait func()) {
+	if err := RegisterWorker(workerID, kinds, concurrency); err != nil {
+		log.Printf("worker %s: registration failed: %v", workerID, err)
+	}
+	wg := StartQueueWorkers(ctx, workerID, kinds, concurrency)
+
+	go func() {
+		heartbeat := time.NewTicker(workerHeartbeatInterval)
+		reap := time.NewTicker(leaseReapInterval)
+		defer heartbeat.Stop()
+		defer reap.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-heartbeat.C:
+				if err := Heartbeat(workerID); err != nil {
+					log.Printf("worker %s: heartbeat failed: %v", workerID, err)
+				}
+			case <-reap.C:
+				if err := ReapExpiredLeases(); err != nil {
+					log.Printf("worker %s: lease reaper failed: %v", workerID, err)
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			wg.Wait()
+			if err := DeregisterWorker(workerID); err != nil {
+				log.Printf("worker %s: deregistration failed: %v", workerID, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCron est retourné pour une expression cron mal formée
+var ErrInvalidCron = errors.New("invalid cron expression")
+
+// Raccourcis acceptés à la place des cinq champs
+var cronMacros = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+}
+
+var cronMonthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var cronDayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+// CronSchedule est une expression cron standard à cinq champs
+// (minute, heure, jour du mois, mois, jour de la semaine)
+type CronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// Jour du mois et jour de la semaine restreints tous les deux: l'un OU l'autre suffit
+	domAndDow bool
+	// Champ heure en "*" ou "*/n": l'expression suit l'heure locale même quand elle recule
+	hourly bool
+}
+
+// ParseCron lit une expression à cinq champs (listes, intervalles, pas, noms de
+// mois et de jours) ou un raccourci (@hourly, @daily, @weekly, @monthly, @yearly)
+func ParseCron(expr string) (CronSchedule, error) {
+	expr = strings.TrimSpace(strings.ToLower(expr))
+	if macro, ok := cronMacros[expr]; ok {
+		expr = macro
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return CronSchedule{}, fmt.Errorf("%w: expected 5 fields, got %d", ErrInvalidCron, len(fields))
+	}
+	var s CronSchedule
+	var err error
+	if s.minute, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return CronSchedule{}, err
+	}
+	if s.hour, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return CronSchedule{}, err
+	}
+	if s.dom, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return CronSchedule{}, err
+	}
+	if s.month, err = parseCronField(fields[3], 1, 12, cronMonthNames); err != nil {
+		return CronSchedule{}, err
+	}
+	if s.dow, err = parseCronField(fields[4], 0, 7, cronDayNames); err != nil {
+		return CronSchedule{}, err
+	}
+	// 7 = dimanche
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.domAndDow = !strings.HasPrefix(fields[2], "*") && !strings.HasPrefix(fields[4], "*")
+	s.hourly = strings.HasPrefix(fields[1], "*")
+	return s, nil
+}
+
+func parseCronField(field string, lo, hi int, names map[string]int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, step := part, 1
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("%w: bad step in %q", ErrInvalidCron, field)
+			}
+			rng, step = part[:i], n
+		}
+		start, end := lo, hi
+		if rng != "*" {
+			bounds := strings.SplitN(rng, "-", 2)
+			var err error
+			if start, err = cronValue(bounds[0], names); err != nil {
+				return 0, fmt.Errorf("%w: %q", ErrInvalidCron, field)
+			}
+			end = start
+			if len(bounds) == 2 {
+				if end, err = cronValue(bounds[1], names); err != nil {
+					return 0, fmt.Errorf("%w: %q", ErrInvalidCron, field)
+				}
+			} else if step > 1 {
+				// "5/15" = de 5 à la fin, par pas de 15
+				end = hi
+			}
+		}
+		if start < lo || end > hi || start > end {
+			return 0, fmt.Errorf("%w: %q out of range %d-%d", ErrInvalidCron, field, lo, hi)
+		}
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func cronValue(v string, names map[string]int) (int, error) {
+	if n, ok := names[v]; ok {
+		return n, nil
+	}
+	return strconv.Atoi(v)
+}
+
+// Next retourne la première échéance strictement postérieure à t, dans le fuseau de t
+// (zéro si aucune dans les cinq ans, ex. 30 février). Une heure sautée au passage à
+// l'heure d'été n'a pas d'échéance; une heure rejouée au passage à l'heure d'hiver
+// n'en a qu'une, lors de son premier passage, sauf pour une expression horaire
+// (champ heure en "*") qui s'exécute aussi pendant l'heure rejouée.
+func (s CronSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			// En temps écoulé: time.Date peut choisir le second passage d'une heure rejouée
+			t = t.Add(time.Duration(60-t.Minute()) * time.Minute)
+		case s.minute&(1<<uint(t.Minute())) == 0 || (!s.hourly && repeatedWallClock(t)):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// repeatedWallClock indique si l'heure locale de t a déjà eu lieu plus tôt: t suit
+// un recul de l'heure de moins que l'écart entre les deux décalages
+func repeatedWallClock(t time.Time) bool {
+	start, _ := t.ZoneBounds()
+	if start.IsZero() {
+		return false
+	}
+	_, before := start.Add(-time.Second).Zone()
+	_, offset := t.Zone()
+	return before > offset && t.Sub(start) < time.Duration(before-offset)*time.Second
+}
+
+func (s CronSchedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domAndDow {
+		return dom || dow
+	}
+	return dom && dow
+}
@@ -0,0 +1,155 @@
+package util
+
+import (
+	"errors"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestParseCronInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"@every 5m",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 0 *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"5-1 * * * *",
+		"1-2-3 * * * *",
+		"a * * * *",
+		"* * * foo *",
+		"* * * * monday",
+		"1,,2 * * * *",
+	} {
+		if _, err := ParseCron(expr); !errors.Is(err, ErrInvalidCron) {
+			t.Errorf("ParseCron(%q) err = %v, want ErrInvalidCron", expr, err)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	// Lundi 19 octobre 2026, 10:07 UTC
+	from := time.Date(2026, 10, 19, 10, 7, 30, 0, time.UTC)
+	at := func(y int, m time.Month, d, h, min int) time.Time {
+		return time.Date(y, m, d, h, min, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", at(2026, 10, 19, 10, 8)},
+		{"7 10 * * *", at(2026, 10, 20, 10, 7)}, // strictement après
+		{"*/15 * * * *", at(2026, 10, 19, 10, 15)},
+		{"5/15 * * * *", at(2026, 10, 19, 10, 20)},
+		{"0 9-17/4 * * *", at(2026, 10, 19, 13, 0)},
+		{"0,30 22 * * *", at(2026, 10, 19, 22, 0)},
+		{"0 0 1,15 * *", at(2026, 11, 1, 0, 0)},
+		{"0 0 * jan,jul *", at(2027, 1, 1, 0, 0)},
+		{"0 0 1 JUN-AUG *", at(2027, 6, 1, 0, 0)},
+		{"0 8 * * mon-fri", at(2026, 10, 20, 8, 0)},
+		{"0 8 * * 7", at(2026, 10, 25, 8, 0)}, // 7 = dimanche
+		{"0 8 * * sun", at(2026, 10, 25, 8, 0)},
+		{"0 8 * * sat,0", at(2026, 10, 24, 8, 0)},
+		// Jour du mois et jour de la semaine restreints: l'un ou l'autre suffit
+		{"0 0 13 * fri", at(2026, 10, 23, 0, 0)},
+		{"0 0 21 * fri", at(2026, 10, 21, 0, 0)},
+		// Jour de la semaine seul restreint: les deux doivent correspondre
+		{"0 0 * 2 mon", at(2027, 2, 1, 0, 0)},
+		{"0 0 1 * *", at(2026, 11, 1, 0, 0)},
+		{"0 0 31 * *", at(2026, 10, 31, 0, 0)},
+		{"0 0 31 11 *", time.Time{}}, // 31 novembre: jamais
+		{"0 0 29 2 *", at(2028, 2, 29, 0, 0)},
+		{"@hourly", at(2026, 10, 19, 11, 0)},
+		{"@daily", at(2026, 10, 20, 0, 0)},
+		{"@weekly", at(2026, 10, 25, 0, 0)},
+		{"@monthly", at(2026, 11, 1, 0, 0)},
+		{"@yearly", at(2027, 1, 1, 0, 0)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		s, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Errorf("ParseCron(%q): %v", tt.expr, err)
+			continue
+		}
+		if got := s.Next(from); !got.Equal(tt.want) {
+			t.Errorf("%q: Next = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+// Passages à l'heure d'été (29 mars 2026, 02:00 -> 03:00) et d'hiver (25 octobre
+// 2026, 03:00 -> 02:00) à Paris
+func TestCronNextDST(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Fatal(err)
+	}
+	local := func(m time.Month, d, h, min int) time.Time {
+		return time.Date(2026, m, d, h, min, 0, 0, paris)
+	}
+	utc := func(m time.Month, d, h, min int) time.Time {
+		return time.Date(2026, m, d, h, min, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"skipped hour has no run", "30 2 * * *", local(3, 29, 0, 0), utc(3, 30, 0, 30)},
+		{"hour after the jump", "0 3 * * *", local(3, 29, 0, 0), utc(3, 29, 1, 0)},
+		{"hourly across the jump", "0 * * * *", local(3, 29, 1, 30), utc(3, 29, 1, 0)},
+		{"every 30 min across the jump", "*/30 * * * *", local(3, 29, 1, 45), utc(3, 29, 1, 0)},
+		{"repeated hour first pass", "30 2 * * *", local(10, 25, 0, 0), utc(10, 25, 0, 30)},
+		{"repeated hour runs once", "30 2 * * *", utc(10, 25, 0, 30).In(paris), utc(10, 26, 1, 30)},
+		{"during the repeated hour", "45 2 * * *", utc(10, 25, 0, 50).In(paris), utc(10, 26, 1, 45)},
+		{"hourly runs in the repeated hour", "0 * * * *", utc(10, 25, 0, 0).In(paris), utc(10, 25, 1, 0)},
+		{"after the repeated hour", "0 3 * * *", utc(10, 25, 0, 30).In(paris), utc(10, 25, 2, 0)},
+		{"daily across fall back", "0 12 * * *", local(10, 24, 12, 0), utc(10, 25, 11, 0)},
+	}
+	for _, tt := range tests {
+		s, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseCron(%q): %v", tt.expr, err)
+		}
+		got := s.Next(tt.from)
+		if !got.Equal(tt.want) {
+			t.Errorf("%s: %q from %v: Next = %v, want %v", tt.name, tt.expr, tt.from, got.UTC(), tt.want)
+		}
+		if got.Location() != paris {
+			t.Errorf("%s: Next in %v, want Europe/Paris", tt.name, got.Location())
+		}
+	}
+}
+
+func TestRepeatedWallClock(t *testing.T) {
+	paris, _ := time.LoadLocation("Europe/Paris")
+	tests := []struct {
+		at   time.Time
+		want bool
+	}{
+		{time.Date(2026, 10, 25, 0, 30, 0, 0, time.UTC), false}, // 02:30 CEST
+		{time.Date(2026, 10, 25, 1, 0, 0, 0, time.UTC), true},   // 02:00 CET
+		{time.Date(2026, 10, 25, 1, 59, 0, 0, time.UTC), true},  // 02:59 CET
+		{time.Date(2026, 10, 25, 2, 0, 0, 0, time.UTC), false},  // 03:00 CET
+		{time.Date(2026, 3, 29, 1, 0, 0, 0, time.UTC), false},   // 03:00 CEST
+		{time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC), false},
+	}
+	for _, tt := range tests {
+		if got := repeatedWallClock(tt.at.In(paris)); got != tt.want {
+			t.Errorf("repeatedWallClock(%v) = %v, want %v", tt.at.In(paris), got, tt.want)
+		}
+	}
+	if repeatedWallClock(time.Date(2026, 10, 25, 1, 0, 0, 0, time.UTC)) {
+		t.Error("UTC never repeats")
+	}
+}
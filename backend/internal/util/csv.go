@@ -2,17 +2,124 @@ package util
 
 import (
 	"backend/internal/model"
-	"bytes"
-	"encoding/csv"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
 )
 
-func GenerateCSV(results []model.EmailResult) string {
-	b := &bytes.Buffer{}
-	w := csv.NewWriter(b)
-	w.Write([]string{"Email", "Status", "Reason", "CheckedAt"})
-	for _, r := range results {
-		w.Write([]string{r.Email, r.Status, r.Reason, r.CheckedAt.Format("2006-01-02 15:04:05")})
+// Colonnes du format historique, pour les jobs créés sans en-tête d'origine
+var legacyColumns = []string{"Email", "Status", "Reason", "CheckedAt"}
+
+// Colonnes ajoutées aux colonnes d'origine dans le fichier enrichi
+var verificationColumns = []string{"status", "reason", "score", "flags", "suggestion"}
+
+// ResultLayout décrit les colonnes d'un téléchargement: chaque ligne d'origine
+// (toutes ses colonnes, dans l'ordre du fichier) suivie des colonnes de
+// vérification, ou le format historique si le job n'a pas d'en-tête.
+type ResultLayout struct {
+	headers []string
+}
+
+// NewResultLayout lit l'en-tête JSON stocké sur le job ("" ou "null" sans en-tête)
+func NewResultLayout(headersJSON string) ResultLayout {
+	var headers []string
+	if headersJSON != "" && headersJSON != "null" {
+		json.Unmarshal([]byte(headersJSON), &headers)
+	}
+	return ResultLayout{headers: headers}
+}
+
+func (l ResultLayout) enriched() bool { return len(l.headers) > 0 }
+
+// Columns retourne la ligne d'en-tête
+func (l ResultLayout) Columns() []string {
+	if !l.enriched() {
+		return legacyColumns
+	}
+	return append(append([]string{}, l.headers...), verificationColumns...)
+}
+
+// Row retourne les cellules d'un résultat, alignées sur Columns
+func (l ResultLayout) Row(r model.EmailResult) []string {
+	if !l.enriched() {
+		return []string{r.Email, r.Status, r.Reason, r.CheckedAt.Format("2006-01-02 15:04:05")}
+	}
+	row := l.sourceRow(r)
+	// Ligne sans adresse: recopiée sans colonnes de vérification
+	if r.Email == "" {
+		return append(row, make([]string, len(verificationColumns))...)
+	}
+	return append(row, r.Status, r.Reason, strconv.Itoa(r.Score), strings.Join(ResultFlags(r), ";"), r.Suggestion)
+}
+
+// sourceRow décode les colonnes d'origine, complétées ou tronquées à la largeur
+// de l'en-tête pour aligner les colonnes ajoutées; une ligne illisible est vide
+func (l ResultLayout) sourceRow(r model.EmailResult) []string {
+	var row []string
+	if err := json.Unmarshal([]byte(r.SourceRow), &row); err != nil {
+		row = nil
+	}
+	if len(row) > len(l.headers) {
+		return row[:len(l.headers)]
+	}
+	for len(row) < len(l.headers) {
+		row = append(row, "")
+	}
+	return row
+}
+
+// ResultRecord est un résultat dans les téléchargements JSON et NDJSON
+type ResultRecord struct {
+	RowIndex   int               `json:"rowIndex"`
+	Email      string            `json:"email"`
+	Status     string            `json:"status"`
+	Reason     string            `json:"reason"`
+	Score      int               `json:"score"`
+	Flags      []string          `json:"flags"`
+	Suggestion string            `json:"suggestion,omitempty"`
+	CheckedAt  time.Time         `json:"checkedAt"`
+	Source     map[string]string `json:"source,omitempty"` // colonnes d'origine par nom
+}
+
+// Record retourne un résultat avec ses colonnes d'origine
+func (l ResultLayout) Record(r model.EmailResult) ResultRecord {
+	rec := ResultRecord{
+		RowIndex:   r.RowIndex,
+		Email:      r.Email,
+		Status:     r.Status,
+		Reason:     r.Reason,
+		Score:      r.Score,
+		Flags:      ResultFlags(r),
+		Suggestion: r.Suggestion,
+		CheckedAt:  r.CheckedAt,
+	}
+	if l.enriched() {
+		row := l.sourceRow(r)
+		rec.Source = make(map[string]string, len(l.headers))
+		for i, h := range l.headers {
+			if _, dup := rec.Source[h]; !dup {
+				rec.Source[h] = row[i]
+			}
+		}
+	}
+	return rec
+}
+
+// ResultFlags liste les indicateurs d'un résultat (role_based, disposable, catch_all, free)
+func ResultFlags(r model.EmailResult) []string {
+	flags := []string{}
+	if r.IsRoleBased {
+		flags = append(flags, "role_based")
+	}
+	if r.IsDisposable {
+		flags = append(flags, "disposable")
+	}
+	if r.IsCatchAll {
+		flags = append(flags, "catch_all")
+	}
+	if r.IsFree {
+		flags = append(flags, "free")
 	}
-	w.Flush()
-	return b.String()
+	return flags
 }
@@ -0,0 +1,45 @@
+package util
+
+import (
+	"backend/internal/model"
+	"reflect"
+	"testing"
+)
+
+func TestResultLayoutRowAlignment(t *testing.T) {
+	layout := NewResultLayout(`["email","name"]`)
+	tests := []struct {
+		name string
+		res  model.EmailResult
+		want []string
+	}{
+		{"aligned", model.EmailResult{Email: "a@x.com", Status: "valid", Score: 95, SourceRow: `["a@x.com","Ann"]`},
+			[]string{"a@x.com", "Ann", "valid", "", "95", "", ""}},
+		{"short row padded", model.EmailResult{Email: "a@x.com", Status: "valid", Score: 95, SourceRow: `["a@x.com"]`},
+			[]string{"a@x.com", "", "valid", "", "95", "", ""}},
+		{"long row truncated", model.EmailResult{Email: "a@x.com", Status: "valid", Score: 95, SourceRow: `["a@x.com","Ann","extra","more"]`},
+			[]string{"a@x.com", "Ann", "valid", "", "95", "", ""}},
+		{"malformed source", model.EmailResult{Email: "a@x.com", Status: "invalid", SourceRow: `{not json`},
+			[]string{"", "", "invalid", "", "0", "", ""}},
+		{"blank row passthrough", model.EmailResult{SourceRow: `["","Bob"]`},
+			[]string{"", "Bob", "", "", "", "", ""}},
+	}
+	for _, tt := range tests {
+		got := layout.Row(tt.res)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Row = %q, want %q", tt.name, got, tt.want)
+		}
+		if len(got) != len(layout.Columns()) {
+			t.Errorf("%s: %d cells for %d columns", tt.name, len(got), len(layout.Columns()))
+		}
+	}
+}
+
+func TestResultLayoutRecordSource(t *testing.T) {
+	layout := NewResultLayout(`["email","name"]`)
+	rec := layout.Record(model.EmailResult{Email: "a@x.com", SourceRow: `["a@x.com","Ann","extra"]`})
+	want := map[string]string{"email": "a@x.com", "name": "Ann"}
+	if !reflect.DeepEqual(rec.Source, want) {
+		t.Errorf("Source = %v, want %v", rec.Source, want)
+	}
+}
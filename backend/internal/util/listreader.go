@@ -0,0 +1,459 @@
+package util
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// Formats de liste reconnus
+const (
+	FormatCSV    = "csv"
+	FormatXLSX   = "xlsx"
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+	FormatText   = "txt"
+)
+
+// Taille de l'échantillon lu pour détecter le format, l'encodage et le délimiteur
+const sniffSize = 64 * 1024
+
+// ListInfo décrit ce qui a été détecté à l'ouverture d'un fichier
+type ListInfo struct {
+	Format    string   `json:"format"`
+	Delimiter string   `json:"delimiter,omitempty"`
+	Encoding  string   `json:"encoding,omitempty"`
+	Sheet     string   `json:"sheet,omitempty"`
+	Sheets    []string `json:"sheets,omitempty"`
+}
+
+// ListOptions permet de forcer certains choix de la détection automatique
+type ListOptions struct {
+	// Feuille XLSX à lire (par défaut la première feuille non vide)
+	Sheet string
+}
+
+// ListReader lit une liste (CSV, TSV, XLSX, JSON, NDJSON, texte) ligne par ligne,
+// sous forme de colonnes, quel que soit le format d'origine
+type ListReader interface {
+	// Header retourne les noms de colonnes; ils sont générés pour les formats sans en-tête
+	Header() []string
+	// Read retourne la ligne de données suivante, ou io.EOF
+	Read() ([]string, error)
+	// Info retourne le format, l'encodage, le délimiteur et la feuille détectés
+	Info() ListInfo
+	Close() error
+}
+
+// IsRowError indique une erreur limitée à une ligne (CSV mal formé): la lecture
+// peut continuer. Toute autre erreur interrompt la lecture du fichier.
+func IsRowError(err error) bool {
+	var parseErr *csv.ParseError
+	return errors.As(err, &parseErr)
+}
+
+// OpenListReader ouvre le fichier et détecte son format d'après son contenu,
+// jamais son extension (un .csv qui est en fait un XLSX est lu comme tel, et
+// inversement un CSV renommé en .xlsx est lu comme un CSV).
+func OpenListReader(path string, opts ListOptions) (ListReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	head := make([]byte, 4)
+	n, _ := io.ReadFull(f, head)
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if n == 4 && bytes.Equal(head, []byte("PK\x03\x04")) {
+		f.Close()
+		return openXLSXReader(path, opts)
+	}
+
+	// Encodage puis décodage en UTF-8 à la volée
+	sample := make([]byte, sniffSize)
+	n, _ = io.ReadFull(f, sample)
+	sample = sample[:n]
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+	enc, encName := detectEncoding(sample)
+	decoded := bufio.NewReaderSize(transform.NewReader(f, enc.NewDecoder()), sniffSize)
+	text, _ := decoded.Peek(sniffSize)
+
+	trimmed := bytes.TrimLeft(text, " \t\r\n")
+	var r ListReader
+	switch {
+	case len(trimmed) > 0 && trimmed[0] == '[':
+		r, err = newJSONArrayReader(f, decoded)
+	case len(trimmed) > 0 && trimmed[0] == '{':
+		r, err = newNDJSONReader(f, decoded)
+	default:
+		if delim, ok := sniffDelimiter(text); ok {
+			r, err = newCSVListReader(f, decoded, delim)
+		} else {
+			r, err = newTextReader(f, decoded)
+		}
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	setEncoding(r, encName)
+	return r, nil
+}
+
+// detectEncoding choisit l'encodage à partir du BOM, de la présence d'octets nuls
+// (UTF-16 sans BOM) ou de la validité UTF-8; à défaut Windows-1252 (sur-ensemble de Latin-1)
+func detectEncoding(sample []byte) (encoding.Encoding, string) {
+	switch {
+	case bytes.HasPrefix(sample, []byte{0xEF, 0xBB, 0xBF}):
+		return unicode.UTF8BOM, "utf-8-bom"
+	case bytes.HasPrefix(sample, []byte{0xFF, 0xFE}):
+		return unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM), "utf-16le"
+	case bytes.HasPrefix(sample, []byte{0xFE, 0xFF}):
+		return unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM), "utf-16be"
+	}
+	if len(sample) >= 4 {
+		evenNul, oddNul := 0, 0
+		for i := 0; i+1 < len(sample) && i < 512; i += 2 {
+			if sample[i] == 0 {
+				evenNul++
+			}
+			if sample[i+1] == 0 {
+				oddNul++
+			}
+		}
+		pairs := min(len(sample), 512) / 2
+		if oddNul > pairs/2 && evenNul == 0 {
+			return unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM), "utf-16le"
+		}
+		if evenNul > pairs/2 && oddNul == 0 {
+			return unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM), "utf-16be"
+		}
+	}
+	if validUTF8Prefix(sample) {
+		return encoding.Nop, "utf-8"
+	}
+	return charmap.Windows1252, "windows-1252"
+}
+
+// validUTF8Prefix tolère une séquence UTF-8 coupée en fin d'échantillon
+func validUTF8Prefix(b []byte) bool {
+	for i := 0; i < 3 && len(b) > 0; i++ {
+		if utf8.Valid(b) {
+			return true
+		}
+		if len(b) < sniffSize {
+			return false
+		}
+		b = b[:len(b)-1]
+	}
+	return utf8.Valid(b)
+}
+
+// sniffDelimiter retient, parmi , ; tabulation et |, celui qui apparaît le même
+// nombre de fois (non nul) sur le plus de lignes de l'échantillon
+func sniffDelimiter(text []byte) (rune, bool) {
+	lines := []string{}
+	for _, l := range strings.Split(string(text), "\n") {
+		if l = strings.TrimRight(l, "\r"); strings.TrimSpace(l) != "" {
+			lines = append(lines, l)
+		}
+		if len(lines) == 20 {
+			break
+		}
+	}
+	// La dernière ligne de l'échantillon peut être tronquée
+	if len(lines) > 1 && len(text) >= sniffSize {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return 0, false
+	}
+
+	// Score: nombre de lignes qui s'accordent sur le même nombre de délimiteurs,
+	// puis nombre de colonnes en cas d'égalité
+	best, bestAgree, bestCount := rune(0), 0, 0
+	for _, d := range []rune{',', ';', '\t', '|'} {
+		counts := map[int]int{}
+		for _, l := range lines {
+			if n := countOutsideQuotes(l, d); n > 0 {
+				counts[n]++
+			}
+		}
+		agree, count := 0, 0
+		for n, c := range counts {
+			if c > agree || (c == agree && n > count) {
+				agree, count = c, n
+			}
+		}
+		// Un délimiteur doit être présent sur la majorité des lignes
+		if agree*2 < len(lines) {
+			continue
+		}
+		if agree > bestAgree || (agree == bestAgree && count > bestCount) {
+			best, bestAgree, bestCount = d, agree, count
+		}
+	}
+	return best, best != 0
+}
+
+func countOutsideQuotes(line string, d rune) int {
+	n, quoted := 0, false
+	for _, r := range line {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == d && !quoted:
+			n++
+		}
+	}
+	return n
+}
+
+func delimiterName(d rune) string {
+	if d == '\t' {
+		return "\\t"
+	}
+	return string(d)
+}
+
+// setEncoding complète l'info des lecteurs texte avec l'encodage détecté
+func setEncoding(r ListReader, enc string) {
+	switch lr := r.(type) {
+	case *csvListReader:
+		lr.info.Encoding = enc
+	case *jsonListReader:
+		lr.info.Encoding = enc
+	case *textListReader:
+		lr.info.Encoding = enc
+	}
+}
+
+// ---------- CSV / TSV ----------
+
+type csvListReader struct {
+	f      io.Closer
+	reader *csv.Reader
+	header []string
+	info   ListInfo
+}
+
+func newCSVListReader(f io.Closer, r io.Reader, delim rune) (*csvListReader, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = delim
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read header: %w", err)
+	}
+	return &csvListReader{
+		f:      f,
+		reader: reader,
+		header: header,
+		info:   ListInfo{Format: FormatCSV, Delimiter: delimiterName(delim)},
+	}, nil
+}
+
+func (r *csvListReader) Header() []string        { return r.header }
+func (r *csvListReader) Read() ([]string, error) { return r.reader.Read() }
+func (r *csvListReader) Info() ListInfo          { return r.info }
+func (r *csvListReader) Close() error            { return r.f.Close() }
+
+// ---------- Texte: une valeur par ligne ----------
+
+type textListReader struct {
+	f       io.Closer
+	scanner *bufio.Scanner
+	header  []string
+	pending []string
+	info    ListInfo
+}
+
+// newTextReader lit une valeur par ligne. La première ligne est un en-tête
+// seulement si elle ne ressemble ni à un email ni à un domaine (ex: "email").
+func newTextReader(f io.Closer, r io.Reader) (*textListReader, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	t := &textListReader{f: f, scanner: scanner, header: []string{"value"}, info: ListInfo{Format: FormatText}}
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if !strings.ContainsAny(line, "@.") {
+			t.header = []string{line}
+		} else {
+			t.pending = []string{line}
+		}
+		break
+	}
+	return t, scanner.Err()
+}
+
+func (r *textListReader) Header() []string { return r.header }
+func (r *textListReader) Info() ListInfo   { return r.info }
+func (r *textListReader) Close() error     { return r.f.Close() }
+
+func (r *textListReader) Read() ([]string, error) {
+	if r.pending != nil {
+		row := r.pending
+		r.pending = nil
+		return row, nil
+	}
+	for r.scanner.Scan() {
+		if line := strings.TrimSpace(r.scanner.Text()); line != "" {
+			return []string{line}, nil
+		}
+	}
+	if err := r.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// ---------- JSON (tableau) et NDJSON ----------
+
+type jsonListReader struct {
+	f       io.Closer
+	dec     *json.Decoder
+	header  []string
+	pending []string
+	array   bool
+	info    ListInfo
+}
+
+// newJSONArrayReader lit un tableau d'objets ou de chaînes, élément par élément
+func newJSONArrayReader(f io.Closer, r io.Reader) (*jsonListReader, error) {
+	dec := json.NewDecoder(r)
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+		return nil, errors.New("invalid JSON array")
+	}
+	j := &jsonListReader{f: f, dec: dec, array: true, info: ListInfo{Format: FormatJSON}}
+	return j, j.readFirst()
+}
+
+// newNDJSONReader lit un objet JSON par ligne
+func newNDJSONReader(f io.Closer, r io.Reader) (*jsonListReader, error) {
+	j := &jsonListReader{f: f, dec: json.NewDecoder(r), info: ListInfo{Format: FormatNDJSON}}
+	return j, j.readFirst()
+}
+
+// readFirst lit le premier élément pour fixer l'en-tête (clés du premier objet, dans l'ordre)
+func (r *jsonListReader) readFirst() error {
+	raw, err := r.next()
+	if err == io.EOF {
+		r.header = []string{"value"}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	keys, err := objectKeys(raw)
+	if err != nil {
+		return err
+	}
+	if keys == nil {
+		r.header = []string{"value"}
+	} else {
+		r.header = keys
+	}
+	r.pending, err = r.toRow(raw)
+	return err
+}
+
+func (r *jsonListReader) next() (json.RawMessage, error) {
+	if r.array && !r.dec.More() {
+		return nil, io.EOF
+	}
+	var raw json.RawMessage
+	if err := r.dec.Decode(&raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+func (r *jsonListReader) Header() []string { return r.header }
+func (r *jsonListReader) Info() ListInfo   { return r.info }
+func (r *jsonListReader) Close() error     { return r.f.Close() }
+
+func (r *jsonListReader) Read() ([]string, error) {
+	if r.pending != nil {
+		row := r.pending
+		r.pending = nil
+		return row, nil
+	}
+	raw, err := r.next()
+	if err != nil {
+		return nil, err
+	}
+	return r.toRow(raw)
+}
+
+// toRow aligne un élément JSON sur l'en-tête; les valeurs non textuelles sont réencodées en JSON
+func (r *jsonListReader) toRow(raw json.RawMessage) ([]string, error) {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return []string{jsonScalar(raw)}, nil
+	}
+	row := make([]string, len(r.header))
+	for i, k := range r.header {
+		if v, ok := obj[k]; ok {
+			row[i] = jsonScalar(v)
+		}
+	}
+	return row, nil
+}
+
+// objectKeys retourne les clés d'un objet JSON dans leur ordre d'apparition, nil si ce n'est pas un objet
+func objectKeys(raw json.RawMessage) ([]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if tok != json.Delim('{') {
+		return nil, nil
+	}
+	keys := []string{}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, tok.(string))
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+func jsonScalar(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	if string(raw) == "null" {
+		return ""
+	}
+	return string(raw)
+}
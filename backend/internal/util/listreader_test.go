@@ -0,0 +1,233 @@
+package util
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// readList ouvre le fichier et retourne l'info, l'en-tête et toutes les lignes
+func readList(t *testing.T, path string, opts ListOptions) (ListInfo, []string, [][]string) {
+	t.Helper()
+	r, err := OpenListReader(path, opts)
+	if err != nil {
+		t.Fatalf("OpenListReader(%s): %v", filepath.Base(path), err)
+	}
+	defer r.Close()
+	var rows [][]string
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Read(%s): %v", filepath.Base(path), err)
+		}
+		rows = append(rows, row)
+	}
+	return r.Info(), r.Header(), rows
+}
+
+func writeTestFile(t *testing.T, name string, content []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestOpenListReaderText(t *testing.T) {
+	tests := []struct {
+		name      string
+		file      string
+		content   []byte
+		format    string
+		delimiter string
+		encoding  string
+		header    []string
+		rows      [][]string
+	}{
+		{"comma", "a.csv", []byte("email,name\na@x.com,Ann\nb@x.com,Bob\n"),
+			FormatCSV, ",", "utf-8", []string{"email", "name"}, [][]string{{"a@x.com", "Ann"}, {"b@x.com", "Bob"}}},
+		{"semicolon with commas in quotes", "a.csv", []byte("email;name\na@x.com;\"Doe, Ann\"\nb@x.com;Bob\n"),
+			FormatCSV, ";", "utf-8", []string{"email", "name"}, [][]string{{"a@x.com", "Doe, Ann"}, {"b@x.com", "Bob"}}},
+		{"tab", "a.tsv", []byte("email\tname\na@x.com\tAnn\n"),
+			FormatCSV, "\\t", "utf-8", []string{"email", "name"}, [][]string{{"a@x.com", "Ann"}}},
+		{"pipe", "a.txt", []byte("email|name|city\na@x.com|Ann|Paris\nb@x.com|Bob|Rabat\n"),
+			FormatCSV, "|", "utf-8", []string{"email", "name", "city"}, [][]string{{"a@x.com", "Ann", "Paris"}, {"b@x.com", "Bob", "Rabat"}}},
+		{"quoted newline", "a.csv", []byte("email,note\na@x.com,\"line 1\nline 2\"\nb@x.com,ok\n"),
+			FormatCSV, ",", "utf-8", []string{"email", "note"}, [][]string{{"a@x.com", "line 1\nline 2"}, {"b@x.com", "ok"}}},
+		{"crlf", "a.csv", []byte("email,name\r\na@x.com,Ann\r\n"),
+			FormatCSV, ",", "utf-8", []string{"email", "name"}, [][]string{{"a@x.com", "Ann"}}},
+		{"utf-8 bom", "a.csv", []byte("\xEF\xBB\xBFemail,name\na@x.com,Zoé\n"),
+			FormatCSV, ",", "utf-8-bom", []string{"email", "name"}, [][]string{{"a@x.com", "Zoé"}}},
+		{"utf-16le bom", "a.csv", utf16LE("\uFEFFemail,name\r\na@x.com,Zoé\r\n"),
+			FormatCSV, ",", "utf-16le", []string{"email", "name"}, [][]string{{"a@x.com", "Zoé"}}},
+		{"windows-1252", "a.csv", []byte("email,name\na@x.com,Zo\xe9\n"),
+			FormatCSV, ",", "windows-1252", []string{"email", "name"}, [][]string{{"a@x.com", "Zoé"}}},
+		{"text without header", "a.txt", []byte("a@x.com\n\nb@x.com\n"),
+			FormatText, "", "utf-8", []string{"value"}, [][]string{{"a@x.com"}, {"b@x.com"}}},
+		{"text with header", "a.txt", []byte("Emails\na@x.com\n"),
+			FormatText, "", "utf-8", []string{"Emails"}, [][]string{{"a@x.com"}}},
+		{"json array of objects", "a.json", []byte(`[{"email":"a@x.com","age":31,"vip":true},{"vip":false,"email":"b@x.com","extra":"x"},{"email":null}]`),
+			FormatJSON, "", "utf-8", []string{"email", "age", "vip"}, [][]string{{"a@x.com", "31", "true"}, {"b@x.com", "", "false"}, {"", "", ""}}},
+		{"json array of strings", "a.json", []byte(` ["a@x.com", "b@x.com"]`),
+			FormatJSON, "", "utf-8", []string{"value"}, [][]string{{"a@x.com"}, {"b@x.com"}}},
+		{"ndjson", "a.ndjson", []byte("{\"email\":\"a@x.com\",\"tags\":[\"x\"]}\n{\"email\":\"b@x.com\"}\n"),
+			FormatNDJSON, "", "utf-8", []string{"email", "tags"}, [][]string{{"a@x.com", `["x"]`}, {"b@x.com", ""}}},
+		// Le contenu l'emporte sur l'extension
+		{"json named csv", "a.csv", []byte(`["a@x.com"]`),
+			FormatJSON, "", "utf-8", []string{"value"}, [][]string{{"a@x.com"}}},
+		{"csv named xlsx", "a.xlsx", []byte("email,name\na@x.com,Ann\n"),
+			FormatCSV, ",", "utf-8", []string{"email", "name"}, [][]string{{"a@x.com", "Ann"}}},
+		{"csv named xlsx uppercase", "A.XLSX", []byte("email;name\na@x.com;Ann\n"),
+			FormatCSV, ";", "utf-8", []string{"email", "name"}, [][]string{{"a@x.com", "Ann"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, header, rows := readList(t, writeTestFile(t, tt.file, tt.content), ListOptions{})
+			if info.Format != tt.format || info.Delimiter != tt.delimiter || info.Encoding != tt.encoding {
+				t.Errorf("info = %+v, want format %s, delimiter %q, encoding %s", info, tt.format, tt.delimiter, tt.encoding)
+			}
+			if !reflect.DeepEqual(header, tt.header) {
+				t.Errorf("header = %q, want %q", header, tt.header)
+			}
+			if !reflect.DeepEqual(rows, tt.rows) {
+				t.Errorf("rows = %q, want %q", rows, tt.rows)
+			}
+		})
+	}
+}
+
+func TestSniffDelimiter(t *testing.T) {
+	tests := []struct {
+		text string
+		want rune
+		ok   bool
+	}{
+		{"a,b\nc,d\n", ',', true},
+		{"a;b;c\nd;e;f\n", ';', true},
+		// Virgules dans un champ entre guillemets: le point-virgule est régulier
+		{"name;email\n\"Doe, Ann\";a@x.com\n\"Roe, Bob\";b@x.com\n", ';', true},
+		// Une virgule isolée dans une valeur ne fait pas un délimiteur
+		{"a@x.com\nb@x.com\nDoe, Ann\n", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := sniffDelimiter([]byte(tt.text))
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("sniffDelimiter(%q) = %q, %t; want %q, %t", tt.text, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func utf16LE(s string) []byte {
+	var b []byte
+	for _, r := range s {
+		b = append(b, byte(r), byte(r>>8))
+	}
+	return b
+}
+
+// writeXLSX crée un classeur minimal: les feuilles sont données dans l'ordre du classeur
+func writeXLSX(t *testing.T, shared string, sheets ...[2]string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "list.xlsx")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	add := func(name, content string) {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(w, content)
+	}
+	workbook := `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`
+	rels := `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`
+	for i, s := range sheets {
+		id := string(rune('1' + i))
+		workbook += `<sheet name="` + s[0] + `" sheetId="` + id + `" r:id="rId` + id + `"/>`
+		rels += `<Relationship Id="rId` + id + `" Target="worksheets/sheet` + id + `.xml"/>`
+		add("xl/worksheets/sheet"+id+".xml", `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`+s[1]+`</sheetData></worksheet>`)
+	}
+	add("xl/workbook.xml", workbook+`</sheets></workbook>`)
+	add("xl/_rels/workbook.xml.rels", rels+`</Relationships>`)
+	if shared != "" {
+		add("xl/sharedStrings.xml", `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`+shared+`</sst>`)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	return path
+}
+
+func TestOpenListReaderXLSX(t *testing.T) {
+	shared := `<si><t>email</t></si><si><t>name</t></si><si><t>a@x.com</t></si>` +
+		// Texte riche en plusieurs runs, annotation phonétique ignorée
+		`<si><r><t>Ann </t></r><r><t>Doe</t></r><rPh><t>ignored</t></rPh></si>`
+	data := `<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="inlineStr"><is><t>active</t></is></c></row>` +
+		`<row r="2"><c r="A2" t="s"><v>2</v></c><c r="B2" t="s"><v>3</v></c><c r="C2" t="b"><v>1</v></c></row>` +
+		// Ligne vide ignorée, cellules éparses (B absente) et chaîne en ligne
+		`<row r="3"></row>` +
+		`<row r="4"><c r="A4" t="inlineStr"><is><t>b@x.com</t></is></c><c r="C4" t="b"><v>0</v></c></row>` +
+		// Cellule lointaine: la ligne est étendue jusqu'à la colonne
+		`<row r="5"><c r="A5" t="str"><v>c@x.com</v></c><c r="E5"><v>42</v></c></row>`
+	path := writeXLSX(t, shared, [2]string{"Empty", ""}, [2]string{"Contacts", data}, [2]string{"Other", `<row r="1"><c r="A1" t="inlineStr"><is><t>domain</t></is></c></row><row r="2"><c r="A2" t="inlineStr"><is><t>x.com</t></is></c></row>`})
+
+	// Par défaut, la première feuille non vide
+	info, header, rows := readList(t, path, ListOptions{})
+	if info.Format != FormatXLSX || info.Sheet != "Contacts" || !reflect.DeepEqual(info.Sheets, []string{"Empty", "Contacts", "Other"}) {
+		t.Errorf("info = %+v", info)
+	}
+	if want := []string{"email", "name", "active"}; !reflect.DeepEqual(header, want) {
+		t.Errorf("header = %q, want %q", header, want)
+	}
+	wantRows := [][]string{
+		{"a@x.com", "Ann Doe", "TRUE"},
+		{"b@x.com", "", "FALSE"},
+		{"c@x.com", "", "", "", "42"},
+	}
+	if !reflect.DeepEqual(rows, wantRows) {
+		t.Errorf("rows = %q, want %q", rows, wantRows)
+	}
+
+	// Feuille choisie, nom insensible à la casse
+	info, header, rows = readList(t, path, ListOptions{Sheet: "other"})
+	if info.Sheet != "Other" || !reflect.DeepEqual(header, []string{"domain"}) || !reflect.DeepEqual(rows, [][]string{{"x.com"}}) {
+		t.Errorf("sheet Other: info = %+v, header = %q, rows = %q", info, header, rows)
+	}
+	if _, err := OpenListReader(path, ListOptions{Sheet: "Missing"}); err == nil {
+		t.Error("missing sheet: expected an error")
+	}
+}
+
+func TestOpenListReaderXLSXNoSharedStrings(t *testing.T) {
+	path := writeXLSX(t, "", [2]string{"Sheet1", `<row><c t="inlineStr"><is><t>email</t></is></c></row><row><c t="inlineStr"><is><t>a@x.com</t></is></c></row>`})
+	_, header, rows := readList(t, path, ListOptions{})
+	if !reflect.DeepEqual(header, []string{"email"}) || !reflect.DeepEqual(rows, [][]string{{"a@x.com"}}) {
+		t.Errorf("header = %q, rows = %q", header, rows)
+	}
+}
+
+func TestOpenListReaderXLSXAllEmpty(t *testing.T) {
+	path := writeXLSX(t, "", [2]string{"Sheet1", ""}, [2]string{"Sheet2", `<row r="1"></row>`})
+	if _, err := OpenListReader(path, ListOptions{}); err == nil {
+		t.Error("expected an error for a workbook without data")
+	}
+}
+
+func TestXLSXColumnIndex(t *testing.T) {
+	for ref, want := range map[string]int{"A1": 0, "B7": 1, "Z3": 25, "AA10": 26, "AB12": 27, "ba2": 52, "": 0} {
+		if got := xlsxColumnIndex(ref); got != want {
+			t.Errorf("xlsxColumnIndex(%q) = %d, want %d", ref, got, want)
+		}
+	}
+}
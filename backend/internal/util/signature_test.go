@@ -0,0 +1,51 @@
+package util
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestVerifySignature(t *testing.T) {
+	secret := "whsec_test"
+	body := []byte(`{"event":"job.finished"}`)
+	now := time.Unix(1700000000, 0)
+	valid := SignPayload(secret, body, now)
+	tests := []struct {
+		name   string
+		secret string
+		header string
+		body   []byte
+		now    time.Time
+		ok     bool
+	}{
+		{"valid", secret, valid, body, now, true},
+		{"valid within tolerance", secret, valid, body, now.Add(SignatureTolerance), true},
+		{"valid with spaces", secret, "t=1700000000, v1=" + computeSignature(secret, "1700000000", body), body, now, true},
+		{"tampered body", secret, valid, []byte(`{"event":"job.failed"}`), now, false},
+		{"wrong secret", "whsec_other", valid, body, now, false},
+		{"stale timestamp", secret, valid, body, now.Add(SignatureTolerance + time.Second), false},
+		{"future timestamp", secret, valid, body, now.Add(-SignatureTolerance - time.Second), false},
+		{"empty header", secret, "", body, now, false},
+		{"missing signature", secret, "t=1700000000", body, now, false},
+		{"missing timestamp", secret, "v1=" + computeSignature(secret, "1700000000", body), body, now, false},
+		{"non numeric timestamp", secret, "t=abc,v1=" + computeSignature(secret, "abc", body), body, now, false},
+		{"garbage", secret, "not a signature", body, now, false},
+	}
+	for _, tt := range tests {
+		err := VerifySignature(tt.secret, tt.header, tt.body, tt.now)
+		if tt.ok && err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		}
+		if !tt.ok && !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("%s: err = %v, want ErrInvalidSignature", tt.name, err)
+		}
+	}
+}
+
+func TestSignPayloadFormat(t *testing.T) {
+	got := SignPayload("s", []byte("body"), time.Unix(42, 0))
+	if want := "t=42,v1=" + computeSignature("s", "42", []byte("body")); got != want {
+		t.Errorf("SignPayload = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,314 @@
+package util
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Lecture XLSX minimale (stdlib): chaînes partagées en mémoire, feuille lue en flux
+
+type xlsxListReader struct {
+	zr     *zip.ReadCloser
+	sheet  io.ReadCloser
+	dec    *xml.Decoder
+	shared []string
+	header []string
+	info   ListInfo
+}
+
+type xlsxSheet struct {
+	Name string
+	Path string
+}
+
+func openXLSXReader(filePath string, opts ListOptions) (ListReader, error) {
+	zr, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid xlsx file: %w", err)
+	}
+	sheets, err := xlsxSheets(zr)
+	if err != nil {
+		zr.Close()
+		return nil, err
+	}
+	shared, err := xlsxSharedStrings(zr)
+	if err != nil {
+		zr.Close()
+		return nil, err
+	}
+	names := make([]string, len(sheets))
+	for i, s := range sheets {
+		names[i] = s.Name
+	}
+
+	// Feuille demandée, sinon la première qui contient un en-tête
+	candidates := sheets
+	if opts.Sheet != "" {
+		candidates = nil
+		for _, s := range sheets {
+			if strings.EqualFold(s.Name, strings.TrimSpace(opts.Sheet)) {
+				candidates = []xlsxSheet{s}
+			}
+		}
+		if candidates == nil {
+			zr.Close()
+			return nil, fmt.Errorf("sheet %q not found (sheets: %s)", opts.Sheet, strings.Join(names, ", "))
+		}
+	}
+	for _, s := range candidates {
+		r := &xlsxListReader{zr: zr, shared: shared, info: ListInfo{Format: FormatXLSX, Sheet: s.Name, Sheets: names}}
+		if err := r.openSheet(s.Path); err != nil {
+			zr.Close()
+			return nil, err
+		}
+		header, err := r.Read()
+		if err == nil && len(header) > 0 {
+			r.header = header
+			return r, nil
+		}
+		r.sheet.Close()
+		if err != nil && err != io.EOF {
+			zr.Close()
+			return nil, err
+		}
+	}
+	zr.Close()
+	return nil, errors.New("xlsx file has no non-empty sheet")
+}
+
+func (r *xlsxListReader) openSheet(name string) error {
+	f, err := zipOpen(r.zr, name)
+	if err != nil {
+		return err
+	}
+	r.sheet = f
+	r.dec = xml.NewDecoder(f)
+	return nil
+}
+
+func (r *xlsxListReader) Header() []string { return r.header }
+func (r *xlsxListReader) Info() ListInfo   { return r.info }
+
+func (r *xlsxListReader) Close() error {
+	r.sheet.Close()
+	return r.zr.Close()
+}
+
+// Read retourne la ligne suivante; les lignes entièrement vides sont ignorées
+func (r *xlsxListReader) Read() ([]string, error) {
+	for {
+		row, err := r.readRow()
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range row {
+			if strings.TrimSpace(v) != "" {
+				return row, nil
+			}
+		}
+	}
+}
+
+func (r *xlsxListReader) readRow() ([]string, error) {
+	var row []string
+	inRow := false
+	var cellType, cellRef string
+	var value strings.Builder
+	inValue := false
+	for {
+		tok, err := r.dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "row":
+				inRow = true
+				row = row[:0]
+			case "c":
+				cellType, cellRef = "", ""
+				for _, a := range t.Attr {
+					switch a.Name.Local {
+					case "t":
+						cellType = a.Value
+					case "r":
+						cellRef = a.Value
+					}
+				}
+				value.Reset()
+			case "v", "t":
+				inValue = inRow
+			}
+		case xml.CharData:
+			if inValue {
+				value.Write(t)
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "v", "t":
+				inValue = false
+			case "c":
+				col := len(row)
+				if cellRef != "" {
+					col = xlsxColumnIndex(cellRef)
+				}
+				for len(row) <= col {
+					row = append(row, "")
+				}
+				row[col] = r.cellValue(cellType, value.String())
+			case "row":
+				return append([]string(nil), row...), nil
+			case "sheetData":
+				return nil, io.EOF
+			}
+		}
+	}
+}
+
+func (r *xlsxListReader) cellValue(cellType, raw string) string {
+	switch cellType {
+	case "s":
+		i, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err == nil && i >= 0 && i < len(r.shared) {
+			return r.shared[i]
+		}
+		return ""
+	case "b":
+		if raw == "1" {
+			return "TRUE"
+		}
+		return "FALSE"
+	}
+	return raw
+}
+
+// xlsxColumnIndex convertit la référence de cellule ("AB12") en index de colonne (27)
+func xlsxColumnIndex(ref string) int {
+	col := 0
+	for _, ch := range ref {
+		if ch >= 'a' && ch <= 'z' {
+			ch -= 'a' - 'A'
+		}
+		if ch < 'A' || ch > 'Z' {
+			break
+		}
+		col = col*26 + int(ch-'A'+1)
+	}
+	return max(col-1, 0)
+}
+
+// xlsxSheets retourne les feuilles dans l'ordre du classeur avec le chemin de leur XML
+func xlsxSheets(zr *zip.ReadCloser) ([]xlsxSheet, error) {
+	var workbook struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+			RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := zipDecodeXML(zr, "xl/workbook.xml", &workbook); err != nil {
+		return nil, fmt.Errorf("invalid xlsx workbook: %w", err)
+	}
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := zipDecodeXML(zr, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return nil, fmt.Errorf("invalid xlsx workbook: %w", err)
+	}
+	targets := map[string]string{}
+	for _, rel := range rels.Relationships {
+		target := rel.Target
+		if strings.HasPrefix(target, "/") {
+			target = strings.TrimPrefix(target, "/")
+		} else {
+			target = path.Join("xl", target)
+		}
+		targets[rel.ID] = target
+	}
+	sheets := make([]xlsxSheet, 0, len(workbook.Sheets))
+	for _, s := range workbook.Sheets {
+		if target, ok := targets[s.RID]; ok {
+			sheets = append(sheets, xlsxSheet{Name: s.Name, Path: target})
+		}
+	}
+	if len(sheets) == 0 {
+		return nil, errors.New("xlsx file has no sheet")
+	}
+	return sheets, nil
+}
+
+// xlsxSharedStrings charge la table des chaînes partagées (absente si aucune chaîne)
+func xlsxSharedStrings(zr *zip.ReadCloser) ([]string, error) {
+	f, err := zipOpen(zr, "xl/sharedStrings.xml")
+	if err != nil {
+		return nil, nil
+	}
+	defer f.Close()
+	dec := xml.NewDecoder(f)
+	var shared []string
+	var current strings.Builder
+	inSI, inT, inRPh := false, false, false
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return shared, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid xlsx shared strings: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "si":
+				inSI = true
+				current.Reset()
+			case "t":
+				inT = inSI && !inRPh
+			case "rPh":
+				// Annotations phonétiques: ne font pas partie du texte
+				inRPh = true
+			}
+		case xml.CharData:
+			if inT {
+				current.Write(t)
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inT = false
+			case "rPh":
+				inRPh = false
+			case "si":
+				inSI = false
+				shared = append(shared, current.String())
+			}
+		}
+	}
+}
+
+func zipOpen(zr *zip.ReadCloser, name string) (io.ReadCloser, error) {
+	for _, f := range zr.File {
+		if strings.EqualFold(f.Name, name) {
+			return f.Open()
+		}
+	}
+	return nil, fmt.Errorf("%s not found in archive", name)
+}
+
+func zipDecodeXML(zr *zip.ReadCloser, name string, v any) error {
+	f, err := zipOpen(zr, name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return xml.NewDecoder(f).Decode(v)
+}
@@ -0,0 +1,178 @@
+package util
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Écriture XLSX minimale (stdlib): chaque feuille est écrite en flux, ligne par
+// ligne, avec des chaînes en ligne (pas de table de chaînes partagées à garder en mémoire)
+
+// Limites du format: 31 caractères par nom de feuille, 32767 par cellule, 1048576 lignes
+const (
+	xlsxMaxSheetName = 31
+	xlsxMaxCell      = 32767
+	xlsxMaxRows      = 1 << 20
+)
+
+var ErrXLSXTooManyRows = errors.New("xlsx sheet row limit reached")
+
+// XLSXWriter écrit un classeur dans w; les feuilles sont écrites l'une après l'autre
+type XLSXWriter struct {
+	zw     *zip.Writer
+	buf    *bufio.Writer
+	sheets []string
+	rows   int
+	open   bool
+}
+
+func NewXLSXWriter(w io.Writer) *XLSXWriter {
+	return &XLSXWriter{zw: zip.NewWriter(w)}
+}
+
+// AddSheet termine la feuille en cours et commence la suivante
+func (x *XLSXWriter) AddSheet(name string) error {
+	if err := x.closeSheet(); err != nil {
+		return err
+	}
+	f, err := x.zw.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", len(x.sheets)+1))
+	if err != nil {
+		return err
+	}
+	x.sheets = append(x.sheets, xlsxSheetName(name, len(x.sheets)+1))
+	x.buf = bufio.NewWriter(f)
+	x.rows = 0
+	x.open = true
+	_, err = x.buf.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	return err
+}
+
+// WriteRow ajoute une ligne de cellules texte à la feuille en cours
+func (x *XLSXWriter) WriteRow(cells []string) error {
+	if !x.open {
+		return errors.New("xlsx: no sheet")
+	}
+	if x.rows >= xlsxMaxRows {
+		return ErrXLSXTooManyRows
+	}
+	x.rows++
+	fmt.Fprintf(x.buf, `<row r="%d">`, x.rows)
+	for i, v := range cells {
+		if v == "" {
+			continue
+		}
+		if len(v) > xlsxMaxCell {
+			v = truncateUTF8(v, xlsxMaxCell)
+		}
+		fmt.Fprintf(x.buf, `<c r="%s%d" t="inlineStr"><is><t xml:space="preserve">`, xlsxColumnName(i), x.rows)
+		xml.EscapeText(x.buf, []byte(v))
+		x.buf.WriteString(`</t></is></c>`)
+	}
+	_, err := x.buf.WriteString(`</row>`)
+	return err
+}
+
+func (x *XLSXWriter) closeSheet() error {
+	if !x.open {
+		return nil
+	}
+	x.open = false
+	if _, err := x.buf.WriteString(`</sheetData></worksheet>`); err != nil {
+		return err
+	}
+	return x.buf.Flush()
+}
+
+// Close termine la dernière feuille puis écrit le classeur et ses relations
+func (x *XLSXWriter) Close() error {
+	if len(x.sheets) == 0 {
+		if err := x.AddSheet("Sheet1"); err != nil {
+			return err
+		}
+	}
+	if err := x.closeSheet(); err != nil {
+		return err
+	}
+
+	var types, workbook, rels strings.Builder
+	types.WriteString(xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	workbook.WriteString(xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	rels.WriteString(xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i, name := range x.sheets {
+		n := i + 1
+		fmt.Fprintf(&types, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, n)
+		fmt.Fprintf(&workbook, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xmlAttr(name), n, n)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, n, n)
+	}
+	types.WriteString(`</Types>`)
+	workbook.WriteString(`</sheets></workbook>`)
+	rels.WriteString(`</Relationships>`)
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", types.String()},
+		{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+		{"xl/workbook.xml", workbook.String()},
+		{"xl/_rels/workbook.xml.rels", rels.String()},
+	}
+	for _, p := range parts {
+		f, err := x.zw.Create(p.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, p.content); err != nil {
+			return err
+		}
+	}
+	return x.zw.Close()
+}
+
+// xlsxColumnName convertit un index de colonne (27) en lettres ("AB")
+func xlsxColumnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+// xlsxSheetName retire les caractères interdits dans un nom de feuille et le tronque
+func xlsxSheetName(name string, n int) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if name == "" {
+		return fmt.Sprintf("Sheet%d", n)
+	}
+	return truncateUTF8(name, xlsxMaxSheetName)
+}
+
+func xmlAttr(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s)) // échappe aussi les guillemets
+	return b.String()
+}
+
+// truncateUTF8 coupe s à n octets au plus sans couper un caractère
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
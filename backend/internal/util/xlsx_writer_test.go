@@ -0,0 +1,144 @@
+package util
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// writeTestWorkbook écrit les feuilles avec XLSXWriter et retourne le chemin du fichier
+func writeTestWorkbook(t *testing.T, sheets map[string][][]string, order ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "export.xlsx")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	w := NewXLSXWriter(f)
+	for _, name := range order {
+		if err := w.AddSheet(name); err != nil {
+			t.Fatal(err)
+		}
+		for _, row := range sheets[name] {
+			if err := w.WriteRow(row); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// Un fichier écrit par XLSXWriter se relit à l'identique avec OpenListReader
+func TestXLSXWriterRoundTrip(t *testing.T) {
+	long := strings.Repeat("é", xlsxMaxCell) // 2 octets par caractère: tronqué
+	results := [][]string{
+		{"email", "name", "note"},
+		{"a@x.com", `Ann & "Bob" <ceo>`, "  spaced  "},
+		// Ligne courte, puis ligne plus longue que l'en-tête avec une cellule vide au milieu
+		{"b@x.com"},
+		{"c@x.com", "", "", "extra"},
+		{"d@x.com", "Zoë 日本", "line1\nline2\ttab"},
+		// Les cellules vides en fin de ligne ne sont pas écrites
+		{"e@x.com", "Eve", ""},
+		// Caractère interdit en XML remplacé plutôt que de corrompre le fichier
+		{"f@x.com", "bell\x07"},
+		{"g@x.com", long},
+	}
+	path := writeTestWorkbook(t, map[string][][]string{
+		"Résultats: 2026/10": results,
+		"Summary":            {{"status", "count"}, {"valid", "5"}},
+	}, "Résultats: 2026/10", "Summary")
+
+	info, header, rows := readList(t, path, ListOptions{})
+	if info.Format != FormatXLSX || info.Sheet != "Résultats_ 2026_10" || !reflect.DeepEqual(info.Sheets, []string{"Résultats_ 2026_10", "Summary"}) {
+		t.Errorf("info = %+v", info)
+	}
+	if !reflect.DeepEqual(header, results[0]) {
+		t.Errorf("header = %q, want %q", header, results[0])
+	}
+	want := [][]string{
+		{"a@x.com", `Ann & "Bob" <ceo>`, "  spaced  "},
+		{"b@x.com"},
+		{"c@x.com", "", "", "extra"},
+		{"d@x.com", "Zoë 日本", "line1\nline2\ttab"},
+		{"e@x.com", "Eve"},
+		{"f@x.com", "bell\uFFFD"},
+		{"g@x.com", truncateUTF8(long, xlsxMaxCell)},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("%d rows, want %d", len(rows), len(want))
+	}
+	for i := range want {
+		if !reflect.DeepEqual(rows[i], want[i]) {
+			t.Errorf("row %d = %.80q, want %.80q", i+1, rows[i], want[i])
+		}
+	}
+	if got := rows[6][1]; len(got) > xlsxMaxCell || len(got) < xlsxMaxCell-1 {
+		t.Errorf("long cell: %d bytes, want ~%d", len(got), xlsxMaxCell)
+	}
+
+	_, header, rows = readList(t, path, ListOptions{Sheet: "summary"})
+	if !reflect.DeepEqual(header, []string{"status", "count"}) || !reflect.DeepEqual(rows, [][]string{{"valid", "5"}}) {
+		t.Errorf("sheet Summary: header = %q, rows = %q", header, rows)
+	}
+}
+
+// Les chaînes en ligne de XLSXWriter se lisent comme les mêmes chaînes stockées
+// dans la table partagée (forme écrite par Excel)
+func TestXLSXWriterMatchesSharedStrings(t *testing.T) {
+	data := [][]string{
+		{"email", "name"},
+		{"a@x.com", "Ann & <Co>"},
+		{"b@x.com"},
+		{"c@x.com", "Ann & <Co>"},
+	}
+	written := writeTestWorkbook(t, map[string][][]string{"Sheet1": data}, "Sheet1")
+
+	// Même contenu avec une table de chaînes partagées, valeurs répétées dédoublonnées
+	var shared, sheet strings.Builder
+	index := map[string]int{}
+	for r, row := range data {
+		sheet.WriteString(`<row r="` + strconv.Itoa(r+1) + `">`)
+		for c, v := range row {
+			i, ok := index[v]
+			if !ok {
+				i = len(index)
+				index[v] = i
+				shared.WriteString(`<si><t>`)
+				xml.EscapeText(&shared, []byte(v))
+				shared.WriteString(`</t></si>`)
+			}
+			sheet.WriteString(`<c r="` + xlsxColumnName(c) + strconv.Itoa(r+1) + `" t="s"><v>` + strconv.Itoa(i) + `</v></c>`)
+		}
+		sheet.WriteString(`</row>`)
+	}
+	excel := writeXLSX(t, shared.String(), [2]string{"Sheet1", sheet.String()})
+
+	_, h1, r1 := readList(t, written, ListOptions{})
+	_, h2, r2 := readList(t, excel, ListOptions{})
+	if !reflect.DeepEqual(h1, h2) || !reflect.DeepEqual(r1, r2) {
+		t.Errorf("inline strings: %q %q\nshared strings: %q %q", h1, r1, h2, r2)
+	}
+	if !reflect.DeepEqual(r1, data[1:]) {
+		t.Errorf("rows = %q, want %q", r1, data[1:])
+	}
+}
+
+func TestXLSXColumnName(t *testing.T) {
+	for i, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"} {
+		if got := xlsxColumnName(i); got != want {
+			t.Errorf("xlsxColumnName(%d) = %q, want %q", i, got, want)
+		}
+		if back := xlsxColumnIndex(want + "1"); back != i {
+			t.Errorf("xlsxColumnIndex(%q) = %d, want %d", want+"1", back, i)
+		}
+	}
+}
@@ -1,15 +1,56 @@
-import { useState } from 'react';
-import { Download, Lock, Search, Clock } from 'lucide-react';
+import { useEffect, useState } from 'react';
+import Link from 'next/link';
+import { Download, Lock, Search, Clock, Trash2 } from 'lucide-react';
 
-const lists = [
-  { name: 'ecp_export_20250324.csv', date: 'Mar 24, 2025, 05:44 AM', progress: 42, emails: 753 },
-  { name: 'leads-import.csv', date: 'Mar 23, 2025, 02:11 PM', progress: 17, emails: 998 },
-  { name: 'chicago.xlsx', date: 'Feb 17, 2025, 06:49 PM', progress: 38, emails: 295 },
-  { name: 'oklahomaaaaaaa.xlsx', date: 'Jan 13, 2025, 04:09 PM', progress: 27, emails: 241 },
-];
+interface JobSummary {
+  id: string;
+  kind: string;
+  fileName: string;
+  displayName?: string;
+  status: string;
+  uploadedAt: string;
+  totalEmails: number;
+  progress: number;
+  tags: string[];
+  counts: Record<string, number>;
+}
+
+interface JobsResponse {
+  jobs: JobSummary[];
+  total: number;
+  page: number;
+  pageSize: number;
+}
+
+const apiUrl = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:3009/api';
+const pageSize = 8;
 
 export default function RecentListsPage() {
   const [search, setSearch] = useState('');
+  const [page, setPage] = useState(1);
+  const [data, setData] = useState<JobsResponse>({ jobs: [], total: 0, page: 1, pageSize });
+  const [refresh, setRefresh] = useState(0);
+
+  useEffect(() => {
+    // Petit délai pour ne pas interroger l'API à chaque frappe
+    const timer = setTimeout(async () => {
+      const params = new URLSearchParams({ page: String(page), pageSize: String(pageSize), q: search });
+      const res = await fetch(`${apiUrl}/jobs?${params}`);
+      if (res.ok) setData(await res.json());
+    }, 250);
+    return () => clearTimeout(timer);
+  }, [search, page, refresh]);
+
+  const deleteJob = async (id: string) => {
+    if (!confirm('Delete this list and its results?')) return;
+    const res = await fetch(`${apiUrl}/jobs/${id}`, { method: 'DELETE' });
+    if (res.ok) setRefresh(r => r + 1);
+  };
+
+  const totalPages = Math.max(1, Math.ceil(data.total / pageSize));
+  const first = data.total === 0 ? 0 : (page - 1) * pageSize + 1;
+  const last = Math.min(page * pageSize, data.total);
+
   return (
     <div>
       <h1 className="text-4xl font-bold mb-8">Recent Lists</h1>
@@ -19,29 +60,35 @@ export default function RecentListsPage() {
             type="text"
             placeholder="Search lists"
             value={search}
-            onChange={e => setSearch(e.target.value)}
+            onChange={e => { setSearch(e.target.value); setPage(1); }}
             className="input-field pl-10"
           />
           <Search className="absolute left-3 top-1/2 -translate-y-1/2 w-5 h-5 text-gray-400" />
         </div>
       </div>
       <div className="grid grid-cols-1 md:grid-cols-2 lg:grid-cols-4 gap-6 mb-8">
-        {lists.filter(l => l.name.toLowerCase().includes(search.toLowerCase())).map(list => (
-          <div key={list.name} className="bg-white rounded-xl border border-border shadow p-6 flex flex-col justify-between min-h-[160px]">
+        {data.jobs.map(list => (
+          <div key={list.id} className="bg-white rounded-xl border border-border shadow p-6 flex flex-col justify-between min-h-[160px]">
             <div className="flex items-center mb-2">
               <Clock className="w-4 h-4 text-gray-400 mr-2" />
-              <span className="text-xs text-gray-500">{list.date}</span>
+              <span className="text-xs text-gray-500">{new Date(list.uploadedAt).toLocaleString()}</span>
+              <span className="text-xs text-gray-500 ml-auto">{list.status}</span>
             </div>
-            <div className="font-semibold text-gray-900 truncate mb-2">{list.name}</div>
+            <Link href={`/results/${list.id}`} className="font-semibold text-gray-900 truncate mb-2">{list.displayName || list.fileName}</Link>
             <div className="flex items-center mb-2">
               <div className="w-full h-2 bg-muted rounded-full mr-2">
-                <div className="h-2 rounded-full bg-primary" style={{ width: `${list.progress}%` }}></div>
+                <div className="h-2 rounded-full bg-primary" style={{ width: `${Math.round(list.progress)}%` }}></div>
               </div>
-              <span className="text-xs text-gray-500 font-semibold">{list.progress}%</span>
+              <span className="text-xs text-gray-500 font-semibold">{Math.round(list.progress)}%</span>
             </div>
             <div className="flex items-center justify-between mt-2">
-              <span className="text-xs text-gray-500">{list.emails} Emails</span>
-              <button className="btn-secondary flex items-center text-sm"><Download className="w-4 h-4 mr-1" /> Download</button>
+              <span className="text-xs text-gray-500">{list.totalEmails} {list.kind === 'extract' ? 'Sites' : 'Emails'}</span>
+              <div className="flex items-center space-x-2">
+                {list.kind !== 'extract' && (
+                  <a href={`${apiUrl}/upload/job/${list.id}/results/download?type=all`} className="btn-secondary flex items-center text-sm"><Download className="w-4 h-4 mr-1" /> Download</a>
+                )}
+                <button onClick={() => deleteJob(list.id)} className="btn-secondary px-2 py-1" title="Delete"><Trash2 className="w-4 h-4" /></button>
+              </div>
             </div>
           </div>
         ))}
@@ -55,13 +102,13 @@ export default function RecentListsPage() {
         ))}
       </div>
       <div className="flex items-center justify-between mt-8">
-        <span className="text-sm text-gray-500">Showing 1 to 4 of 4 results</span>
+        <span className="text-sm text-gray-500">Showing {first} to {last} of {data.total} results</span>
         <div className="flex items-center space-x-2">
-          <button className="btn-secondary px-2 py-1" disabled>{'<'}</button>
-          <span className="text-sm text-gray-700">Page 1 of 1</span>
-          <button className="btn-secondary px-2 py-1" disabled>{'>'}</button>
+          <button className="btn-secondary px-2 py-1" disabled={page <= 1} onClick={() => setPage(p => p - 1)}>{'<'}</button>
+          <span className="text-sm text-gray-700">Page {page} of {totalPages}</span>
+          <button className="btn-secondary px-2 py-1" disabled={page >= totalPages} onClick={() => setPage(p => p + 1)}>{'>'}</button>
         </div>
       </div>
     </div>
   );
-} 
\ No newline at end of file
+}
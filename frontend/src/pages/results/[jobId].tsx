@@ -34,6 +34,7 @@ interface EmailResult {
 interface ResultsResponse {
   jobId: string;
   fileName: string;
+  displayName?: string;
   page: number;
   pageSize: number;
   total: number;
@@ -80,7 +81,7 @@ export default function ResultsPage() {
       }
 
       const data: ResultsResponse = await response.json();
-      setFileName(data.fileName);
+      setFileName(data.displayName || data.fileName);
       setResults(data.results);
       setTotal(data.total);
       setTotalValid(data.valid);